@@ -134,3 +134,35 @@ func SignTransactionWithBuilder(holder core.CryptoComponentsHolder, tx *transact
 	}
 	return builder.ApplyUserSignature(holder, tx)
 }
+
+// SignPayload signs the SigningBytes of the payload as its sender and sets the hex signature
+func SignPayload(holder core.CryptoComponentsHolder, payload *ExactRelayedPayload) error {
+	msgBytes, err := SigningBytes(*payload)
+	if err != nil {
+		return fmt.Errorf("failed to serialize payload: %w", err)
+	}
+	sig, err := (&SimpleSigner{}).SignByteSlice(msgBytes, holder.GetPrivateKey())
+	if err != nil {
+		return err
+	}
+	payload.Signature = hex.EncodeToString(sig)
+	return nil
+}
+
+// SignTransactionAsGuardian applies the guardian co-signature to a guarded transaction
+// The transaction must carry the guardian address and have the guarded options bit set.
+func SignTransactionAsGuardian(holder core.CryptoComponentsHolder, tx *transaction.FrontendTransaction) error {
+	if tx.Options&TxOptionGuarded == 0 {
+		return fmt.Errorf("transaction is not guarded (options: %d)", tx.Options)
+	}
+	if tx.Version < TxVersionGuarded {
+		return fmt.Errorf("guarded transactions require version %d or higher, got %d", TxVersionGuarded, tx.Version)
+	}
+
+	builder, err := builders.NewTxBuilder(&SimpleSigner{})
+	if err != nil {
+		return fmt.Errorf("failed to create tx builder: %w", err)
+	}
+
+	return builder.ApplyGuardianSignature(holder, tx)
+}
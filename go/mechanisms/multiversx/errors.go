@@ -0,0 +1,112 @@
+package multiversx
+
+import (
+	"strings"
+
+	x402 "github.com/coinbase/x402/go"
+)
+
+// Error reasons reported for MultiversX node rejections
+const (
+	// ErrCodeInvalidNonce indicates the transaction nonce does not match the sender account
+	ErrCodeInvalidNonce = "invalid_nonce"
+	// ErrCodeGasTooLow indicates the gas limit or gas price is below what the node requires
+	ErrCodeGasTooLow = "gas_too_low"
+	// ErrCodeInvalidAddress indicates a malformed receiver bech32 address
+	ErrCodeInvalidAddress = "invalid_address"
+	// ErrCodeInvalidSender indicates the payload sender is not a valid erd1 bech32 address
+	ErrCodeInvalidSender = "invalid_sender"
+	// ErrCodeContractRejected indicates the smart contract rejected the call during simulation
+	ErrCodeContractRejected = "contract_rejected"
+	// ErrCodeResultUnknown indicates the transaction executed but its smart contract results could not be read
+	ErrCodeResultUnknown = "result_unknown"
+	// ErrCodeNoMatchingRequirement indicates the payload satisfies none of the offered requirements
+	ErrCodeNoMatchingRequirement = "no_matching_requirement"
+	// ErrCodeUnsupportedVersion indicates the payload declares an x402 version the facilitator does not accept
+	ErrCodeUnsupportedVersion = "unsupported_x402_version"
+	// ErrCodeGasPriceTooHigh indicates a gas price above the accepted multiple of the network minimum
+	ErrCodeGasPriceTooHigh = "gas_price_too_high"
+	// ErrCodePaymentNotYetValid indicates the payload validAfter is still in the future
+	ErrCodePaymentNotYetValid = "payment_not_yet_valid"
+	// ErrCodeReceiverMismatch indicates the payment does not go to the requirement PayTo
+	ErrCodeReceiverMismatch = "receiver_mismatch"
+	// ErrCodeAmountMismatch indicates the payment amount is below the required amount
+	ErrCodeAmountMismatch = "amount_mismatch"
+	// ErrCodeAssetMismatch indicates the payment does not transfer the required asset
+	ErrCodeAssetMismatch = "asset_mismatch"
+	// ErrCodeTokenNonceMismatch indicates the transferred SFT/NFT nonce differs from the required one
+	ErrCodeTokenNonceMismatch = "token_nonce_mismatch"
+	// ErrCodeInvalidTransferData indicates the transaction data is not a well-formed transfer
+	ErrCodeInvalidTransferData = "invalid_transfer_data"
+	// ErrCodeInvalidRequirements indicates the payment requirements themselves are incomplete or malformed
+	ErrCodeInvalidRequirements = "invalid_requirements"
+	// ErrCodeSCCallMismatch indicates the smart contract function or arguments differ from the required call
+	ErrCodeSCCallMismatch = "sc_call_mismatch"
+	// ErrCodeInconsistentTransaction indicates the version or options do not fit the transfer
+	ErrCodeInconsistentTransaction = "inconsistent_transaction"
+	// ErrCodePaymentReplayed indicates the sender nonce on this chain was already settled
+	ErrCodePaymentReplayed = "payment_replayed"
+	// ErrCodeInvalidValueFormat indicates the transaction value is not a canonical base-10 integer
+	ErrCodeInvalidValueFormat = "invalid_value_format"
+	// ErrCodeFeeNotCovered indicates a relayed token payment does not cover the relayer fee
+	ErrCodeFeeNotCovered = "fee_not_covered"
+	// ErrCodeChainMismatch indicates the payload was signed for another chain than the required network
+	ErrCodeChainMismatch = "chain_mismatch"
+	// ErrCodeInsufficientBalance indicates the sender EGLD balance does not cover the value and fee
+	ErrCodeInsufficientBalance = "insufficient_balance"
+	// ErrCodeMemoMismatch indicates a plain EGLD transfer does not carry the expected memo
+	ErrCodeMemoMismatch = "memo_mismatch"
+	// ErrCodeSelfPayment indicates the payment sends the funds back to its own sender
+	ErrCodeSelfPayment = "self_payment"
+	// ErrCodeMissingGuardianSignature indicates a guarded transaction lacks its guardian or guardian signature
+	ErrCodeMissingGuardianSignature = "missing_guardian_signature"
+	// ErrCodeInvalidGuardianSignature indicates the guardian signature does not match the guardian address
+	ErrCodeInvalidGuardianSignature = "invalid_guardian_signature"
+)
+
+// ContractRejectedError carries the message a smart contract returned through signalError
+type ContractRejectedError struct {
+	Message string
+}
+
+// Error implements the error interface
+func (e *ContractRejectedError) Error() string {
+	return "contract rejected: " + e.Message
+}
+
+// NodeErrorMapping translates node error messages containing Match into the x402 error Reason
+type NodeErrorMapping struct {
+	Match  string
+	Reason string
+}
+
+// DefaultNodeErrorMappings covers the rejections returned by the MultiversX gateway and API
+var DefaultNodeErrorMappings = []NodeErrorMapping{
+	{Match: "insufficient funds", Reason: x402.ErrCodeInsufficientFunds},
+	{Match: "insufficient balance", Reason: x402.ErrCodeInsufficientFunds},
+	{Match: "lowerNonceInTx", Reason: ErrCodeInvalidNonce},
+	{Match: "nonce too low", Reason: ErrCodeInvalidNonce},
+	{Match: "higher nonce", Reason: ErrCodeInvalidNonce},
+	{Match: "invalid nonce", Reason: ErrCodeInvalidNonce},
+	{Match: "insufficient gas limit", Reason: ErrCodeGasTooLow},
+	{Match: "insufficient gas price", Reason: ErrCodeGasTooLow},
+	{Match: "not enough gas", Reason: ErrCodeGasTooLow},
+	{Match: "out of gas", Reason: ErrCodeGasTooLow},
+	{Match: "invalid signature", Reason: x402.ErrCodeSignatureInvalid},
+	{Match: "verification failed", Reason: x402.ErrCodeSignatureInvalid},
+}
+
+// MapNodeError returns the x402 error reason for a node error message
+// Matching is case-insensitive and the first matching entry wins.
+func MapNodeError(message string, mappings []NodeErrorMapping) (string, bool) {
+	lower := strings.ToLower(message)
+	for _, m := range mappings {
+		if m.Match == "" {
+			continue
+		}
+		if strings.Contains(lower, strings.ToLower(m.Match)) {
+			return m.Reason, true
+		}
+	}
+	return "", false
+}
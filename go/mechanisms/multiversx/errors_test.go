@@ -0,0 +1,28 @@
+package multiversx
+
+import (
+	"testing"
+
+	x402 "github.com/coinbase/x402/go"
+)
+
+func TestMapNodeError(t *testing.T) {
+	tests := []struct {
+		message    string
+		wantReason string
+		wantOK     bool
+	}{
+		{"insufficient funds", x402.ErrCodeInsufficientFunds, true},
+		{"Insufficient Funds for address erd1...", x402.ErrCodeInsufficientFunds, true},
+		{"lowerNonceInTx: true", ErrCodeInvalidNonce, true},
+		{"insufficient gas limit in tx", ErrCodeGasTooLow, true},
+		{"something unexpected", "", false},
+	}
+
+	for _, tt := range tests {
+		reason, ok := MapNodeError(tt.message, DefaultNodeErrorMappings)
+		if ok != tt.wantOK || reason != tt.wantReason {
+			t.Errorf("MapNodeError(%q) = (%s, %v); want (%s, %v)", tt.message, reason, ok, tt.wantReason, tt.wantOK)
+		}
+	}
+}
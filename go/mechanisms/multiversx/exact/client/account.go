@@ -0,0 +1,41 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/multiversx/mx-sdk-go/core"
+)
+
+// ErrNonceFetchTimeout is returned by CreatePaymentPayload when the sender account lookup exceeds WithAccountFetchTimeout
+var ErrNonceFetchTimeout = errors.New("nonce fetch timed out")
+
+// WithAccountFetchTimeout bounds the sender account lookup CreatePaymentPayload makes to read the nonce
+// Without it the lookup only ends with the caller's context, which can hang payment UIs on a slow gateway.
+func WithAccountFetchTimeout(d time.Duration) Option {
+	return func(s *ExactMultiversXScheme) {
+		s.accountTimeout = d
+	}
+}
+
+// fetchNonce returns the current nonce of the sender account
+func (s *ExactMultiversXScheme) fetchNonce(ctx context.Context, sender core.AddressHandler) (uint64, error) {
+	fetchCtx := ctx
+	if s.accountTimeout > 0 {
+		var cancel context.CancelFunc
+		fetchCtx, cancel = context.WithTimeout(ctx, s.accountTimeout)
+		defer cancel()
+	}
+
+	account, err := s.proxy.GetAccount(fetchCtx, sender)
+	if err != nil {
+		// Only the timeout of the lookup itself is reported as such; a cancelled caller context is passed through
+		if ctx.Err() == nil && errors.Is(fetchCtx.Err(), context.DeadlineExceeded) {
+			return 0, fmt.Errorf("%w after %s", ErrNonceFetchTimeout, s.accountTimeout)
+		}
+		return 0, fmt.Errorf("failed to fetch nonce: %w", err)
+	}
+	return account.Nonce, nil
+}
@@ -0,0 +1,39 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/coinbase/x402/go/types"
+)
+
+func TestCreatePaymentPayload_AccountFetchTimeout(t *testing.T) {
+	req := types.PaymentRequirements{
+		PayTo:  testPayTo,
+		Amount: "100",
+		Asset:  "EGLD",
+		Extra:  map[string]interface{}{"relayer": testPayTo},
+	}
+
+	scheme, _ := NewExactMultiversXScheme(&MockSigner{addr: testSender}, "multiversx:D",
+		WithProxy(&MockProxy{block: true}), WithAccountFetchTimeout(20*time.Millisecond))
+
+	start := time.Now()
+	_, err := scheme.CreatePaymentPayload(context.Background(), req)
+	if !errors.Is(err, ErrNonceFetchTimeout) {
+		t.Fatalf("CreatePaymentPayload() error = %v, want ErrNonceFetchTimeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("CreatePaymentPayload() returned after %s, want the 20ms timeout", elapsed)
+	}
+
+	// A cancelled caller context is not reported as a timeout
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = scheme.CreatePaymentPayload(ctx, req)
+	if errors.Is(err, ErrNonceFetchTimeout) || !errors.Is(err, context.Canceled) {
+		t.Errorf("CreatePaymentPayload() error = %v, want context.Canceled", err)
+	}
+}
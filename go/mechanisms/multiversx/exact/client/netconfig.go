@@ -0,0 +1,72 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/multiversx/mx-sdk-go/data"
+
+	"github.com/coinbase/x402/go/mechanisms/multiversx"
+	"github.com/coinbase/x402/go/types"
+)
+
+// DefaultNetworkConfigTTL is how long the network config is reused before it is fetched again
+// The chain ID, minimum gas price and gas per byte rarely change, so one fetch serves many payments.
+const DefaultNetworkConfigTTL = 10 * time.Minute
+
+// WithNetworkConfigTTL sets how long the fetched network config is cached
+// A non-positive TTL fetches it for every payment.
+func WithNetworkConfigTTL(d time.Duration) Option {
+	return func(s *ExactMultiversXScheme) {
+		s.networkConfig.ttl = d
+	}
+}
+
+// networkConfigCache holds the last network config fetched by the scheme
+// A scheme serves a single network, so one entry is enough.
+type networkConfigCache struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	config    *data.NetworkConfig
+	fetchedAt time.Time
+}
+
+// getNetworkConfig returns the cached network config, fetching it when missing or expired
+func (s *ExactMultiversXScheme) getNetworkConfig(ctx context.Context) (*data.NetworkConfig, error) {
+	c := &s.networkConfig
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.config != nil && time.Since(c.fetchedAt) < c.ttl {
+		return c.config, nil
+	}
+
+	config, err := s.proxy.GetNetworkConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.config = config
+	c.fetchedAt = time.Now()
+	return config, nil
+}
+
+// gasPrice returns the gas price of the payment: Extra["gasPrice"] when set, otherwise the minimum gas price
+// The minimum is the network minimum, never below GasPriceDefault; it stays the default when the network
+// config cannot be fetched. An explicit gas price below the minimum would be refused by the node.
+func (s *ExactMultiversXScheme) gasPrice(ctx context.Context, requirements types.PaymentRequirements) (uint64, error) {
+	minimum := uint64(multiversx.GasPriceDefault)
+	if config, err := s.getNetworkConfig(ctx); err == nil && config != nil && config.MinGasPrice > minimum {
+		minimum = config.MinGasPrice
+	}
+
+	explicit, ok := multiversx.ExtraUint64(requirements.Extra, "gasPrice")
+	if !ok {
+		return minimum, nil
+	}
+	if explicit < minimum {
+		return 0, fmt.Errorf("gasPrice %d is below the network minimum %d", explicit, minimum)
+	}
+	return explicit, nil
+}
@@ -0,0 +1,92 @@
+package client
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coinbase/x402/go/mechanisms/multiversx"
+	"github.com/coinbase/x402/go/types"
+)
+
+func TestCreatePaymentPayload_CachesNetworkConfig(t *testing.T) {
+	req := types.PaymentRequirements{
+		PayTo:  testPayTo,
+		Amount: "100",
+		Asset:  "EGLD",
+		Extra:  map[string]interface{}{"assetTransferMethod": multiversx.TransferMethodDirect},
+	}
+
+	mockProxy := &MockProxy{minGasPrice: 1500000000}
+	scheme, _ := NewExactMultiversXScheme(&MockSigner{addr: testSender}, "multiversx:D", WithProxy(mockProxy))
+
+	for i := 0; i < 3; i++ {
+		payload, err := scheme.CreatePaymentPayload(context.Background(), req)
+		if err != nil {
+			t.Fatalf("CreatePaymentPayload() error = %v", err)
+		}
+		rp, _ := multiversx.PayloadFromMap(payload.Payload)
+		if rp.GasPrice != 1500000000 {
+			t.Errorf("GasPrice = %d, want the network minimum 1500000000", rp.GasPrice)
+		}
+	}
+	if mockProxy.configCalls != 1 {
+		t.Errorf("GetNetworkConfig calls = %d, want 1 within the TTL", mockProxy.configCalls)
+	}
+
+	// An expired entry is fetched again
+	expiring := &MockProxy{}
+	scheme, _ = NewExactMultiversXScheme(&MockSigner{addr: testSender}, "multiversx:D", WithProxy(expiring), WithNetworkConfigTTL(time.Nanosecond))
+	for i := 0; i < 2; i++ {
+		time.Sleep(time.Millisecond)
+		if _, err := scheme.CreatePaymentPayload(context.Background(), req); err != nil {
+			t.Fatalf("CreatePaymentPayload() error = %v", err)
+		}
+	}
+	if expiring.configCalls != 2 {
+		t.Errorf("GetNetworkConfig calls = %d, want 2 after expiry", expiring.configCalls)
+	}
+}
+
+func TestCreatePaymentPayload_GasPrice(t *testing.T) {
+	tests := []struct {
+		name        string
+		minGasPrice uint64
+		explicit    interface{}
+		want        uint64
+		wantErr     bool
+	}{
+		{"network minimum above default", 2000000000, nil, 2000000000, false},
+		{"network minimum below default", 500000000, nil, multiversx.GasPriceDefault, false},
+		{"explicit above minimum", 2000000000, float64(3000000000), 3000000000, false},
+		{"explicit equal to minimum", 2000000000, uint64(2000000000), 2000000000, false},
+		{"explicit below minimum", 2000000000, float64(1000000000), 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			extra := map[string]interface{}{"assetTransferMethod": multiversx.TransferMethodDirect}
+			if tt.explicit != nil {
+				extra["gasPrice"] = tt.explicit
+			}
+			req := types.PaymentRequirements{PayTo: testPayTo, Amount: "100", Asset: "EGLD", Extra: extra}
+
+			scheme, _ := NewExactMultiversXScheme(&MockSigner{addr: testSender}, "multiversx:D", WithProxy(&MockProxy{minGasPrice: tt.minGasPrice}))
+			payload, err := scheme.CreatePaymentPayload(context.Background(), req)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "below the network minimum") {
+					t.Errorf("CreatePaymentPayload() error = %v, want a gas price below the minimum", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreatePaymentPayload() error = %v", err)
+			}
+			rp, _ := multiversx.PayloadFromMap(payload.Payload)
+			if rp.GasPrice != tt.want {
+				t.Errorf("GasPrice = %d, want %d", rp.GasPrice, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// ErrRelayerNotAllowed is returned by CreatePaymentPayload when the required relayer is not in the WithRelayerAllowlist
+var ErrRelayerNotAllowed = errors.New("relayer not allowed")
+
+// WithRelayerAllowlist restricts the relayers CreatePaymentPayload signs for
+// A relayed transaction commits to its relayer, which alone can broadcast it: an unknown relayer could
+// hold the payment back. Requirements naming another relayer fail with ErrRelayerNotAllowed.
+func WithRelayerAllowlist(relayers []string) Option {
+	return func(s *ExactMultiversXScheme) {
+		s.relayerAllowlist = relayers
+	}
+}
+
+// checkRelayer verifies the relayer is allowed, when an allowlist is configured
+func (s *ExactMultiversXScheme) checkRelayer(relayer string) error {
+	if s.relayerAllowlist == nil || slices.Contains(s.relayerAllowlist, relayer) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrRelayerNotAllowed, relayer)
+}
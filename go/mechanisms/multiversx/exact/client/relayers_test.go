@@ -0,0 +1,55 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/coinbase/x402/go/mechanisms/multiversx"
+	"github.com/coinbase/x402/go/types"
+)
+
+func TestWithRelayerAllowlist(t *testing.T) {
+	trusted := "erd1qyu5wthldzr8wx5c9ucg8kjagg0jfs53s8nr3zpz3hypefsdd8ssycr6th"
+
+	tests := []struct {
+		name    string
+		relayer string
+		wantErr bool
+	}{
+		{"allowed relayer", trusted, false},
+		{"unknown relayer", testSender, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockProxy := &MockProxy{nonce: 3}
+			scheme, _ := NewExactMultiversXScheme(&MockSigner{addr: testSender}, "multiversx:D", WithProxy(mockProxy), WithRelayerAllowlist([]string{trusted}))
+
+			req := types.PaymentRequirements{
+				PayTo:   testPayTo,
+				Amount:  "100",
+				Asset:   "EGLD",
+				Network: "multiversx:D",
+				Extra:   map[string]interface{}{"relayer": tt.relayer},
+			}
+			payload, err := scheme.CreatePaymentPayload(context.Background(), req)
+			if tt.wantErr {
+				if !errors.Is(err, ErrRelayerNotAllowed) {
+					t.Fatalf("CreatePaymentPayload() error = %v, want ErrRelayerNotAllowed", err)
+				}
+				if mockProxy.accountCalls != 0 {
+					t.Errorf("proxy called %d times for a disallowed relayer", mockProxy.accountCalls)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreatePaymentPayload() error = %v", err)
+			}
+			rp, _ := multiversx.PayloadFromMap(payload.Payload)
+			if rp.Relayer != trusted {
+				t.Errorf("payload relayer = %s, want %s", rp.Relayer, trusted)
+			}
+		})
+	}
+}
@@ -2,10 +2,7 @@ package client
 
 import (
 	"context"
-	"encoding/hex"
 	"fmt"
-	"math/big"
-	"strings"
 	"time"
 
 	"github.com/multiversx/mx-sdk-go/blockchain"
@@ -24,8 +21,22 @@ type ExactMultiversXScheme struct {
 	network x402.Network
 	chainID string
 	proxy   blockchain.Proxy
+
+	gasEstimator GasEstimator
+	simulate     bool
+	apiURL       string
+	apiOverrides map[string]string
+
+	networkConfig    networkConfigCache
+	accountTimeout   time.Duration
+	preferredAssets  []string
+	relayerAllowlist []string
 }
 
+// GasEstimator computes the gas limit of a payment transaction
+// It receives the unsigned payload with GasLimit set to the default estimate; returning 0 keeps the default.
+type GasEstimator func(payload multiversx.ExactRelayedPayload) uint64
+
 // Option defines functional options for ExactMultiversXScheme
 type Option func(*ExactMultiversXScheme)
 
@@ -36,26 +47,52 @@ func WithProxy(proxy blockchain.Proxy) Option {
 	}
 }
 
-// NewExactMultiversXScheme creates a new client scheme instance
-func NewExactMultiversXScheme(signer multiversx.ClientMultiversXSigner, network x402.Network, opts ...Option) (*ExactMultiversXScheme, error) {
-	chainID, err := multiversx.GetMultiversXChainId(string(network))
-	if err != nil {
-		return nil, err
+// WithGasEstimator plugs contract-specific gas logic in place of the default CalculateGasLimit formula
+// An explicit Extra["gasLimit"] in the requirements still takes precedence.
+func WithGasEstimator(estimator GasEstimator) Option {
+	return func(s *ExactMultiversXScheme) {
+		s.gasEstimator = estimator
+	}
+}
+
+// WithChainID sets the chain ID payments are signed for instead of deriving it from the network
+// It is meant for private and sovereign chains whose network reference is not a public chain ID;
+// such chains also need their gateway set with WithAPIURL or WithAPIURLOverride.
+func WithChainID(chainID string) Option {
+	return func(s *ExactMultiversXScheme) {
+		s.chainID = chainID
 	}
+}
 
+// NewExactMultiversXScheme creates a new client scheme instance
+func NewExactMultiversXScheme(signer multiversx.ClientMultiversXSigner, network x402.Network, opts ...Option) (*ExactMultiversXScheme, error) {
 	s := &ExactMultiversXScheme{
-		signer:  signer,
-		network: network,
-		chainID: chainID,
+		signer:        signer,
+		network:       network,
+		networkConfig: networkConfigCache{ttl: DefaultNetworkConfigTTL},
 	}
 	for _, opt := range opts {
 		opt(s)
 	}
 
+	var err error
+	if s.chainID == "" {
+		s.chainID, err = multiversx.GetMultiversXChainId(string(network))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if s.apiURL == "" {
+		s.apiURL, err = multiversx.GetAPIURLStrict(s.chainID, s.apiOverrides)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	if s.proxy == nil {
-		apiURL := multiversx.GetAPIURL(s.chainID)
 		args := blockchain.ArgsProxy{
-			ProxyURL:            apiURL,
+			ProxyURL:            s.apiURL,
 			Client:              nil,
 			SameScState:         false,
 			ShouldBeSynced:      false,
@@ -87,37 +124,66 @@ func (s *ExactMultiversXScheme) CreatePaymentPayload(ctx context.Context, requir
 		return types.PaymentPayload{}, fmt.Errorf("invalid PayTo address (must be valid Bech32): %w", err)
 	}
 
-	transferMethod, _ := requirements.Extra["assetTransferMethod"].(string)
+	// Reject malformed amounts ("1.5", "-100", "1e3") before they end up in the transaction value
+	if _, err := multiversx.CheckAmount(requirements.Amount); err != nil {
+		return types.PaymentPayload{}, err
+	}
+
+	if advertised, ok := requirements.Extra[multiversx.ExtraX402Versions]; ok {
+		versions, err := multiversx.ParseX402Versions(advertised)
+		if err != nil {
+			return types.PaymentPayload{}, err
+		}
+		if !multiversx.ContainsX402Version(versions, x402.ProtocolVersion) {
+			return types.PaymentPayload{}, fmt.Errorf("version mismatch: client speaks x402 version %d, server accepts %v", x402.ProtocolVersion, versions)
+		}
+	}
+
+	transferMethod, _ := multiversx.ExtraString(requirements.Extra, "assetTransferMethod")
+
+	// A facilitator relaying with relayedTxV1 wraps a plain transaction signed without relayer fields
+	relayVersion, _ := multiversx.ExtraUint64(requirements.Extra, multiversx.ExtraRelayVersion)
+	relayedV1 := transferMethod != multiversx.TransferMethodDirect && relayVersion == multiversx.RelayVersionV1
 
 	version := uint32(2)
 	// If explicitly set to direct, use version 1, otherwise default to version 2 (relayed)
 	if transferMethod == multiversx.TransferMethodDirect {
 		version = 1
+	} else if relayedV1 {
+		version = multiversx.TxVersionRelayedV1Inner
+	}
+
+	// Extract relayer info, rejecting a malformed relayer before any network call
+	var relayer string
+	if transferMethod != multiversx.TransferMethodDirect && !relayedV1 {
+		var ok bool
+		relayer, ok = multiversx.ExtraString(requirements.Extra, "relayer")
+		if !ok {
+			return types.PaymentPayload{}, fmt.Errorf("relayer address is required for relayed transfers")
+		}
+		if !multiversx.IsValidAddress(relayer) {
+			return types.PaymentPayload{}, fmt.Errorf("invalid relayer address (must be valid Bech32): %s", relayer)
+		}
+		if err := s.checkRelayer(relayer); err != nil {
+			return types.PaymentPayload{}, err
+		}
 	}
 
 	chainID := s.chainID
 
 	sender := s.signer.Address()
-	gasPrice := uint64(multiversx.GasPriceDefault)
+	gasPrice, err := s.gasPrice(ctx, requirements)
+	if err != nil {
+		return types.PaymentPayload{}, err
+	}
 
 	senderAddr, err := data.NewAddressFromBech32String(sender)
 	if err != nil {
 		return types.PaymentPayload{}, fmt.Errorf("invalid sender address: %w", err)
 	}
-	account, err := s.proxy.GetAccount(ctx, senderAddr)
+	nonce, err := s.fetchNonce(ctx, senderAddr)
 	if err != nil {
-		return types.PaymentPayload{}, fmt.Errorf("failed to fetch nonce: %w", err)
-	}
-	nonce := account.Nonce
-
-	// Extract relayer info
-	var relayer string
-	if transferMethod != multiversx.TransferMethodDirect {
-		var ok bool
-		relayer, ok = requirements.Extra["relayer"].(string)
-		if !ok || relayer == "" {
-			return types.PaymentPayload{}, fmt.Errorf("relayer address is required for relayed transfers")
-		}
+		return types.PaymentPayload{}, err
 	}
 
 	asset := requirements.Asset
@@ -133,11 +199,9 @@ func (s *ExactMultiversXScheme) CreatePaymentPayload(ctx context.Context, requir
 
 	gasLimit := s.calculateGasLimit(requirements, dataString)
 
-	now := time.Now().Unix()
-	validAfter := uint64(now - 600)
-	validBefore := uint64(now + 600) // Default 10 min buffer
-	if requirements.MaxTimeoutSeconds > 0 {
-		validBefore = uint64(now + int64(requirements.MaxTimeoutSeconds))
+	validAfter, validBefore, err := s.validityWindow(requirements)
+	if err != nil {
+		return types.PaymentPayload{}, err
 	}
 
 	txData := multiversx.ExactRelayedPayload{
@@ -155,6 +219,7 @@ func (s *ExactMultiversXScheme) CreatePaymentPayload(ctx context.Context, requir
 		ValidAfter:  validAfter,
 		ValidBefore: validBefore,
 	}
+	txData.GasLimit = s.estimateGas(requirements, txData)
 
 	// Sign transaction using SDK builder
 	cryptoHolder, err := multiversx.NewSimpleCryptoHolderFromBytes(s.signer.PrivateKey())
@@ -162,39 +227,93 @@ func (s *ExactMultiversXScheme) CreatePaymentPayload(ctx context.Context, requir
 		return types.PaymentPayload{}, fmt.Errorf("failed to create crypto holder: %w", err)
 	}
 
-	tx := txData.ToTransaction()
-	if err := multiversx.SignTransactionWithBuilder(cryptoHolder, &tx, false); err != nil {
+	if err := multiversx.SignPayload(cryptoHolder, &txData); err != nil {
 		return types.PaymentPayload{}, fmt.Errorf("failed to sign transaction: %w", err)
 	}
-	txData.Signature = tx.Signature
+
+	if s.simulate {
+		tx := txData.ToTransaction()
+		if err := s.simulateTransaction(ctx, &tx); err != nil {
+			return types.PaymentPayload{}, err
+		}
+	}
 
 	finalMap := txData.ToMap()
 
 	return types.PaymentPayload{
-		X402Version: 2,
+		X402Version: x402.ProtocolVersion,
 		Payload:     finalMap,
 	}, nil
 }
 
+// validityWindow returns the validAfter/validBefore bounds for the payload
+// Explicit Extra["validAfter"] and Extra["validBefore"] from the server take precedence over the defaults.
+// A validAfter in the future schedules the payment: the default validBefore then counts from validAfter.
+func (s *ExactMultiversXScheme) validityWindow(requirements types.PaymentRequirements) (uint64, uint64, error) {
+	now := time.Now().Unix()
+	timeout := int64(600) // Default 10 min buffer
+	if requirements.MaxTimeoutSeconds > 0 {
+		timeout = int64(requirements.MaxTimeoutSeconds)
+	}
+
+	validAfter := uint64(now - 600)
+	validBefore := uint64(now + timeout)
+
+	if val, ok := multiversx.ExtraUint64(requirements.Extra, "validAfter"); ok {
+		validAfter = val
+		if val > uint64(now) {
+			validBefore = val + uint64(timeout)
+		}
+	}
+	if val, ok := multiversx.ExtraUint64(requirements.Extra, "validBefore"); ok {
+		validBefore = val
+	}
+
+	if validAfter >= validBefore {
+		return 0, 0, fmt.Errorf("invalid validity window: validAfter (%d) must be before validBefore (%d)", validAfter, validBefore)
+	}
+	if validBefore <= uint64(now) {
+		return 0, 0, fmt.Errorf("payment requirement expired: validBefore (%d) is not in the future", validBefore)
+	}
+
+	return validAfter, validBefore, nil
+}
+
+// estimateGas applies the configured gas estimator on top of the default gas limit
+func (s *ExactMultiversXScheme) estimateGas(requirements types.PaymentRequirements, payload multiversx.ExactRelayedPayload) uint64 {
+	if s.gasEstimator == nil {
+		return payload.GasLimit
+	}
+	if _, explicit := multiversx.ExtraUint64(requirements.Extra, "gasLimit"); explicit {
+		return payload.GasLimit
+	}
+	if gasLimit := s.gasEstimator(payload); gasLimit > 0 {
+		return gasLimit
+	}
+	return payload.GasLimit
+}
+
 func (s *ExactMultiversXScheme) calculateGasLimit(requirements types.PaymentRequirements, dataString string) uint64 {
-	if gl, ok := requirements.Extra["gasLimit"].(uint64); ok {
+	if gl, ok := multiversx.ExtraUint64(requirements.Extra, "gasLimit"); ok {
 		return gl
-	} else if glFloat, ok := requirements.Extra["gasLimit"].(float64); ok {
-		return uint64(glFloat)
 	}
 
 	asset := requirements.Asset
 
 	// Fallback calculation using utils
-	// Count number of transfers - currently strictly 1 for this flow
+	// Count one transfer per token of the bundle (a single one without Extra["transfers"])
 	// Add 10M buffer if SC call (dataString not empty implies potential SC or ESDT transfer)
 	// For standard EGLD transfer dataString is empty
+	numTransfers := 1
+	if transfers, err := multiversx.RequiredTransfers(requirements); err == nil {
+		numTransfers = len(transfers)
+	}
 
 	// Base gas limit
-	gasLimit := multiversx.CalculateGasLimit([]byte(dataString), 1)
+	gasLimit := multiversx.CalculateGasLimit([]byte(dataString), numTransfers)
 
 	// Check for SC call indicator (if any extra arguments or SC function passed)
-	scFunction, _ := requirements.Extra["scFunction"].(string)
+	scFunction, _ := multiversx.ExtraString(requirements.Extra, "scFunction")
 	isScCall := scFunction != "" || (asset != multiversx.NativeTokenTicker)
 
 	if isScCall {
@@ -205,60 +324,15 @@ func (s *ExactMultiversXScheme) calculateGasLimit(requirements types.PaymentRequ
 }
 
 func (s *ExactMultiversXScheme) constructTransferData(requirements types.PaymentRequirements, sender string) (string, string, string, error) {
-	asset := requirements.Asset
-	scFunction, _ := requirements.Extra["scFunction"].(string)
-
-	var arguments []string
-	if argsInterface, ok := requirements.Extra["arguments"].([]string); ok {
-		arguments = argsInterface
-	}
-
-	if asset != multiversx.NativeTokenTicker {
-		// Token Transfer (ESDT)
-		receiver := sender
-		value := "0"
-
-		payToAddr, _ := data.NewAddressFromBech32String(requirements.PayTo)
-		destHex := hex.EncodeToString(payToAddr.AddressBytes())
-
-		tokenHex := hex.EncodeToString([]byte(asset))
-
-		amtBig, ok := new(big.Int).SetString(requirements.Amount, 10)
-		if !ok {
-			return "", "", "", fmt.Errorf("invalid amount: %s", requirements.Amount)
-		}
-		amtHex := hex.EncodeToString(amtBig.Bytes())
-
-		parts := []string{
-			"MultiESDTNFTTransfer",
-			destHex,
-			"01",
-			tokenHex,
-			"00",
-			amtHex,
-		}
-
-		if scFunction != "" {
-			parts = append(parts, hex.EncodeToString([]byte(scFunction)))
-			if len(arguments) > 0 {
-				parts = append(parts, arguments...)
-			}
-		}
-
-		return strings.Join(parts, "@"), receiver, value, nil
+	handler, err := multiversx.ResolveTransferMethodHandler(requirements)
+	if err != nil {
+		return "", "", "", err
 	}
 
-	// Native EGLD Transfer
-	receiver := requirements.PayTo
-	value := requirements.Amount
-
-	var parts []string
-	if scFunction != "" {
-		parts = append(parts, scFunction)
-		if len(arguments) > 0 {
-			parts = append(parts, arguments...)
-		}
+	fields, err := handler.Encode(requirements, sender)
+	if err != nil {
+		return "", "", "", err
 	}
 
-	return strings.Join(parts, "@"), receiver, value, nil
+	return fields.Data, fields.Receiver, fields.Value, nil
 }
@@ -2,14 +2,19 @@ package client
 
 import (
 	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/multiversx/mx-chain-core-go/data/api"
 	"github.com/multiversx/mx-chain-core-go/data/transaction"
 	"github.com/multiversx/mx-sdk-go/core"
 	"github.com/multiversx/mx-sdk-go/data"
 
+	x402 "github.com/coinbase/x402/go"
 	"github.com/coinbase/x402/go/mechanisms/multiversx"
 
 	"github.com/coinbase/x402/go/types"
@@ -47,12 +52,22 @@ const (
 
 // MockProxy implements Proxy interface
 type MockProxy struct {
-	nonce uint64
-	err   error
+	nonce        uint64
+	err          error
+	accountCalls int
+	configCalls  int
+	minGasPrice  uint64
+	// block makes GetAccount wait for its context to end, as a hanging gateway would
+	block bool
 }
 
 // GetAccount must match blockchain.Proxy interface
 func (m *MockProxy) GetAccount(ctx context.Context, address core.AddressHandler) (*data.Account, error) {
+	m.accountCalls++
+	if m.block {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
 	return &data.Account{
 		Nonce: m.nonce,
 	}, m.err
@@ -63,9 +78,14 @@ func (m *MockProxy) SendTransaction(ctx context.Context, tx *transaction.Fronten
 }
 
 func (m *MockProxy) GetNetworkConfig(ctx context.Context) (*data.NetworkConfig, error) {
+	m.configCalls++
+	minGasPrice := m.minGasPrice
+	if minGasPrice == 0 {
+		minGasPrice = 1000000000
+	}
 	return &data.NetworkConfig{
 		MinGasLimit: 50000,
-		MinGasPrice: 1000000000,
+		MinGasPrice: minGasPrice,
 		ChainID:     "D",
 	}, nil
 }
@@ -252,7 +272,7 @@ func TestCreatePaymentPayload_EGLD_Alias(t *testing.T) {
 	req := types.PaymentRequirements{
 		PayTo:   testPayTo,
 		Amount:  "100",
-		Asset:   "EGLD-000000", // Should be treated as EGLD if handled or ESDT token otherwise
+		Asset:   multiversx.NativeTokenMultiTransferID,
 		Network: "multiversx:D",
 		Extra: map[string]interface{}{
 			"relayer": testSender,
@@ -270,11 +290,8 @@ func TestCreatePaymentPayload_EGLD_Alias(t *testing.T) {
 	}
 	rp := *rpPtr
 
-	// Should be ESDT transfer (MultiESDTNFTTransfer)
-	// Because we treat EGLD-000000 as a token identifier for MultiESDT.
-
-	// Value should be 0 (Native EGLD not sent via Value field in MultiESDT usually, unless implied?)
-	// Actually, if using EGLD-000000 in MultiESDT, the 'value' of tx is 0, and amount is in data.
+	// EGLD-000000 is native EGLD sent within a MultiESDTNFTTransfer: the amount is in the data
+	// and the transaction value stays 0, as the facilitator verifies it
 	if rp.Value != "0" {
 		t.Errorf("Value should be 0 for MultiESDT, got %s", rp.Value)
 	}
@@ -290,3 +307,439 @@ func TestCreatePaymentPayload_EGLD_Alias(t *testing.T) {
 		t.Errorf("Data should contain EGLD-000000 hex %s, got %s", tokenHex, rp.Data)
 	}
 }
+
+// invoiceTransferHandler is a custom transfer method that pays EGLD to an invoice endpoint
+type invoiceTransferHandler struct{}
+
+func (h *invoiceTransferHandler) Encode(requirements types.PaymentRequirements, sender string) (multiversx.TransferFields, error) {
+	invoice, _ := requirements.Extra["invoice"].(string)
+	return multiversx.TransferFields{
+		Receiver: requirements.PayTo,
+		Value:    requirements.Amount,
+		Data:     "payInvoice@" + hex.EncodeToString([]byte(invoice)),
+	}, nil
+}
+
+func (h *invoiceTransferHandler) Verify(payload multiversx.ExactRelayedPayload, requirements types.PaymentRequirements) error {
+	invoice, _ := requirements.Extra["invoice"].(string)
+	if payload.Data != "payInvoice@"+hex.EncodeToString([]byte(invoice)) {
+		return fmt.Errorf("invoice mismatch: %s", payload.Data)
+	}
+	if payload.Receiver != requirements.PayTo || !multiversx.CheckBigInt(payload.Value, requirements.Amount) {
+		return fmt.Errorf("transfer mismatch")
+	}
+	return nil
+}
+
+func TestCreatePaymentPayload_CustomTransferMethod(t *testing.T) {
+	if err := multiversx.RegisterTransferMethod("invoice", &invoiceTransferHandler{}); err != nil {
+		t.Fatalf("Failed to register transfer method: %v", err)
+	}
+	t.Cleanup(func() { _ = multiversx.UnregisterTransferMethod("invoice") })
+
+	signer := &MockSigner{addr: testSender}
+	mockProxy := &MockProxy{nonce: 35}
+	scheme, _ := NewExactMultiversXScheme(signer, "multiversx:D", WithProxy(mockProxy))
+
+	req := types.PaymentRequirements{
+		PayTo:   testPayTo,
+		Amount:  "100",
+		Asset:   "EGLD",
+		Network: "multiversx:D",
+		Extra: map[string]interface{}{
+			"assetTransferMethod": "invoice",
+			"invoice":             "inv-42",
+			"relayer":             testSender,
+		},
+	}
+
+	payload, err := scheme.CreatePaymentPayload(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Failed to create payload: %v", err)
+	}
+
+	rp, err := multiversx.PayloadFromMap(payload.Payload)
+	if err != nil {
+		t.Fatalf("Failed to parse payload: %v", err)
+	}
+
+	expectedData := "payInvoice@" + hex.EncodeToString([]byte("inv-42"))
+	if rp.Data != expectedData {
+		t.Errorf("Wrong data: expected %s, got %s", expectedData, rp.Data)
+	}
+	if rp.Version != 2 || rp.Relayer != testSender {
+		t.Errorf("Custom transfer methods should be relayed, got version %d relayer %s", rp.Version, rp.Relayer)
+	}
+
+	handler, err := multiversx.ResolveTransferMethodHandler(req)
+	if err != nil {
+		t.Fatalf("Failed to resolve handler: %v", err)
+	}
+	if err := handler.Verify(*rp, req); err != nil {
+		t.Errorf("Round-trip verification failed: %v", err)
+	}
+
+	req.Extra["invoice"] = "inv-43"
+	if err := handler.Verify(*rp, req); err == nil {
+		t.Error("Expected verification to fail for a different invoice")
+	}
+}
+
+func TestCreatePaymentPayload_UnknownTransferMethod(t *testing.T) {
+	signer := &MockSigner{addr: testSender}
+	scheme, _ := NewExactMultiversXScheme(signer, "multiversx:D", WithProxy(&MockProxy{}))
+
+	req := types.PaymentRequirements{
+		PayTo:   testPayTo,
+		Amount:  "100",
+		Asset:   "EGLD",
+		Network: "multiversx:D",
+		Extra: map[string]interface{}{
+			"assetTransferMethod": "unknown",
+			"relayer":             testSender,
+		},
+	}
+
+	if _, err := scheme.CreatePaymentPayload(context.Background(), req); err == nil {
+		t.Fatal("Expected error for unregistered transfer method")
+	}
+}
+
+func TestCreatePaymentPayload_CustomChainID(t *testing.T) {
+	signer := &MockSigner{addr: testSender}
+	mockProxy := &MockProxy{nonce: 1}
+
+	// A sovereign chain reference is not a public chain ID and cannot be parsed from the network
+	if _, err := NewExactMultiversXScheme(signer, "multiversx:S1", WithProxy(mockProxy)); err == nil {
+		t.Fatal("Expected an error for an unknown network without WithChainID")
+	}
+
+	scheme, err := NewExactMultiversXScheme(signer, "multiversx:S1", WithProxy(mockProxy), WithChainID("S1"), WithAPIURL("http://localhost:7950"))
+	if err != nil {
+		t.Fatalf("NewExactMultiversXScheme() error = %v", err)
+	}
+
+	req := types.PaymentRequirements{
+		PayTo:   testPayTo,
+		Amount:  "100",
+		Asset:   "EGLD",
+		Network: "multiversx:S1",
+		Extra:   map[string]interface{}{"relayer": testSender},
+	}
+	payload, err := scheme.CreatePaymentPayload(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Failed to create payload: %v", err)
+	}
+	rp, err := multiversx.PayloadFromMap(payload.Payload)
+	if err != nil {
+		t.Fatalf("Failed to parse payload: %v", err)
+	}
+	if rp.ChainID != "S1" {
+		t.Errorf("payload chain ID = %q, want S1", rp.ChainID)
+	}
+}
+
+func TestCreatePaymentPayload_MalformedRelayer(t *testing.T) {
+	signer := &MockSigner{addr: testSender}
+	mockProxy := &MockProxy{}
+	scheme, _ := NewExactMultiversXScheme(signer, "multiversx:D", WithProxy(mockProxy))
+
+	badChecksum := testSender[:len(testSender)-1] + "q"
+	if badChecksum == testSender {
+		badChecksum = testSender[:len(testSender)-1] + "p"
+	}
+
+	for _, relayer := range []string{"erd1typo", badChecksum, "not-an-address"} {
+		req := types.PaymentRequirements{
+			PayTo:   testPayTo,
+			Amount:  "100",
+			Asset:   "EGLD",
+			Network: "multiversx:D",
+			Extra: map[string]interface{}{
+				"relayer": relayer,
+			},
+		}
+
+		_, err := scheme.CreatePaymentPayload(context.Background(), req)
+		if err == nil || !strings.Contains(err.Error(), "invalid relayer address") {
+			t.Errorf("relayer %q: expected invalid relayer error, got %v", relayer, err)
+		}
+	}
+	if mockProxy.accountCalls != 0 {
+		t.Errorf("proxy called %d times for malformed relayers", mockProxy.accountCalls)
+	}
+}
+
+func TestCreatePaymentPayload_InvalidAmount(t *testing.T) {
+	signer := &MockSigner{addr: testSender}
+	mockProxy := &MockProxy{}
+	scheme, _ := NewExactMultiversXScheme(signer, "multiversx:D", WithProxy(mockProxy))
+
+	tests := []struct {
+		amount  string
+		wantErr bool
+	}{
+		{"1.5", true},
+		{"-100", true},
+		{"1e3", true},
+		{"1000000000000000000", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.amount, func(t *testing.T) {
+			req := types.PaymentRequirements{
+				PayTo:   testPayTo,
+				Amount:  tt.amount,
+				Asset:   "EGLD",
+				Network: "multiversx:D",
+				Extra: map[string]interface{}{
+					"relayer": testSender,
+				},
+			}
+
+			payload, err := scheme.CreatePaymentPayload(context.Background(), req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CreatePaymentPayload() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if !strings.Contains(err.Error(), tt.amount) {
+					t.Errorf("Expected error to name the amount, got %v", err)
+				}
+				return
+			}
+			if payload.Payload["value"] != tt.amount {
+				t.Errorf("value = %v, want %s", payload.Payload["value"], tt.amount)
+			}
+		})
+	}
+}
+
+func TestCreatePaymentPayload_ValidityWindow(t *testing.T) {
+	signer := &MockSigner{addr: testSender}
+	scheme, _ := NewExactMultiversXScheme(signer, "multiversx:D", WithProxy(&MockProxy{nonce: 1}))
+	now := uint64(time.Now().Unix())
+
+	baseReq := func(extra map[string]interface{}) types.PaymentRequirements {
+		extra["relayer"] = testSender
+		return types.PaymentRequirements{
+			PayTo:   testPayTo,
+			Amount:  "100",
+			Asset:   "EGLD",
+			Network: "multiversx:D",
+			Extra:   extra,
+		}
+	}
+
+	t.Run("Explicit Window Honored", func(t *testing.T) {
+		req := baseReq(map[string]interface{}{
+			"validAfter":  float64(now - 10),
+			"validBefore": float64(now + 30),
+		})
+		payload, err := scheme.CreatePaymentPayload(context.Background(), req)
+		if err != nil {
+			t.Fatalf("Failed to create payload: %v", err)
+		}
+		rp, _ := multiversx.PayloadFromMap(payload.Payload)
+		if rp.ValidAfter != now-10 || rp.ValidBefore != now+30 {
+			t.Errorf("Expected window [%d, %d], got [%d, %d]", now-10, now+30, rp.ValidAfter, rp.ValidBefore)
+		}
+	})
+
+	t.Run("Scheduled ValidAfter", func(t *testing.T) {
+		nextWeek := now + 7*24*3600
+		req := baseReq(map[string]interface{}{
+			"validAfter": nextWeek,
+		})
+		req.MaxTimeoutSeconds = 300
+		payload, err := scheme.CreatePaymentPayload(context.Background(), req)
+		if err != nil {
+			t.Fatalf("Failed to create payload: %v", err)
+		}
+		rp, _ := multiversx.PayloadFromMap(payload.Payload)
+		if rp.ValidAfter != nextWeek || rp.ValidBefore != nextWeek+300 {
+			t.Errorf("Expected window [%d, %d], got [%d, %d]", nextWeek, nextWeek+300, rp.ValidAfter, rp.ValidBefore)
+		}
+	})
+
+	t.Run("Inverted Window", func(t *testing.T) {
+		req := baseReq(map[string]interface{}{
+			"validAfter":  now + 60,
+			"validBefore": now + 30,
+		})
+		if _, err := scheme.CreatePaymentPayload(context.Background(), req); err == nil {
+			t.Error("Expected error for validAfter after validBefore")
+		}
+	})
+
+	t.Run("Expired ValidBefore", func(t *testing.T) {
+		req := baseReq(map[string]interface{}{
+			"validBefore": now - 1,
+		})
+		if _, err := scheme.CreatePaymentPayload(context.Background(), req); err == nil {
+			t.Error("Expected error for validBefore in the past")
+		}
+	})
+}
+
+func TestCreatePaymentPayload_MultiTokenBundle(t *testing.T) {
+	signer := &MockSigner{addr: testSender}
+	mockProxy := &MockProxy{nonce: 3}
+	scheme, _ := NewExactMultiversXScheme(signer, "multiversx:D", WithProxy(mockProxy))
+
+	transfers := []interface{}{
+		map[string]interface{}{"asset": testAsset, "amount": "100"},
+		map[string]interface{}{"asset": "WEGLD-bd4d79", "amount": "5"},
+	}
+	req := types.PaymentRequirements{
+		PayTo:   testPayTo,
+		Amount:  "100",
+		Asset:   testAsset,
+		Network: "multiversx:D",
+		Extra: map[string]interface{}{
+			"relayer":   testSender,
+			"transfers": transfers,
+		},
+	}
+
+	payload, err := scheme.CreatePaymentPayload(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Failed to create payload: %v", err)
+	}
+
+	rp, err := multiversx.PayloadFromMap(payload.Payload)
+	if err != nil {
+		t.Fatalf("Failed to parse payload: %v", err)
+	}
+
+	parts := strings.Split(rp.Data, "@")
+	if len(parts) != 9 || parts[2] != "02" {
+		t.Fatalf("Expected 2-transfer MultiESDTNFTTransfer data, got %s", rp.Data)
+	}
+	if parts[6] != hex.EncodeToString([]byte("WEGLD-bd4d79")) || parts[8] != "05" {
+		t.Errorf("Second transfer encoded incorrectly: %s", rp.Data)
+	}
+
+	// Gas accounts for every transfer of the bundle
+	expectedGas := multiversx.CalculateGasLimit([]byte(rp.Data), 2) + 10_000_000
+	if rp.GasLimit != expectedGas {
+		t.Errorf("GasLimit = %d, want %d", rp.GasLimit, expectedGas)
+	}
+
+	handler, err := multiversx.ResolveTransferMethodHandler(req)
+	if err != nil {
+		t.Fatalf("ResolveTransferMethodHandler failed: %v", err)
+	}
+	if err := handler.Verify(*rp, req); err != nil {
+		t.Errorf("Bundle payload failed verification: %v", err)
+	}
+}
+
+func TestCreatePaymentPayload_GasEstimator(t *testing.T) {
+	signer := &MockSigner{addr: testSender}
+
+	var seen multiversx.ExactRelayedPayload
+	estimator := func(payload multiversx.ExactRelayedPayload) uint64 {
+		seen = payload
+		return 42_000_000
+	}
+	scheme, _ := NewExactMultiversXScheme(signer, "multiversx:D", WithProxy(&MockProxy{nonce: 1}), WithGasEstimator(estimator))
+
+	req := types.PaymentRequirements{
+		PayTo:   testPayTo,
+		Amount:  "100",
+		Asset:   testAsset,
+		Network: "multiversx:D",
+		Extra: map[string]interface{}{
+			"relayer": testSender,
+		},
+	}
+
+	payload, err := scheme.CreatePaymentPayload(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Failed to create payload: %v", err)
+	}
+	rp, _ := multiversx.PayloadFromMap(payload.Payload)
+	if rp.GasLimit != 42_000_000 {
+		t.Errorf("Expected estimator gas limit 42000000, got %d", rp.GasLimit)
+	}
+	if !strings.HasPrefix(seen.Data, "MultiESDTNFTTransfer") || seen.GasLimit == 0 {
+		t.Errorf("Expected estimator to receive the built payload with the default gas limit, got %+v", seen)
+	}
+
+	// An explicit gas limit from the server still wins
+	req.Extra["gasLimit"] = uint64(1_000_000)
+	payload, err = scheme.CreatePaymentPayload(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Failed to create payload: %v", err)
+	}
+	rp, _ = multiversx.PayloadFromMap(payload.Payload)
+	if rp.GasLimit != 1_000_000 {
+		t.Errorf("Expected explicit gas limit 1000000, got %d", rp.GasLimit)
+	}
+}
+
+func TestCreatePaymentPayload_VersionNegotiation(t *testing.T) {
+	signer := &MockSigner{addr: testSender}
+	scheme, _ := NewExactMultiversXScheme(signer, "multiversx:D", WithProxy(&MockProxy{nonce: 1}))
+
+	req := types.PaymentRequirements{
+		PayTo:   testPayTo,
+		Amount:  "100",
+		Asset:   "EGLD",
+		Network: "multiversx:D",
+		Extra: map[string]interface{}{
+			"assetTransferMethod":        multiversx.TransferMethodDirect,
+			multiversx.ExtraX402Versions: []interface{}{float64(2)},
+		},
+	}
+
+	payload, err := scheme.CreatePaymentPayload(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Failed to create payload: %v", err)
+	}
+	if payload.X402Version != x402.ProtocolVersion {
+		t.Errorf("Expected x402 version %d, got %d", x402.ProtocolVersion, payload.X402Version)
+	}
+
+	req.Extra[multiversx.ExtraX402Versions] = []interface{}{float64(3)}
+	if _, err := scheme.CreatePaymentPayload(context.Background(), req); err == nil || !strings.Contains(err.Error(), "version mismatch") {
+		t.Errorf("Expected version mismatch error, got %v", err)
+	}
+}
+
+func TestCreatePaymentPayload_SignsSigningBytes(t *testing.T) {
+	// The SDK builder signs as the address of the key, so the signer must hold it
+	holder, _ := multiversx.NewSimpleCryptoHolderFromBytes((&MockSigner{}).PrivateKey())
+	signer := &MockSigner{addr: holder.GetBech32()}
+	scheme, _ := NewExactMultiversXScheme(signer, "multiversx:D", WithProxy(&MockProxy{nonce: 7}))
+
+	payload, err := scheme.CreatePaymentPayload(context.Background(), types.PaymentRequirements{
+		PayTo:  testPayTo,
+		Amount: "100",
+		Asset:  "EGLD",
+		Extra:  map[string]interface{}{"relayer": testPayTo},
+	})
+	if err != nil {
+		t.Fatalf("CreatePaymentPayload() error = %v", err)
+	}
+	relayedPayload, _ := multiversx.PayloadFromMap(payload.Payload)
+
+	msgBytes, err := multiversx.SigningBytes(*relayedPayload)
+	if err != nil {
+		t.Fatalf("SigningBytes() error = %v", err)
+	}
+	sig, _ := hex.DecodeString(relayedPayload.Signature)
+	pubKey := ed25519.NewKeyFromSeed(signer.PrivateKey()).Public().(ed25519.PublicKey)
+	if !ed25519.Verify(pubKey, msgBytes, sig) {
+		t.Fatal("Expected the client signature to cover SigningBytes")
+	}
+
+	// The SDK transaction builder signs the same bytes
+	tx := relayedPayload.ToTransaction()
+	if err := multiversx.SignTransactionWithBuilder(holder, &tx, false); err != nil {
+		t.Fatalf("SignTransactionWithBuilder() error = %v", err)
+	}
+	if tx.Signature != relayedPayload.Signature {
+		t.Errorf("builder signature %s differs from the client signature %s", tx.Signature, relayedPayload.Signature)
+	}
+}
@@ -0,0 +1,117 @@
+package client
+
+import (
+	"context"
+	"math/big"
+	"slices"
+
+	"github.com/multiversx/mx-chain-core-go/data/api"
+	"github.com/multiversx/mx-sdk-go/core"
+	"github.com/multiversx/mx-sdk-go/data"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/mechanisms/multiversx"
+)
+
+// esdtBalanceReader is implemented by proxies able to read the token balance of an account,
+// as the SDK proxy does; without it only EGLD balances are checked by SelectionPolicy
+type esdtBalanceReader interface {
+	GetESDTTokenData(ctx context.Context, address core.AddressHandler, tokenIdentifier string, queryOptions api.AccountQueryOptions) (*data.ESDTFungibleTokenData, error)
+}
+
+// WithPreferredAssets sets the assets SelectionPolicy ranks first, most preferred first
+func WithPreferredAssets(assets ...string) Option {
+	return func(s *ExactMultiversXScheme) {
+		s.preferredAssets = assets
+	}
+}
+
+// SelectionPolicy returns a payment policy for servers offering several MultiversX requirements
+// Requirements of this scheme's network the signer cannot pay are dropped, and the remaining ones are
+// ordered by WithPreferredAssets so the default selector picks the preferred affordable asset. A balance
+// that cannot be read keeps its requirement; requirements of other networks are left as they are.
+// Register it with x402.WithPolicy; the balances are read with ctx.
+func (s *ExactMultiversXScheme) SelectionPolicy(ctx context.Context) x402.PaymentPolicy {
+	return func(requirements []x402.PaymentRequirementsView) []x402.PaymentRequirementsView {
+		balances := make(map[string]*big.Int)
+		var selected []x402.PaymentRequirementsView
+		for _, req := range requirements {
+			if s.handles(req) && !s.canAfford(ctx, req, balances) {
+				continue
+			}
+			selected = append(selected, req)
+		}
+
+		slices.SortStableFunc(selected, func(a, b x402.PaymentRequirementsView) int {
+			return s.preferenceRank(a) - s.preferenceRank(b)
+		})
+		return selected
+	}
+}
+
+// handles reports whether the requirement is paid through this scheme
+func (s *ExactMultiversXScheme) handles(req x402.PaymentRequirementsView) bool {
+	return req.GetScheme() == multiversx.SchemeExact && req.GetNetwork() == string(s.network)
+}
+
+// preferenceRank returns the position of the requirement asset in the preferred assets
+func (s *ExactMultiversXScheme) preferenceRank(req x402.PaymentRequirementsView) int {
+	if s.handles(req) {
+		if rank := slices.Index(s.preferredAssets, req.GetAsset()); rank >= 0 {
+			return rank
+		}
+	}
+	return len(s.preferredAssets)
+}
+
+// canAfford reports whether the signer balance of the requirement asset covers its amount
+func (s *ExactMultiversXScheme) canAfford(ctx context.Context, req x402.PaymentRequirementsView, balances map[string]*big.Int) bool {
+	amount, err := multiversx.CheckAmount(req.GetAmount())
+	if err != nil {
+		return false
+	}
+
+	asset := req.GetAsset()
+	balance, ok := balances[asset]
+	if !ok {
+		balance, ok = s.balanceOf(ctx, asset)
+		if !ok {
+			return true
+		}
+		balances[asset] = balance
+	}
+	return balance.Cmp(amount) >= 0
+}
+
+// balanceOf reads the signer balance of asset, reporting false when it cannot be read
+func (s *ExactMultiversXScheme) balanceOf(ctx context.Context, asset string) (*big.Int, bool) {
+	address, err := data.NewAddressFromBech32String(s.signer.Address())
+	if err != nil {
+		return nil, false
+	}
+
+	var balance string
+	if asset == "" || asset == multiversx.NativeTokenTicker {
+		account, err := s.proxy.GetAccount(ctx, address)
+		if err != nil || account == nil {
+			return nil, false
+		}
+		balance = account.Balance
+	} else {
+		reader, ok := s.proxy.(esdtBalanceReader)
+		if !ok {
+			return nil, false
+		}
+		token, err := reader.GetESDTTokenData(ctx, address, asset, api.AccountQueryOptions{})
+		if err != nil || token == nil {
+			return nil, false
+		}
+		balance = token.Balance
+	}
+
+	if balance == "" {
+		return new(big.Int), true
+	}
+	value, ok := new(big.Int).SetString(balance, 10)
+	return value, ok
+}
@@ -0,0 +1,109 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/multiversx/mx-chain-core-go/data/api"
+	"github.com/multiversx/mx-sdk-go/core"
+	"github.com/multiversx/mx-sdk-go/data"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/mechanisms/multiversx"
+	"github.com/coinbase/x402/go/types"
+)
+
+// balanceProxy serves account balances per asset, EGLD included
+type balanceProxy struct {
+	*MockProxy
+	balances map[string]string
+	// empty makes the proxy answer without an account or token and without an error
+	empty bool
+}
+
+func (p *balanceProxy) GetAccount(ctx context.Context, address core.AddressHandler) (*data.Account, error) {
+	if p.empty {
+		return nil, nil
+	}
+	return &data.Account{Balance: p.balances[multiversx.NativeTokenTicker]}, nil
+}
+
+func (p *balanceProxy) GetESDTTokenData(ctx context.Context, address core.AddressHandler, tokenIdentifier string, queryOptions api.AccountQueryOptions) (*data.ESDTFungibleTokenData, error) {
+	if p.empty {
+		return nil, nil
+	}
+	return &data.ESDTFungibleTokenData{TokenIdentifier: tokenIdentifier, Balance: p.balances[tokenIdentifier]}, nil
+}
+
+func TestSelectionPolicy(t *testing.T) {
+	egld := types.PaymentRequirements{Scheme: multiversx.SchemeExact, Network: "multiversx:D", Asset: multiversx.NativeTokenTicker, Amount: "1000", PayTo: testPayTo}
+	usdc := types.PaymentRequirements{Scheme: multiversx.SchemeExact, Network: "multiversx:D", Asset: "USDC-c76f1f", Amount: "500", PayTo: testPayTo}
+
+	tests := []struct {
+		name      string
+		balances  map[string]string
+		preferred []string
+		want      string
+	}{
+		{"Preferred Asset Affordable", map[string]string{"EGLD": "5000", "USDC-c76f1f": "500"}, []string{"USDC-c76f1f"}, "USDC-c76f1f"},
+		{"Preferred Asset Zero Balance", map[string]string{"EGLD": "5000"}, []string{"USDC-c76f1f"}, multiversx.NativeTokenTicker},
+		{"Preferred Asset Underfunded", map[string]string{"EGLD": "5000", "USDC-c76f1f": "499"}, []string{"USDC-c76f1f"}, multiversx.NativeTokenTicker},
+		{"No Preference Skips Unaffordable", map[string]string{"USDC-c76f1f": "800"}, nil, "USDC-c76f1f"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proxy := &balanceProxy{MockProxy: &MockProxy{}, balances: tt.balances}
+			scheme, err := NewExactMultiversXScheme(&MockSigner{addr: testSender}, "multiversx:D", WithProxy(proxy), WithPreferredAssets(tt.preferred...))
+			if err != nil {
+				t.Fatalf("NewExactMultiversXScheme() error = %v", err)
+			}
+			client := x402.Newx402Client(x402.WithPolicy(scheme.SelectionPolicy(context.Background())))
+			client.Register("multiversx:D", scheme)
+
+			selected, err := client.SelectPaymentRequirements([]types.PaymentRequirements{egld, usdc})
+			if err != nil {
+				t.Fatalf("SelectPaymentRequirements() error = %v", err)
+			}
+			if selected.Asset != tt.want {
+				t.Errorf("selected %s, want %s", selected.Asset, tt.want)
+			}
+		})
+	}
+
+	// Nothing affordable: the policy leaves no requirement to pay with
+	proxy := &balanceProxy{MockProxy: &MockProxy{}, balances: map[string]string{}}
+	scheme, _ := NewExactMultiversXScheme(&MockSigner{addr: testSender}, "multiversx:D", WithProxy(proxy))
+	client := x402.Newx402Client(x402.WithPolicy(scheme.SelectionPolicy(context.Background())))
+	client.Register("multiversx:D", scheme)
+	if _, err := client.SelectPaymentRequirements([]types.PaymentRequirements{egld, usdc}); err == nil {
+		t.Error("Expected an error when no requirement is affordable")
+	}
+}
+
+func TestSelectionPolicy_MissingAccount(t *testing.T) {
+	egld := types.PaymentRequirements{Scheme: multiversx.SchemeExact, Network: "multiversx:D", Asset: multiversx.NativeTokenTicker, Amount: "1000", PayTo: testPayTo}
+	usdc := types.PaymentRequirements{Scheme: multiversx.SchemeExact, Network: "multiversx:D", Asset: "USDC-c76f1f", Amount: "500", PayTo: testPayTo}
+
+	// A gateway answering without an account is an unreadable balance: nothing is filtered out
+	proxy := &balanceProxy{MockProxy: &MockProxy{}, empty: true}
+	scheme, err := NewExactMultiversXScheme(&MockSigner{addr: testSender}, "multiversx:D", WithProxy(proxy))
+	if err != nil {
+		t.Fatalf("NewExactMultiversXScheme() error = %v", err)
+	}
+	for _, asset := range []string{multiversx.NativeTokenTicker, "USDC-c76f1f"} {
+		if balance, ok := scheme.balanceOf(context.Background(), asset); ok {
+			t.Errorf("balanceOf(%s) = %s, want an unreadable balance", asset, balance)
+		}
+	}
+
+	client := x402.Newx402Client(x402.WithPolicy(scheme.SelectionPolicy(context.Background())))
+	client.Register("multiversx:D", scheme)
+	selected, err := client.SelectPaymentRequirements([]types.PaymentRequirements{egld, usdc})
+	if err != nil {
+		t.Fatalf("SelectPaymentRequirements() error = %v", err)
+	}
+	if selected.Asset != multiversx.NativeTokenTicker {
+		t.Errorf("selected %s, want %s", selected.Asset, multiversx.NativeTokenTicker)
+	}
+}
@@ -0,0 +1,97 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/multiversx/mx-chain-core-go/data/transaction"
+)
+
+// simulationTimeout bounds the dry-run request sent by CreatePaymentPayload
+const simulationTimeout = 30 * time.Second
+
+// ErrSimulationFailed is returned by CreatePaymentPayload when the dry-run of the signed transaction fails
+var ErrSimulationFailed = errors.New("payment simulation failed")
+
+// WithClientSimulation makes CreatePaymentPayload dry-run the signed transaction on the gateway
+// /transaction/simulate endpoint and fail if it would not execute (insufficient balance, gas too
+// low, contract rejection). It adds a round trip to every payment, so it is off by default.
+func WithClientSimulation() Option {
+	return func(s *ExactMultiversXScheme) {
+		s.simulate = true
+	}
+}
+
+// WithAPIURL overrides the gateway used for simulations and by the default proxy
+// Defaults to the public gateway of the network.
+func WithAPIURL(url string) Option {
+	return func(s *ExactMultiversXScheme) {
+		s.apiURL = url
+	}
+}
+
+// WithAPIURLOverride replaces the public gateway of chainID, e.g. with a private observer or a paid gateway
+// It applies when the scheme network is chainID; an explicit WithAPIURL takes precedence.
+func WithAPIURLOverride(chainID string, url string) Option {
+	return func(s *ExactMultiversXScheme) {
+		if s.apiOverrides == nil {
+			s.apiOverrides = make(map[string]string)
+		}
+		s.apiOverrides[chainID] = url
+	}
+}
+
+// simulateTransaction dry-runs the signed transaction and returns an ErrSimulationFailed error if it would fail
+// Relayed transactions are not signed by the relayer yet, so their signatures are not checked.
+func (s *ExactMultiversXScheme) simulateTransaction(ctx context.Context, tx *transaction.FrontendTransaction) error {
+	txBytes, err := json.Marshal(tx)
+	if err != nil {
+		return err
+	}
+
+	url := s.apiURL + "/transaction/simulate"
+	if tx.RelayerAddr != "" {
+		url += "?checkSignature=false"
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(txBytes))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{Timeout: simulationTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to simulate payment: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var res struct {
+		Data struct {
+			Result struct {
+				Status     string `json:"status"`
+				FailReason string `json:"failReason"`
+			} `json:"result"`
+		} `json:"data"`
+		Error string `json:"error"`
+		Code  string `json:"code"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return fmt.Errorf("failed to decode simulation response (status %d): %w", resp.StatusCode, err)
+	}
+
+	switch {
+	case res.Error != "":
+		return fmt.Errorf("%w: %s", ErrSimulationFailed, res.Error)
+	case res.Data.Result.FailReason != "":
+		return fmt.Errorf("%w: %s", ErrSimulationFailed, res.Data.Result.FailReason)
+	case res.Code == "successful", res.Data.Result.Status == "success", res.Data.Result.Status == "successful":
+		return nil
+	}
+	return fmt.Errorf("%w: status %q (code: %s)", ErrSimulationFailed, res.Data.Result.Status, res.Code)
+}
@@ -0,0 +1,102 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/multiversx/mx-chain-core-go/data/transaction"
+
+	"github.com/coinbase/x402/go/types"
+)
+
+func TestCreatePaymentPayload_ClientSimulation(t *testing.T) {
+	var simulated []transaction.FrontendTransaction
+	var queries []string
+	response := `{"data":{"result":{"status":"fail","failReason":"insufficient funds"}},"error":"","code":"successful"}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/transaction/simulate" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var tx transaction.FrontendTransaction
+		json.NewDecoder(r.Body).Decode(&tx)
+		simulated = append(simulated, tx)
+		queries = append(queries, r.URL.RawQuery)
+		w.Write([]byte(response))
+	}))
+	defer server.Close()
+
+	req := types.PaymentRequirements{
+		PayTo:  testPayTo,
+		Amount: "100",
+		Asset:  "EGLD",
+		Extra:  map[string]interface{}{"relayer": testSender},
+	}
+
+	scheme, _ := NewExactMultiversXScheme(&MockSigner{addr: testSender}, "multiversx:D",
+		WithProxy(&MockProxy{nonce: 3}), WithAPIURL(server.URL), WithClientSimulation())
+
+	_, err := scheme.CreatePaymentPayload(context.Background(), req)
+	if !errors.Is(err, ErrSimulationFailed) || !strings.Contains(err.Error(), "insufficient funds") {
+		t.Fatalf("CreatePaymentPayload() error = %v, want simulation failure", err)
+	}
+	if len(simulated) != 1 || simulated[0].Signature == "" || simulated[0].Nonce != 3 {
+		t.Fatalf("simulated = %+v, want the signed transaction", simulated)
+	}
+	// The relayer has not signed yet, so signatures are not checked
+	if queries[0] != "checkSignature=false" {
+		t.Errorf("query = %q, want checkSignature=false", queries[0])
+	}
+
+	response = `{"data":{"result":{"status":"success","hash":"sim_hash"}},"error":"","code":"successful"}`
+	if _, err := scheme.CreatePaymentPayload(context.Background(), req); err != nil {
+		t.Errorf("CreatePaymentPayload() error = %v", err)
+	}
+
+	// Off by default
+	plain, _ := NewExactMultiversXScheme(&MockSigner{addr: testSender}, "multiversx:D",
+		WithProxy(&MockProxy{nonce: 3}), WithAPIURL(server.URL))
+	if _, err := plain.CreatePaymentPayload(context.Background(), req); err != nil {
+		t.Fatalf("CreatePaymentPayload() error = %v", err)
+	}
+	if len(simulated) != 2 {
+		t.Errorf("simulations = %d, want none without WithClientSimulation", len(simulated)-2)
+	}
+}
+
+func TestWithAPIURLOverride(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.Write([]byte(`{"data":{"config":{"erd_chain_id":"D","erd_min_gas_price":1000000000}},"code":"successful"}`))
+	}))
+	defer server.Close()
+
+	scheme, err := NewExactMultiversXScheme(&MockSigner{addr: testSender}, "multiversx:D",
+		WithAPIURLOverride("D", server.URL), WithAPIURLOverride("1", "https://gateway.example.com"))
+	if err != nil {
+		t.Fatalf("NewExactMultiversXScheme() error = %v", err)
+	}
+	if scheme.apiURL != server.URL {
+		t.Errorf("apiURL = %s, want the devnet override %s", scheme.apiURL, server.URL)
+	}
+
+	config, err := scheme.proxy.GetNetworkConfig(context.Background())
+	if err != nil {
+		t.Fatalf("GetNetworkConfig() error = %v", err)
+	}
+	if config.ChainID != "D" || len(paths) != 1 || paths[0] != "/network/config" {
+		t.Errorf("Expected the proxy to query the override, got config %+v and paths %v", config, paths)
+	}
+
+	// Overrides of other chains do not apply
+	testnet, _ := NewExactMultiversXScheme(&MockSigner{addr: testSender}, "multiversx:T", WithAPIURLOverride("D", server.URL))
+	if testnet.apiURL != "https://testnet-api.multiversx.com" {
+		t.Errorf("apiURL = %s, want the public testnet API", testnet.apiURL)
+	}
+}
@@ -0,0 +1,58 @@
+package facilitator
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/multiversx/mx-sdk-go/data"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/mechanisms/multiversx"
+)
+
+// WithBalanceCheck makes Verify check the sender EGLD balance before simulating the payment
+// The sender must hold the transferred EGLD value plus, unless a relayer pays it, the maximum
+// transaction fee. ESDT balances are left to the simulation. Insufficient balances are reported
+// as insufficient_balance with the shortfall instead of an opaque simulation error.
+func WithBalanceCheck() Option {
+	return func(s *ExactMultiversXScheme) {
+		s.balanceCheck = true
+	}
+}
+
+// checkBalance verifies the sender can afford the EGLD the payload spends
+// Account lookup failures are not reported: the simulation still catches an insufficient balance.
+func (s *ExactMultiversXScheme) checkBalance(ctx context.Context, payload multiversx.ExactRelayedPayload) error {
+	if !s.balanceCheck {
+		return nil
+	}
+
+	needed, ok := new(big.Int).SetString(payload.Value, 10)
+	if !ok {
+		return nil
+	}
+	if payload.Relayer == "" {
+		needed.Add(needed, multiversx.MaxTransactionFee(payload))
+	}
+	if needed.Sign() == 0 {
+		return nil
+	}
+
+	addr, err := data.NewAddressFromBech32String(payload.Sender)
+	if err != nil {
+		return nil
+	}
+	account, err := s.proxy.GetAccount(ctx, addr)
+	if err != nil || account == nil {
+		return nil
+	}
+	balance, ok := new(big.Int).SetString(account.Balance, 10)
+	if !ok {
+		balance = new(big.Int)
+	}
+
+	if balance.Cmp(needed) < 0 {
+		return x402.NewVerifyError(multiversx.ErrCodeInsufficientBalance, payload.Sender, "multiversx", &multiversx.InsufficientBalanceError{Balance: balance, Needed: needed})
+	}
+	return nil
+}
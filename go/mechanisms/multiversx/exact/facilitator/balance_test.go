@@ -0,0 +1,102 @@
+package facilitator
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/multiversx/mx-sdk-go/data"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/mechanisms/multiversx"
+	"github.com/coinbase/x402/go/types"
+)
+
+func TestVerify_BalanceCheck(t *testing.T) {
+	var simulations int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		simulations++
+		w.Write([]byte(`{"data":{"result":{"status":"success","hash":"sim_hash"}},"error":""}`))
+	}))
+	defer server.Close()
+
+	pubKey, privKey, _ := ed25519.GenerateKey(nil)
+	senderAddr, _ := data.NewAddressFromBytes(pubKey).AddressAsBech32String()
+
+	// Needs 1000 of value plus a maximum fee of 50000 * 10^9 = 5 * 10^13
+	payload := multiversx.ExactRelayedPayload{
+		Nonce:    1,
+		Value:    "1000",
+		Receiver: senderAddr,
+		Sender:   senderAddr,
+		GasPrice: 1000000000,
+		GasLimit: 50000,
+		ChainID:  "D",
+		Version:  1,
+	}
+	tx := payload.ToTransaction()
+	txBytes, _ := multiversx.SerializeTransaction(&tx)
+	payload.Signature = hex.EncodeToString(ed25519.Sign(privKey, txBytes))
+
+	req := types.PaymentRequirements{
+		PayTo:  senderAddr,
+		Amount: "1000",
+		Asset:  multiversx.NativeTokenTicker,
+		Extra:  map[string]interface{}{"assetTransferMethod": multiversx.TransferMethodDirect},
+	}
+
+	scheme, _ := NewExactMultiversXScheme(server.URL, &MockSigner{}, WithBalanceCheck())
+	mockProxy := &MockProxy{account: &data.Account{Balance: "50000000000999"}}
+	scheme.proxy = mockProxy
+
+	_, err := scheme.Verify(context.Background(), types.PaymentPayload{Payload: payload.ToMap()}, req)
+	var vErr *x402.VerifyError
+	if !errors.As(err, &vErr) || vErr.Reason != multiversx.ErrCodeInsufficientBalance {
+		t.Fatalf("Verify() error = %v, want %s", err, multiversx.ErrCodeInsufficientBalance)
+	}
+	if !strings.Contains(err.Error(), "1 short") {
+		t.Errorf("Expected the shortfall in the error, got %v", err)
+	}
+	if simulations != 0 {
+		t.Errorf("simulations = %d, want none after a failed balance check", simulations)
+	}
+
+	mockProxy.account.Balance = "50000000001000"
+	if _, err := scheme.Verify(context.Background(), types.PaymentPayload{Payload: payload.ToMap()}, req); err != nil {
+		t.Errorf("Verify() with enough balance error = %v", err)
+	}
+}
+
+func TestCheckBalance(t *testing.T) {
+	sender := "erd1qyu5wthldzr8wx5c9ucg8kjagg0jfs53s8nr3zpz3hypefsdd8ssycr6th"
+	esdt := multiversx.ExactRelayedPayload{Sender: sender, Value: "0", GasPrice: 1000000000, GasLimit: 500000}
+
+	tests := []struct {
+		name    string
+		check   bool
+		payload multiversx.ExactRelayedPayload
+		account *data.Account
+		wantErr bool
+	}{
+		{"ESDT Sender Pays Fee", true, esdt, &data.Account{Balance: "1"}, true},
+		{"ESDT Fee Covered", true, esdt, &data.Account{Balance: "500000000000000"}, false},
+		{"Relayed ESDT", true, func() multiversx.ExactRelayedPayload { p := esdt; p.Relayer = sender; return p }(), &data.Account{Balance: "0"}, false},
+		{"Account Unavailable", true, esdt, nil, false},
+		{"Disabled", false, esdt, &data.Account{Balance: "0"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := &ExactMultiversXScheme{proxy: &MockProxy{account: tt.account}, balanceCheck: tt.check}
+			err := scheme.checkBalance(context.Background(), tt.payload)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkBalance() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
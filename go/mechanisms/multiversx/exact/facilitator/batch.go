@@ -0,0 +1,64 @@
+package facilitator
+
+import (
+	"context"
+	"runtime"
+	"sync"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/types"
+)
+
+// BatchVerifyItem pairs a payment payload with the requirements it must satisfy
+type BatchVerifyItem struct {
+	Payload      types.PaymentPayload
+	Requirements types.PaymentRequirements
+}
+
+// VerifyResult is the outcome of verifying a single batch item
+type VerifyResult struct {
+	Response *x402.VerifyResponse
+	Err      error
+}
+
+// WithBatchConcurrency bounds how many payloads VerifyBatch verifies (and simulates) at once
+// Non-positive values use runtime.NumCPU().
+func WithBatchConcurrency(n int) Option {
+	return func(s *ExactMultiversXScheme) {
+		s.batchConcurrency = n
+	}
+}
+
+// VerifyBatch verifies the items on a bounded pool of workers
+// Results are returned in the order of the items; a failed item does not stop the others.
+func (s *ExactMultiversXScheme) VerifyBatch(ctx context.Context, items []BatchVerifyItem) []VerifyResult {
+	results := make([]VerifyResult, len(items))
+
+	concurrency := s.batchConcurrency
+	if concurrency <= 0 {
+		concurrency = runtime.NumCPU()
+	}
+	slots := make(chan struct{}, concurrency)
+
+	var wg sync.WaitGroup
+	for i, item := range items {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			results[i] = VerifyResult{Err: ctx.Err()}
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, item BatchVerifyItem) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			resp, err := s.Verify(ctx, item.Payload, item.Requirements)
+			results[i] = VerifyResult{Response: resp, Err: err}
+		}(i, item)
+	}
+	wg.Wait()
+
+	return results
+}
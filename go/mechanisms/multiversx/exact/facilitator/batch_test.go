@@ -0,0 +1,156 @@
+package facilitator
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/multiversx/mx-sdk-go/data"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/mechanisms/multiversx"
+	"github.com/coinbase/x402/go/types"
+)
+
+func TestVerifyBatch_BoundsConcurrency(t *testing.T) {
+	const concurrency = 2
+	const total = 8
+
+	var inFlight, maxInFlight int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			observed := atomic.LoadInt32(&maxInFlight)
+			if current <= observed || atomic.CompareAndSwapInt32(&maxInFlight, observed, current) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte(`{"data":{"result":{"status":"success","hash":"sim_hash"}},"error":""}`))
+	}))
+	defer server.Close()
+
+	scheme, _ := NewExactMultiversXScheme(server.URL, &MockSigner{}, WithBatchConcurrency(concurrency))
+
+	pubKey, privKey, _ := ed25519.GenerateKey(nil)
+	senderAddr, _ := data.NewAddressFromBytes(pubKey).AddressAsBech32String()
+	req := types.PaymentRequirements{
+		PayTo:  senderAddr,
+		Amount: "1000",
+		Asset:  multiversx.NativeTokenTicker,
+		Extra: map[string]interface{}{
+			"assetTransferMethod": multiversx.TransferMethodDirect,
+		},
+	}
+
+	items := make([]BatchVerifyItem, total)
+	for i := range items {
+		payload := multiversx.ExactRelayedPayload{
+			Nonce:    uint64(i),
+			Value:    "1000",
+			Receiver: senderAddr,
+			Sender:   senderAddr,
+			GasPrice: 1000000000,
+			GasLimit: 50000,
+			ChainID:  "D",
+			Version:  1,
+		}
+		tx := payload.ToTransaction()
+		txBytes, _ := multiversx.SerializeTransaction(&tx)
+		payload.Signature = hex.EncodeToString(ed25519.Sign(privKey, txBytes))
+		items[i] = BatchVerifyItem{Payload: types.PaymentPayload{Payload: payload.ToMap()}, Requirements: req}
+	}
+	// An invalid item fails on its own without affecting the rest of the batch
+	items[3].Requirements.Amount = "2000"
+
+	results := scheme.VerifyBatch(context.Background(), items)
+	if len(results) != total {
+		t.Fatalf("Expected %d results, got %d", total, len(results))
+	}
+	for i, result := range results {
+		if i == 3 {
+			if result.Err == nil {
+				t.Error("Expected item 3 to fail verification")
+			}
+			continue
+		}
+		if result.Err != nil || !result.Response.IsValid {
+			t.Errorf("item %d: result = %+v", i, result)
+		}
+	}
+
+	if maxInFlight > concurrency {
+		t.Errorf("max in-flight simulations = %d, want <= %d", maxInFlight, concurrency)
+	}
+	if maxInFlight < concurrency {
+		t.Errorf("max in-flight simulations = %d, expected the pool to be used", maxInFlight)
+	}
+}
+
+func TestVerifyBatch_PerItemOutcomes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":{"result":{"status":"success","hash":"sim_hash"}},"error":""}`))
+	}))
+	defer server.Close()
+	scheme, _ := NewExactMultiversXScheme(server.URL, &MockSigner{}, WithBatchConcurrency(3))
+
+	pubKey, privKey, _ := ed25519.GenerateKey(nil)
+	senderAddr, _ := data.NewAddressFromBytes(pubKey).AddressAsBech32String()
+	req := types.PaymentRequirements{
+		PayTo:  senderAddr,
+		Amount: "1000",
+		Asset:  multiversx.NativeTokenTicker,
+		Extra:  map[string]interface{}{"assetTransferMethod": multiversx.TransferMethodDirect},
+	}
+	signed := func(nonce uint64, mutate func(p *multiversx.ExactRelayedPayload)) BatchVerifyItem {
+		payload := multiversx.ExactRelayedPayload{
+			Nonce:    nonce,
+			Value:    "1000",
+			Receiver: senderAddr,
+			Sender:   senderAddr,
+			GasPrice: 1000000000,
+			GasLimit: 50000,
+			ChainID:  "D",
+			Version:  1,
+		}
+		msgBytes, _ := multiversx.SigningBytes(payload)
+		payload.Signature = hex.EncodeToString(ed25519.Sign(privKey, msgBytes))
+		if mutate != nil {
+			mutate(&payload)
+		}
+		return BatchVerifyItem{Payload: types.PaymentPayload{Payload: payload.ToMap()}, Requirements: req}
+	}
+
+	items := []BatchVerifyItem{
+		signed(1, nil),
+		signed(2, func(p *multiversx.ExactRelayedPayload) { p.Value = "999" }),
+		signed(3, nil),
+		signed(4, func(p *multiversx.ExactRelayedPayload) { p.Receiver = "erd1invalid" }),
+		signed(5, func(p *multiversx.ExactRelayedPayload) { p.Value = "1.5" }),
+	}
+	want := []string{"", x402.ErrCodeSignatureInvalid, "", multiversx.ErrCodeInvalidAddress, multiversx.ErrCodeInvalidValueFormat}
+
+	results := scheme.VerifyBatch(context.Background(), items)
+	if len(results) != len(items) {
+		t.Fatalf("Expected %d results, got %d", len(items), len(results))
+	}
+	for i, result := range results {
+		if want[i] == "" {
+			if result.Err != nil || result.Response == nil || !result.Response.IsValid {
+				t.Errorf("item %d: result = %+v, want valid", i, result)
+			}
+			continue
+		}
+		var vErr *x402.VerifyError
+		if !errors.As(result.Err, &vErr) || vErr.Reason != want[i] {
+			t.Errorf("item %d: error = %v, want %s", i, result.Err, want[i])
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package facilitator
+
+import (
+	"time"
+)
+
+// SettleEventType is the stage of a settlement reported to settle hooks
+type SettleEventType string
+
+const (
+	// SettleEventBroadcast is emitted once the transaction was accepted by the gateway
+	SettleEventBroadcast SettleEventType = "broadcast"
+	// SettleEventPending is emitted for each poll finding the transaction not final yet
+	SettleEventPending SettleEventType = "pending"
+	// SettleEventConfirmed is emitted when the transaction executed successfully
+	SettleEventConfirmed SettleEventType = "confirmed"
+	// SettleEventFailed is emitted when the transaction failed or its contract call was rejected
+	SettleEventFailed SettleEventType = "failed"
+	// SettleEventTimedOut is emitted when Settle stops waiting before the transaction is final
+	SettleEventTimedOut SettleEventType = "timed_out"
+)
+
+// SettleEvent describes a step of a settlement
+type SettleEvent struct {
+	Type   SettleEventType
+	Hash   string
+	Sender string
+	// Status is the raw status reported by the gateway, empty for broadcast and timeout events
+	Status string
+	// Time is when the event occurred and Elapsed how long after the broadcast
+	Time    time.Time
+	Elapsed time.Duration
+	// Err is the contract rejection of a failed event or the cause of a timeout
+	Err error
+}
+
+// SettleHook receives the events of every settlement
+// Hooks run synchronously on the settling goroutine and must return quickly.
+type SettleHook func(event SettleEvent)
+
+// WithSettleHook registers a hook receiving the broadcast and status events of every settlement
+// With WithAsyncSettle only the broadcast event is emitted.
+func WithSettleHook(hook SettleHook) Option {
+	return func(s *ExactMultiversXScheme) {
+		s.settleHooks = append(s.settleHooks, hook)
+	}
+}
+
+// statusObserver receives each transaction status waitForTx classifies
+type statusObserver func(outcome SettlementStatus, status string, err error)
+
+// observe reports the status to the observer, if any
+func (o statusObserver) observe(outcome SettlementStatus, status string, err error) {
+	if o != nil {
+		o(outcome, status, err)
+	}
+}
+
+// settleTracker emits the events of a single settlement to the settle hooks
+type settleTracker struct {
+	scheme      *ExactMultiversXScheme
+	hash        string
+	sender      string
+	broadcastAt time.Time
+	// final is set once a confirmed or failed event was emitted
+	final bool
+}
+
+// trackSettlement emits the broadcast event of a settlement and returns its tracker
+func (s *ExactMultiversXScheme) trackSettlement(hash string, sender string) *settleTracker {
+	t := &settleTracker{scheme: s, hash: hash, sender: sender, broadcastAt: s.now()}
+	t.emit(SettleEventBroadcast, "", nil)
+	return t
+}
+
+// observer returns the status observer passed to waitForTx
+func (t *settleTracker) observer() statusObserver {
+	if len(t.scheme.settleHooks) == 0 {
+		return nil
+	}
+	return func(outcome SettlementStatus, status string, err error) {
+		switch outcome {
+		case SettlementStatusSuccess:
+			t.final = true
+			t.emit(SettleEventConfirmed, status, nil)
+		case SettlementStatusFailed:
+			t.final = true
+			t.emit(SettleEventFailed, status, err)
+		default:
+			t.emit(SettleEventPending, status, nil)
+		}
+	}
+}
+
+// stopped emits a timeout event when waiting ended without a final status
+func (t *settleTracker) stopped(err error) {
+	if !t.final {
+		t.emit(SettleEventTimedOut, "", err)
+	}
+}
+
+// emit sends the event to every settle hook
+func (t *settleTracker) emit(eventType SettleEventType, status string, err error) {
+	if len(t.scheme.settleHooks) == 0 {
+		return
+	}
+	now := t.scheme.now()
+	event := SettleEvent{
+		Type:    eventType,
+		Hash:    t.hash,
+		Sender:  t.sender,
+		Status:  status,
+		Time:    now,
+		Elapsed: now.Sub(t.broadcastAt),
+		Err:     err,
+	}
+	for _, hook := range t.scheme.settleHooks {
+		hook(event)
+	}
+}
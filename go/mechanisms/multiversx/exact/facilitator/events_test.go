@@ -0,0 +1,79 @@
+package facilitator
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/multiversx/mx-chain-core-go/data/transaction"
+
+	"github.com/coinbase/x402/go/mechanisms/multiversx"
+	"github.com/coinbase/x402/go/types"
+)
+
+func TestWithSettleHook(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []transaction.TxStatus
+		async    bool
+		want     []SettleEventType
+	}{
+		{
+			name:     "Confirmed",
+			statuses: []transaction.TxStatus{transaction.TxStatusPending, transaction.TxStatusSuccess},
+			want:     []SettleEventType{SettleEventBroadcast, SettleEventPending, SettleEventConfirmed},
+		},
+		{
+			name:     "Failed",
+			statuses: []transaction.TxStatus{transaction.TxStatusFail},
+			want:     []SettleEventType{SettleEventBroadcast, SettleEventFailed},
+		},
+		{
+			name:  "Async",
+			async: true,
+			want:  []SettleEventType{SettleEventBroadcast},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Unix(1700000000, 0)
+			clock := start
+			scheme := &ExactMultiversXScheme{
+				proxy:       &MockProxy{sendHash: "tx_hash", statusResponses: tt.statuses},
+				asyncSettle: tt.async,
+				clock:       func() time.Time { return clock },
+				sleep: func(ctx context.Context, d time.Duration) error {
+					clock = clock.Add(time.Second)
+					return nil
+				},
+			}
+			var events []SettleEvent
+			WithSettleHook(func(event SettleEvent) { events = append(events, event) })(scheme)
+
+			payload := multiversx.ExactRelayedPayload{Sender: "erd1sender", Nonce: 1, ChainID: "D"}
+			requirements := types.PaymentRequirements{Extra: map[string]interface{}{"assetTransferMethod": multiversx.TransferMethodDirect}}
+			_, _ = scheme.Settle(context.Background(), types.PaymentPayload{Payload: payload.ToMap()}, requirements)
+
+			var got []SettleEventType
+			for _, event := range events {
+				got = append(got, event.Type)
+				if event.Hash != "tx_hash" || event.Sender != "erd1sender" {
+					t.Errorf("event %s = %+v, want hash tx_hash from erd1sender", event.Type, event)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("events = %v, want %v", got, tt.want)
+			}
+
+			last := events[len(events)-1]
+			if want := time.Duration(len(tt.statuses)) * time.Second; last.Elapsed != want {
+				t.Errorf("last event elapsed = %s, want %s", last.Elapsed, want)
+			}
+			if len(tt.statuses) > 0 && last.Status != string(tt.statuses[len(tt.statuses)-1]) {
+				t.Errorf("last event status = %q, want %q", last.Status, tt.statuses[len(tt.statuses)-1])
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package facilitator
+
+import (
+	"github.com/coinbase/x402/go/mechanisms/multiversx"
+)
+
+// ExplorerURLExtraKey is the SettleResponse Extra key holding the explorer link of the settlement transaction
+const ExplorerURLExtraKey = "explorerUrl"
+
+// WithExplorerURL sets the explorer linked from settlement responses
+// Defaults to the public explorer of the payload chain (explorer.multiversx.com, devnet-explorer, testnet-explorer).
+func WithExplorerURL(url string) Option {
+	return func(s *ExactMultiversXScheme) {
+		s.config.ExplorerUrl = url
+	}
+}
+
+// explorerURL returns the explorer link of a settlement transaction sent on chainID
+func (s *ExactMultiversXScheme) explorerURL(chainID string, hash string) string {
+	base := s.config.ExplorerUrl
+	if base == "" {
+		base = multiversx.GetExplorerURL(chainID)
+	}
+	return multiversx.ExplorerTransactionURL(base, hash)
+}
@@ -0,0 +1,47 @@
+package facilitator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/multiversx/mx-chain-core-go/data/transaction"
+
+	"github.com/coinbase/x402/go/mechanisms/multiversx"
+	"github.com/coinbase/x402/go/types"
+)
+
+func TestSettle_ExplorerURL(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+		want string
+	}{
+		{"devnet default", nil, "https://devnet-explorer.multiversx.com/transactions/tx_hash_explorer"},
+		{"override", []Option{WithExplorerURL("https://explorer.example.com/")}, "https://explorer.example.com/transactions/tx_hash_explorer"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := &ExactMultiversXScheme{proxy: &MockProxy{
+				sendHash:        "tx_hash_explorer",
+				statusResponses: []transaction.TxStatus{transaction.TxStatusSuccess},
+			}}
+			for _, opt := range tt.opts {
+				opt(scheme)
+			}
+			scheme.sleep = func(ctx context.Context, d time.Duration) error { return nil }
+
+			payload := multiversx.ExactRelayedPayload{ChainID: multiversx.ChainIDDevnet}
+			resp, err := scheme.Settle(context.Background(), types.PaymentPayload{Payload: payload.ToMap()}, types.PaymentRequirements{
+				Extra: map[string]interface{}{"assetTransferMethod": multiversx.TransferMethodDirect},
+			})
+			if err != nil {
+				t.Fatalf("Settle() error = %v", err)
+			}
+			if got := resp.Extra[ExplorerURLExtraKey]; got != tt.want {
+				t.Errorf("explorer URL = %v, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package facilitator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/mechanisms/multiversx"
+	"github.com/coinbase/x402/go/types"
+)
+
+// WithFeeCoverage requires relayed token payments to cover the relayer fee on top of the price
+// The maximum fee of the payload (gasLimit * gasPrice) is converted into the payment asset and the
+// transferred amount must be at least the required amount plus that fee. EGLD and direct payments
+// are not affected: their sender pays the gas.
+func WithFeeCoverage(converter multiversx.FeeConverter) Option {
+	return func(s *ExactMultiversXScheme) {
+		s.feeConverter = converter
+	}
+}
+
+// checkFeeCoverage verifies a relayed token payment also covers the relayer fee
+func (s *ExactMultiversXScheme) checkFeeCoverage(ctx context.Context, payload multiversx.ExactRelayedPayload, requirements types.PaymentRequirements) error {
+	if s.feeConverter == nil || payload.Relayer == "" || requirements.Asset == multiversx.NativeTokenTicker {
+		return nil
+	}
+
+	fee, err := s.feeConverter(multiversx.MaxTransactionFee(payload), requirements.Asset, x402.Network(requirements.Network))
+	if err != nil {
+		return x402.NewVerifyError(multiversx.ErrCodeFeeNotCovered, payload.Sender, "multiversx", fmt.Errorf("failed to convert relayer fee to %s: %w", requirements.Asset, err))
+	}
+
+	required, err := requiredAssetAmount(requirements)
+	if err != nil {
+		return x402.NewVerifyError(multiversx.ErrCodeInvalidRequirements, payload.Sender, "multiversx", err)
+	}
+	required.Add(required, fee)
+
+	transfer, err := multiversx.DecodeMultiESDTTransfer(payload.Data)
+	if err != nil {
+		return x402.NewVerifyError(multiversx.ErrCodeInvalidTransferData, payload.Sender, "multiversx", err)
+	}
+	paid := new(big.Int)
+	for _, t := range transfer.Transfers {
+		if t.Asset == requirements.Asset {
+			amount, _ := new(big.Int).SetString(t.Amount, 10)
+			paid.Add(paid, amount)
+		}
+	}
+
+	if paid.Cmp(required) < 0 {
+		return x402.NewVerifyError(multiversx.ErrCodeFeeNotCovered, payload.Sender, "multiversx", fmt.Errorf("paid %s %s, need %s including a relayer fee of %s", paid, requirements.Asset, required, fee))
+	}
+	return nil
+}
+
+// requiredAssetAmount sums the amounts of the requirement asset the payment must transfer
+func requiredAssetAmount(requirements types.PaymentRequirements) (*big.Int, error) {
+	transfers, err := multiversx.RequiredTransfers(requirements)
+	if err != nil {
+		return nil, err
+	}
+
+	total := new(big.Int)
+	for _, t := range transfers {
+		if t.Asset != requirements.Asset {
+			continue
+		}
+		amount, ok := new(big.Int).SetString(t.Amount, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid amount %q", t.Amount)
+		}
+		total.Add(total, amount)
+	}
+	if total.Sign() == 0 {
+		return nil, errors.New("requirements transfer none of the asset")
+	}
+	return total, nil
+}
@@ -0,0 +1,85 @@
+package facilitator
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/multiversx/mx-sdk-go/data"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/mechanisms/multiversx"
+	"github.com/coinbase/x402/go/types"
+)
+
+func TestVerify_FeeCoverage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":{"result":{"status":"success","hash":"sim_hash"}},"error":""}`))
+	}))
+	defer server.Close()
+
+	// 1 USDC unit per 10^12 wei of fee
+	converter := func(feeEGLD *big.Int, asset string, network x402.Network) (*big.Int, error) {
+		return new(big.Int).Div(feeEGLD, big.NewInt(1_000_000_000_000)), nil
+	}
+	scheme, _ := NewExactMultiversXScheme(server.URL, &MockSigner{}, WithFeeCoverage(converter))
+
+	pubKey, privKey, _ := ed25519.GenerateKey(nil)
+	senderAddr, _ := data.NewAddressFromBytes(pubKey).AddressAsBech32String()
+	payTo := "erd1spyavw0956vq68xj8y4tenjpq2wd5a9p2c6j8gsz7ztyrnpxrruqzu66jx"
+	relayer := "erd1qyu5wthldzr8wx5c9ucg8kjagg0jfs53s8nr3zpz3hypefsdd8ssycr6th"
+
+	req := types.PaymentRequirements{
+		PayTo:   payTo,
+		Amount:  "1000",
+		Asset:   "USDC-c76f1f",
+		Network: "multiversx:D",
+		Extra:   map[string]interface{}{"relayer": relayer},
+	}
+
+	// gasLimit * gasPrice = 5 * 10^14 wei, converted to a fee of 500 USDC units
+	signedPayload := func(amount int64) types.PaymentPayload {
+		handler, _ := multiversx.GetTransferMethodHandler(multiversx.TransferMethodESDT)
+		priced := req
+		priced.Amount = big.NewInt(amount).String()
+		fields, _ := handler.Encode(priced, senderAddr)
+
+		payload := multiversx.ExactRelayedPayload{
+			Nonce:    1,
+			Value:    fields.Value,
+			Receiver: fields.Receiver,
+			Sender:   senderAddr,
+			Relayer:  relayer,
+			GasPrice: 1_000_000_000,
+			GasLimit: 500_000,
+			Data:     fields.Data,
+			ChainID:  "D",
+			Version:  2,
+		}
+		tx := payload.ToTransaction()
+		txBytes, _ := multiversx.SerializeTransaction(&tx)
+		payload.Signature = hex.EncodeToString(ed25519.Sign(privKey, txBytes))
+		return types.PaymentPayload{Payload: payload.ToMap()}
+	}
+
+	_, err := scheme.Verify(context.Background(), signedPayload(1400), req)
+	var vErr *x402.VerifyError
+	if !errors.As(err, &vErr) || vErr.Reason != multiversx.ErrCodeFeeNotCovered {
+		t.Fatalf("Verify() error = %v, want %s", err, multiversx.ErrCodeFeeNotCovered)
+	}
+
+	if _, err := scheme.Verify(context.Background(), signedPayload(1500), req); err != nil {
+		t.Errorf("Verify() with the fee covered error = %v", err)
+	}
+
+	// Without WithFeeCoverage the base price is enough
+	plain, _ := NewExactMultiversXScheme(server.URL, &MockSigner{})
+	if _, err := plain.Verify(context.Background(), signedPayload(1000), req); err != nil {
+		t.Errorf("Verify() without fee coverage error = %v", err)
+	}
+}
@@ -0,0 +1,65 @@
+package facilitator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/mechanisms/multiversx"
+	"github.com/coinbase/x402/go/types"
+)
+
+// EstimateGas returns the gas limit the payload transaction needs, as computed by the /transaction/cost
+// endpoint, so that clients can rebuild a smart contract call payment with the right gas limit. The
+// payload does not need a valid signature. Calls the contract would reject fail with contract_rejected.
+func (s *ExactMultiversXScheme) EstimateGas(ctx context.Context, payload types.PaymentPayload, requirements types.PaymentRequirements) (uint64, error) {
+	relayedPayload, err := multiversx.PayloadFromMap(payload.Payload)
+	if err != nil {
+		return 0, x402.NewVerifyError(x402.ErrCodeInvalidPayment, "", "multiversx", fmt.Errorf("invalid payload format: %v", err))
+	}
+	if requirements.Network != "" {
+		chainID, err := multiversx.GetMultiversXChainId(requirements.Network)
+		if err != nil {
+			return 0, x402.NewVerifyError(multiversx.ErrCodeInvalidRequirements, relayedPayload.Sender, "multiversx", err)
+		}
+		if chainID != relayedPayload.ChainID {
+			return 0, x402.NewVerifyError(x402.ErrCodeInvalidPayment, relayedPayload.Sender, "multiversx", fmt.Errorf("payload chain ID %q does not match network %s", relayedPayload.ChainID, requirements.Network))
+		}
+	}
+
+	tx := relayedPayload.ToTransaction()
+	txBytes, err := json.Marshal(tx)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := s.postSimulation(ctx, tx.ChainID, "cost", txBytes)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	var res struct {
+		Data struct {
+			TxGasUnits    uint64 `json:"txGasUnits"`
+			ReturnMessage string `json:"returnMessage"`
+		} `json:"data"`
+		Error string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return 0, fmt.Errorf("failed to decode cost response: %w", err)
+	}
+	if res.Error != "" {
+		return 0, errors.New(res.Error)
+	}
+	// The cost endpoint reports a failed contract execution through the return message
+	if res.Data.ReturnMessage != "" {
+		return 0, x402.NewVerifyError(multiversx.ErrCodeContractRejected, relayedPayload.Sender, "multiversx", &multiversx.ContractRejectedError{Message: res.Data.ReturnMessage})
+	}
+	if res.Data.TxGasUnits == 0 {
+		return 0, errors.New("cost endpoint returned no gas estimate")
+	}
+	return res.Data.TxGasUnits, nil
+}
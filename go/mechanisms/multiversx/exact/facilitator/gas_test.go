@@ -0,0 +1,88 @@
+package facilitator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/multiversx/mx-chain-core-go/data/transaction"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/mechanisms/multiversx"
+	"github.com/coinbase/x402/go/types"
+)
+
+func TestEstimateGas(t *testing.T) {
+	tests := []struct {
+		name       string
+		response   string
+		network    string
+		want       uint64
+		wantReason string
+	}{
+		{
+			name:     "Estimate",
+			response: `{"data":{"txGasUnits":4218713,"returnMessage":""},"error":"","code":"successful"}`,
+			network:  "multiversx:D",
+			want:     4218713,
+		},
+		{
+			name:       "ContractRejected",
+			response:   `{"data":{"txGasUnits":0,"returnMessage":"invalid function (not found)"},"error":"","code":"successful"}`,
+			wantReason: multiversx.ErrCodeContractRejected,
+		},
+		{
+			name:       "NetworkMismatch",
+			network:    "multiversx:1",
+			wantReason: x402.ErrCodeInvalidPayment,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var posted transaction.FrontendTransaction
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/transaction/cost" {
+					t.Errorf("request to %s, want /transaction/cost", r.URL.Path)
+				}
+				json.NewDecoder(r.Body).Decode(&posted)
+				w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			scheme, _ := NewExactMultiversXScheme(server.URL, &MockSigner{})
+			payload := multiversx.ExactRelayedPayload{
+				Nonce:    3,
+				Receiver: "erd1qqqqqqqqqqqqqpgqfzydqmdw7m2vazsp6u5p95yxz76t2p9rd8ss0zp9ts",
+				Sender:   "erd1spyavw0956vq68xj8y4tenjpq2wd5a9p2c6j8gsz7ztyrnpxrruqzu66jx",
+				GasPrice: 1000000000,
+				GasLimit: 1,
+				Data:     "buy@01",
+				ChainID:  "D",
+				Version:  1,
+			}
+			requirements := types.PaymentRequirements{Network: tt.network}
+
+			got, err := scheme.EstimateGas(context.Background(), types.PaymentPayload{Payload: payload.ToMap()}, requirements)
+			if tt.wantReason != "" {
+				var vErr *x402.VerifyError
+				if !errors.As(err, &vErr) || vErr.Reason != tt.wantReason {
+					t.Fatalf("EstimateGas() error = %v, want %s", err, tt.wantReason)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("EstimateGas() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("EstimateGas() = %d, want %d", got, tt.want)
+			}
+			if string(posted.Data) != "buy@01" || posted.Sender != payload.Sender {
+				t.Errorf("posted transaction = %+v, want the payload transaction", posted)
+			}
+		})
+	}
+}
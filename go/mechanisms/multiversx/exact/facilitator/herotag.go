@@ -0,0 +1,87 @@
+package facilitator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/mechanisms/multiversx"
+	"github.com/coinbase/x402/go/types"
+)
+
+// HerotagResolver resolves a herotag ("@alice" or "alice.elrond") to the bech32 address owning it
+type HerotagResolver func(ctx context.Context, herotag string) (string, error)
+
+// WithHerotagResolver overrides how herotag PayTo requirements are resolved
+// By default the /usernames endpoint of the configured API URL is queried.
+func WithHerotagResolver(resolver HerotagResolver) Option {
+	return func(s *ExactMultiversXScheme) {
+		s.herotagResolver = resolver
+	}
+}
+
+// resolveRequirements returns the requirements with a herotag PayTo replaced by its address
+// Herotags cannot be reassigned, so successful resolutions are cached for the scheme lifetime.
+func (s *ExactMultiversXScheme) resolveRequirements(ctx context.Context, requirements types.PaymentRequirements) (types.PaymentRequirements, error) {
+	if !multiversx.IsHerotag(requirements.PayTo) {
+		return requirements, nil
+	}
+
+	herotag := multiversx.HerotagUsername(requirements.PayTo)
+	if cached, ok := s.herotags.Load(herotag); ok {
+		requirements.PayTo = cached.(string)
+		return requirements, nil
+	}
+
+	resolver := s.herotagResolver
+	if resolver == nil {
+		resolver = s.resolveHerotagViaAPI
+	}
+	address, err := resolver(ctx, herotag)
+	if err != nil {
+		return requirements, x402.NewVerifyError("herotag_resolution_failed", "", "multiversx", fmt.Errorf("failed to resolve herotag %s: %w", requirements.PayTo, err))
+	}
+	if !multiversx.IsValidAddress(address) {
+		return requirements, x402.NewVerifyError("herotag_resolution_failed", "", "multiversx", fmt.Errorf("herotag %s resolved to invalid address %q", requirements.PayTo, address))
+	}
+
+	s.herotags.Store(herotag, address)
+	requirements.PayTo = address
+	return requirements, nil
+}
+
+// resolveHerotagViaAPI looks the herotag up on the MultiversX API /usernames endpoint
+func (s *ExactMultiversXScheme) resolveHerotagViaAPI(ctx context.Context, herotag string) (string, error) {
+	if s.config.ApiUrl == "" {
+		return "", errors.New("api url not configured")
+	}
+
+	username := strings.TrimSuffix(herotag, ".elrond")
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/usernames/%s", s.config.ApiUrl, url.PathEscape(username)), nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := s.client().Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("usernames api error: %s", resp.Status)
+	}
+
+	var account struct {
+		Address string `json:"address"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&account); err != nil {
+		return "", err
+	}
+	return account.Address, nil
+}
@@ -0,0 +1,120 @@
+package facilitator
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/multiversx/mx-sdk-go/data"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/mechanisms/multiversx"
+	"github.com/coinbase/x402/go/types"
+)
+
+// signedDirectPayload returns a signed direct EGLD payment of 1000 from a fresh key to receiver
+func signedDirectPayload(t *testing.T, receiver string) types.PaymentPayload {
+	t.Helper()
+	pubKey, privKey, _ := ed25519.GenerateKey(nil)
+	senderAddr, _ := data.NewAddressFromBytes(pubKey).AddressAsBech32String()
+
+	payload := multiversx.ExactRelayedPayload{
+		Nonce:    1,
+		Value:    "1000",
+		Receiver: receiver,
+		Sender:   senderAddr,
+		GasPrice: 1000000000,
+		GasLimit: 50000,
+		ChainID:  "D",
+		Version:  1,
+	}
+	tx := payload.ToTransaction()
+	txBytes, _ := multiversx.SerializeTransaction(&tx)
+	payload.Signature = hex.EncodeToString(ed25519.Sign(privKey, txBytes))
+	return types.PaymentPayload{Payload: payload.ToMap()}
+}
+
+func TestVerify_HerotagPayTo(t *testing.T) {
+	resolved := "erd1spyavw0956vq68xj8y4tenjpq2wd5a9p2c6j8gsz7ztyrnpxrruqzu66jx"
+	other := "erd1qyu5wthldzr8wx5c9ucg8kjagg0jfs53s8nr3zpz3hypefsdd8ssycr6th"
+
+	var lookups int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/usernames/alice":
+			lookups++
+			w.Write([]byte(`{"address":"` + resolved + `","username":"alice.elrond"}`))
+		case "/usernames/nobody":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.Write([]byte(`{"data":{"result":{"status":"success","hash":"sim_hash"}},"error":""}`))
+		}
+	}))
+	defer server.Close()
+	scheme, _ := NewExactMultiversXScheme(server.URL, &MockSigner{})
+
+	req := types.PaymentRequirements{
+		PayTo:  "@alice",
+		Amount: "1000",
+		Asset:  multiversx.NativeTokenTicker,
+		Extra:  map[string]interface{}{"assetTransferMethod": multiversx.TransferMethodDirect},
+	}
+
+	for i := 0; i < 2; i++ {
+		resp, err := scheme.Verify(context.Background(), signedDirectPayload(t, resolved), req)
+		if err != nil || !resp.IsValid {
+			t.Fatalf("Expected payment to the resolved address to verify, got %v", err)
+		}
+	}
+	// "alice.elrond" is the same herotag and hits the cache as well
+	req.PayTo = "alice.elrond"
+	if _, err := scheme.Verify(context.Background(), signedDirectPayload(t, resolved), req); err != nil {
+		t.Fatalf("Expected alice.elrond to verify, got %v", err)
+	}
+	if lookups != 1 {
+		t.Errorf("herotag resolved %d times, want 1", lookups)
+	}
+
+	_, err := scheme.Verify(context.Background(), signedDirectPayload(t, other), req)
+	var vErr *x402.VerifyError
+	if !errors.As(err, &vErr) || vErr.Reason != multiversx.ErrCodeReceiverMismatch {
+		t.Errorf("Expected %s for another receiver, got %v", multiversx.ErrCodeReceiverMismatch, err)
+	}
+
+	req.PayTo = "@nobody"
+	_, err = scheme.Verify(context.Background(), signedDirectPayload(t, resolved), req)
+	if !errors.As(err, &vErr) || vErr.Reason != "herotag_resolution_failed" {
+		t.Errorf("Expected herotag_resolution_failed, got %v", err)
+	}
+}
+
+func TestVerify_CustomHerotagResolver(t *testing.T) {
+	resolved := "erd1spyavw0956vq68xj8y4tenjpq2wd5a9p2c6j8gsz7ztyrnpxrruqzu66jx"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":{"result":{"status":"success","hash":"sim_hash"}},"error":""}`))
+	}))
+	defer server.Close()
+
+	var resolvedNames []string
+	scheme, _ := NewExactMultiversXScheme(server.URL, &MockSigner{}, WithHerotagResolver(func(ctx context.Context, herotag string) (string, error) {
+		resolvedNames = append(resolvedNames, herotag)
+		return resolved, nil
+	}))
+
+	req := types.PaymentRequirements{
+		PayTo:  "@alice",
+		Amount: "1000",
+		Asset:  multiversx.NativeTokenTicker,
+		Extra:  map[string]interface{}{"assetTransferMethod": multiversx.TransferMethodDirect},
+	}
+	if _, err := scheme.Verify(context.Background(), signedDirectPayload(t, resolved), req); err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if len(resolvedNames) != 1 || resolvedNames[0] != "alice.elrond" {
+		t.Errorf("resolver called with %v, want [alice.elrond]", resolvedNames)
+	}
+}
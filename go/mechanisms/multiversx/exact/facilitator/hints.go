@@ -0,0 +1,41 @@
+package facilitator
+
+import (
+	"errors"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/mechanisms/multiversx"
+	"github.com/coinbase/x402/go/types"
+)
+
+// WithRemediationHints makes Verify attach a remediation hint to its failures
+// The hint ("increase gasLimit to at least 56000 and re-sign", "fund erd1... with at least
+// 0.5 EGLD") is carried by a multiversx.HintedError wrapped in the VerifyError, so the reason
+// code is unchanged and callers can extract it with errors.As.
+func WithRemediationHints() Option {
+	return func(s *ExactMultiversXScheme) {
+		s.hints = true
+	}
+}
+
+// withRemediationHint wraps the cause of a verification failure with its remediation hint
+// Failures without a known hint are returned unchanged.
+func (s *ExactMultiversXScheme) withRemediationHint(err error, payload types.PaymentPayload) error {
+	if !s.hints {
+		return err
+	}
+	var vErr *x402.VerifyError
+	if !errors.As(err, &vErr) {
+		return err
+	}
+
+	var relayedPayload multiversx.ExactRelayedPayload
+	if payloadPtr, parseErr := multiversx.PayloadFromMap(payload.Payload); parseErr == nil {
+		relayedPayload = *payloadPtr
+	}
+	hint := multiversx.RemediationHint(err, relayedPayload)
+	if hint == "" {
+		return err
+	}
+	return x402.NewVerifyError(vErr.Reason, vErr.Payer, vErr.Network, &multiversx.HintedError{Err: vErr.Err, Hint: hint})
+}
@@ -0,0 +1,94 @@
+package facilitator
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/multiversx/mx-sdk-go/data"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/mechanisms/multiversx"
+	"github.com/coinbase/x402/go/types"
+)
+
+func TestVerify_RemediationHints(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":{"result":{"status":"success","hash":"sim_hash"}},"error":""}`))
+	}))
+	defer server.Close()
+
+	pubKey, privKey, _ := ed25519.GenerateKey(nil)
+	senderAddr, _ := data.NewAddressFromBytes(pubKey).AddressAsBech32String()
+
+	// Needs 1000 of value plus a maximum fee of 50000 * 10^9 = 5 * 10^13
+	payload := multiversx.ExactRelayedPayload{
+		Nonce:    1,
+		Value:    "1000",
+		Receiver: senderAddr,
+		Sender:   senderAddr,
+		GasPrice: 1000000000,
+		GasLimit: 50000,
+		ChainID:  "D",
+		Version:  1,
+	}
+	tx := payload.ToTransaction()
+	txBytes, _ := multiversx.SerializeTransaction(&tx)
+	payload.Signature = hex.EncodeToString(ed25519.Sign(privKey, txBytes))
+
+	req := types.PaymentRequirements{
+		PayTo:  senderAddr,
+		Amount: "1000",
+		Asset:  multiversx.NativeTokenTicker,
+		Extra:  map[string]interface{}{"assetTransferMethod": multiversx.TransferMethodDirect},
+	}
+
+	t.Run("insufficient balance", func(t *testing.T) {
+		scheme, _ := NewExactMultiversXScheme(server.URL, &MockSigner{}, WithBalanceCheck(), WithRemediationHints())
+		scheme.proxy = &MockProxy{account: &data.Account{Balance: "1000"}}
+
+		_, err := scheme.Verify(context.Background(), types.PaymentPayload{Payload: payload.ToMap()}, req)
+		var vErr *x402.VerifyError
+		if !errors.As(err, &vErr) || vErr.Reason != multiversx.ErrCodeInsufficientBalance {
+			t.Fatalf("Verify() error = %v, want %s", err, multiversx.ErrCodeInsufficientBalance)
+		}
+		var hinted *multiversx.HintedError
+		if !errors.As(err, &hinted) {
+			t.Fatalf("Expected a remediation hint, got %v", err)
+		}
+		if want := "fund " + senderAddr + " with at least 0.00005 EGLD"; hinted.Hint != want {
+			t.Errorf("hint = %q, want %q", hinted.Hint, want)
+		}
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		expired := payload
+		expired.ValidBefore = 1
+		scheme, _ := NewExactMultiversXScheme(server.URL, &MockSigner{}, WithRemediationHints())
+		scheme.proxy = &MockProxy{}
+
+		_, err := scheme.Verify(context.Background(), types.PaymentPayload{Payload: expired.ToMap()}, req)
+		var hinted *multiversx.HintedError
+		if !errors.As(err, &hinted) {
+			t.Fatalf("Expected a remediation hint, got %v", err)
+		}
+		if want := "payment expired, re-sign it with a fresh validBefore"; hinted.Hint != want {
+			t.Errorf("hint = %q, want %q", hinted.Hint, want)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		scheme, _ := NewExactMultiversXScheme(server.URL, &MockSigner{}, WithBalanceCheck())
+		scheme.proxy = &MockProxy{account: &data.Account{Balance: "1000"}}
+
+		_, err := scheme.Verify(context.Background(), types.PaymentPayload{Payload: payload.ToMap()}, req)
+		var hinted *multiversx.HintedError
+		if err == nil || errors.As(err, &hinted) {
+			t.Errorf("Verify() error = %v, want a failure without hint", err)
+		}
+	})
+}
@@ -0,0 +1,272 @@
+package facilitator
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"sync"
+	"time"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/mechanisms/multiversx"
+	"github.com/coinbase/x402/go/types"
+)
+
+// IdempotencyKeyExtension is the payment payload extension carrying an explicit idempotency key
+const IdempotencyKeyExtension = "idempotencyKey"
+
+// IdempotentSettlement is the outcome of a settlement recorded under an idempotency key
+// It is first recorded when the transaction is broadcast, with a response marked pending in
+// Extra["pending"], then replaced by the final response or, when the transaction failed, by the
+// failure reason in Response.ErrorReason and its message in Error.
+type IdempotentSettlement struct {
+	// Fingerprint identifies the payload and requirements that were settled under the key
+	Fingerprint string
+	Response    x402.SettleResponse
+	// Error is the message of a settlement that failed after its broadcast
+	Error string
+}
+
+// IdempotencyStore maps idempotency keys to the result of the settlement they identify
+// A retried Settle with a known key returns the recorded result instead of broadcasting again.
+type IdempotencyStore interface {
+	// GetSettlement returns the settlement recorded under key, if any
+	GetSettlement(ctx context.Context, key string) (IdempotentSettlement, bool, error)
+	// PutSettlement records the settlement under key
+	PutSettlement(ctx context.Context, key string, settlement IdempotentSettlement) error
+}
+
+// MemoryIdempotencyStore is an in-memory IdempotencyStore; its state is lost on restart
+type MemoryIdempotencyStore struct {
+	mu          sync.RWMutex
+	settlements *expiringMap[string, IdempotentSettlement]
+}
+
+// NewMemoryIdempotencyStore creates an empty in-memory idempotency store
+// Settlements are forgotten after DefaultMemoryStoreTTL so the store does not grow with every key;
+// clients retry within seconds or minutes, well inside that window.
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{settlements: newExpiringMap[string, IdempotentSettlement](DefaultMemoryStoreTTL)}
+}
+
+// WithTTL sets how long new settlements are kept; zero keeps them for the lifetime of the store
+func (m *MemoryIdempotencyStore) WithTTL(ttl time.Duration) *MemoryIdempotencyStore {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.settlements.ttl = ttl
+	return m
+}
+
+// GetSettlement implements IdempotencyStore
+func (m *MemoryIdempotencyStore) GetSettlement(ctx context.Context, key string) (IdempotentSettlement, bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	settlement, ok := m.settlements.get(key)
+	settlement.Response.Extra = maps.Clone(settlement.Response.Extra)
+	return settlement, ok, nil
+}
+
+// PutSettlement implements IdempotencyStore
+func (m *MemoryIdempotencyStore) PutSettlement(ctx context.Context, key string, settlement IdempotentSettlement) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	settlement.Response.Extra = maps.Clone(settlement.Response.Extra)
+	m.settlements.set(key, settlement)
+	return nil
+}
+
+// WithIdempotencyStore makes Settle idempotent: a retried settlement returns the recorded result
+// instead of failing as replayed. The key is the payload extension IdempotencyKeyExtension when set,
+// otherwise the payment sender, nonce and chain ID. Reusing a key for another payment or other
+// requirements fails with idempotency_key_reused. Concurrent settlements of a key are serialized,
+// and a retry arriving while the transaction is still pending elsewhere gets the pending response.
+// Retries of a settlement recorded as pending look its transaction up again and record the final outcome.
+func WithIdempotencyStore(store IdempotencyStore) Option {
+	return func(s *ExactMultiversXScheme) {
+		s.idempotencyStore = store
+	}
+}
+
+// settle settles the payment once per idempotency key when an idempotency store is configured
+func (s *ExactMultiversXScheme) settle(ctx context.Context, payload types.PaymentPayload, requirements types.PaymentRequirements) (resp *x402.SettleResponse, err error) {
+	defer func() { s.sink().IncSettle(err == nil && resp != nil && resp.Success) }()
+
+	if s.idempotencyStore == nil {
+		return s.settlePayment(ctx, payload, requirements, nil)
+	}
+
+	relayedPayload, err := multiversx.PayloadFromMap(payload.Payload)
+	if err != nil {
+		return nil, x402.NewSettleError("invalid_payload", "", "multiversx", "", err)
+	}
+	key := idempotencyKey(payload, *relayedPayload)
+	fingerprint, err := settlementFingerprint(*relayedPayload, requirements)
+	if err != nil {
+		return nil, x402.NewSettleError("invalid_payload", relayedPayload.Sender, "multiversx", "", err)
+	}
+
+	unlock, err := s.settleLocks.lock(ctx, key)
+	if err != nil {
+		return nil, x402.NewSettleError("settlement_in_progress", relayedPayload.Sender, "multiversx", "", fmt.Errorf("waiting for the settlement under idempotency key %q: %w", key, err))
+	}
+	defer unlock()
+
+	recorded, ok, err := s.idempotencyStore.GetSettlement(ctx, key)
+	if err != nil {
+		return nil, x402.NewSettleError("settlement_store_unavailable", relayedPayload.Sender, "multiversx", "", err)
+	}
+	if ok {
+		if recorded.Fingerprint != fingerprint {
+			return nil, x402.NewSettleError("idempotency_key_reused", relayedPayload.Sender, "multiversx", "", fmt.Errorf("idempotency key %q was used for another payment", key))
+		}
+		if recorded.Response.Extra["pending"] == true {
+			recorded = s.refreshPending(ctx, key, recorded, relayedPayload.Sender)
+		}
+		if !recorded.Response.Success {
+			return nil, x402.NewSettleError(recorded.Response.ErrorReason, recorded.Response.Payer, "multiversx", recorded.Response.Transaction, errors.New(recorded.Error))
+		}
+		return &recorded.Response, nil
+	}
+
+	// As for the settled store, failures to record are not reported: the payment went through
+	// and a retry is still refused as replayed instead of being broadcast twice
+	broadcast := false
+	resp, err = s.settlePayment(ctx, payload, requirements, func(pending *x402.SettleResponse) {
+		broadcast = true
+		_ = s.idempotencyStore.PutSettlement(context.WithoutCancel(ctx), key, IdempotentSettlement{Fingerprint: fingerprint, Response: *pending})
+	})
+	if err != nil {
+		// A transaction still pending when the caller gave up may yet succeed: it stays recorded as pending
+		var settleErr *x402.SettleError
+		if broadcast && !isContextError(err) && errors.As(err, &settleErr) {
+			_ = s.idempotencyStore.PutSettlement(context.WithoutCancel(ctx), key, IdempotentSettlement{
+				Fingerprint: fingerprint,
+				Response: x402.SettleResponse{
+					ErrorReason: settleErr.Reason,
+					Payer:       relayedPayload.Sender,
+					Transaction: settleErr.Transaction,
+				},
+				Error: err.Error(),
+			})
+		}
+		return nil, err
+	}
+	_ = s.idempotencyStore.PutSettlement(context.WithoutCancel(ctx), key, IdempotentSettlement{Fingerprint: fingerprint, Response: *resp})
+	return resp, nil
+}
+
+// refreshPending looks up the transaction of a settlement recorded as pending and records its final outcome
+// The pending record is returned unchanged while the transaction is not final or its status cannot be read.
+func (s *ExactMultiversXScheme) refreshPending(ctx context.Context, key string, recorded IdempotentSettlement, sender string) IdempotentSettlement {
+	hash := recorded.Response.Transaction
+	if s.proxy == nil || hash == "" {
+		return recorded
+	}
+	status, err := s.getTransactionStatus(ctx, hash)
+	if err != nil {
+		return recorded
+	}
+
+	final := IdempotentSettlement{Fingerprint: recorded.Fingerprint}
+	switch settlementStatusOf(status) {
+	case SettlementStatusSuccess:
+		rejection, err := s.contractRejection(ctx, hash)
+		if err != nil {
+			return recorded
+		}
+		if rejection != nil {
+			final.Response = x402.SettleResponse{ErrorReason: multiversx.ErrCodeContractRejected, Payer: sender, Transaction: hash}
+			final.Error = fmt.Sprintf("transaction executed with status %s but its contract call failed: %v", status, rejection)
+			break
+		}
+		extra := maps.Clone(recorded.Response.Extra)
+		delete(extra, "pending")
+		final.Response = x402.SettleResponse{Success: true, Transaction: hash, Extra: extra}
+	case SettlementStatusFailed:
+		final.Response = x402.SettleResponse{ErrorReason: "tx_failed", Payer: sender, Transaction: hash}
+		final.Error = fmt.Sprintf("transaction failed with status: %s", status)
+	default:
+		return recorded
+	}
+
+	_ = s.idempotencyStore.PutSettlement(context.WithoutCancel(ctx), key, final)
+	return final
+}
+
+// keyLocks serializes work per key; its zero value is ready to use
+type keyLocks struct {
+	mu    sync.Mutex
+	locks map[string]*keyLock
+}
+
+// keyLock is the lock of a single key, dropped once nobody holds or waits for it
+type keyLock struct {
+	held chan struct{}
+	refs int
+}
+
+// lock waits until key is free or ctx is done, and returns the function releasing key
+func (l *keyLocks) lock(ctx context.Context, key string) (func(), error) {
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = make(map[string]*keyLock)
+	}
+	k, ok := l.locks[key]
+	if !ok {
+		k = &keyLock{held: make(chan struct{}, 1)}
+		l.locks[key] = k
+	}
+	k.refs++
+	l.mu.Unlock()
+
+	release := func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		k.refs--
+		if k.refs == 0 {
+			delete(l.locks, key)
+		}
+	}
+
+	select {
+	case k.held <- struct{}{}:
+		return func() {
+			<-k.held
+			release()
+		}, nil
+	case <-ctx.Done():
+		release()
+		return nil, ctx.Err()
+	}
+}
+
+// idempotencyKey returns the explicit idempotency key of the payment, or one derived from its sender nonce
+func idempotencyKey(payload types.PaymentPayload, relayedPayload multiversx.ExactRelayedPayload) string {
+	if key, ok := payload.Extensions[IdempotencyKeyExtension].(string); ok && key != "" {
+		return "key:" + key
+	}
+	return fmt.Sprintf("nonce:%s:%d:%s", relayedPayload.Sender, relayedPayload.Nonce, relayedPayload.ChainID)
+}
+
+// settlementFingerprint binds a recorded settlement to the payload and the requirements it paid
+func settlementFingerprint(payload multiversx.ExactRelayedPayload, requirements types.PaymentRequirements) (string, error) {
+	paymentFingerprint, err := payloadFingerprint(payload)
+	if err != nil {
+		return "", err
+	}
+	requirementsJSON, err := json.Marshal(requirements)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode requirements: %w", err)
+	}
+	hash := sha256.New()
+	hash.Write([]byte(paymentFingerprint))
+	hash.Write(requirementsJSON)
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,314 @@
+package facilitator
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/multiversx/mx-chain-core-go/data/transaction"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/mechanisms/multiversx"
+	"github.com/coinbase/x402/go/types"
+)
+
+// countingProxy counts the transactions broadcast through it
+type countingProxy struct {
+	*MockProxy
+	sent int
+}
+
+func (p *countingProxy) SendTransaction(ctx context.Context, tx *transaction.FrontendTransaction) (string, error) {
+	p.sent++
+	return p.MockProxy.SendTransaction(ctx, tx)
+}
+
+func TestSettle_IdempotentRetry(t *testing.T) {
+	proxy := &countingProxy{MockProxy: &MockProxy{
+		sendHash:        "tx_hash_1",
+		statusResponses: []transaction.TxStatus{transaction.TxStatusSuccess},
+	}}
+	scheme := &ExactMultiversXScheme{proxy: proxy}
+	WithIdempotencyStore(NewMemoryIdempotencyStore())(scheme)
+
+	relayed := multiversx.ExactRelayedPayload{Sender: "erd1sender", Receiver: "erd1receiver", Value: "1000", Nonce: 4, ChainID: "D"}
+	payload := types.PaymentPayload{Payload: relayed.ToMap()}
+	requirements := types.PaymentRequirements{Amount: "1000", Extra: map[string]interface{}{"assetTransferMethod": multiversx.TransferMethodDirect}}
+
+	first, err := scheme.Settle(context.Background(), payload, requirements)
+	if err != nil {
+		t.Fatalf("Settle() error = %v", err)
+	}
+	proxy.sendHash = "tx_hash_2"
+	retried, err := scheme.Settle(context.Background(), payload, requirements)
+	if err != nil {
+		t.Fatalf("retried Settle() error = %v", err)
+	}
+	if retried.Transaction != first.Transaction || proxy.sent != 1 {
+		t.Errorf("retried Settle() = %s after %d broadcasts, want %s after 1", retried.Transaction, proxy.sent, first.Transaction)
+	}
+
+	// The same payment against other requirements is not a retry
+	other := requirements
+	other.PayTo = "erd1other"
+	_, err = scheme.Settle(context.Background(), payload, other)
+	var sErr *x402.SettleError
+	if !errors.As(err, &sErr) || sErr.Reason != "idempotency_key_reused" {
+		t.Errorf("Settle() with other requirements error = %v, want idempotency_key_reused", err)
+	}
+	if proxy.sent != 1 {
+		t.Errorf("broadcasts = %d, want 1", proxy.sent)
+	}
+}
+
+func TestSettle_ExplicitIdempotencyKey(t *testing.T) {
+	proxy := &countingProxy{MockProxy: &MockProxy{sendHash: "tx_hash"}}
+	scheme := &ExactMultiversXScheme{proxy: proxy, asyncSettle: true}
+	WithIdempotencyStore(NewMemoryIdempotencyStore())(scheme)
+
+	requirements := types.PaymentRequirements{Extra: map[string]interface{}{"assetTransferMethod": multiversx.TransferMethodDirect}}
+	withKey := func(nonce uint64) types.PaymentPayload {
+		relayed := multiversx.ExactRelayedPayload{Sender: "erd1sender", Nonce: nonce, ChainID: "D"}
+		return types.PaymentPayload{
+			Payload:    relayed.ToMap(),
+			Extensions: map[string]interface{}{IdempotencyKeyExtension: "order-42"},
+		}
+	}
+
+	if _, err := scheme.Settle(context.Background(), withKey(1), requirements); err != nil {
+		t.Fatalf("Settle() error = %v", err)
+	}
+	resp, err := scheme.Settle(context.Background(), withKey(1), requirements)
+	if err != nil || resp.Transaction != "tx_hash" || resp.Extra["pending"] != true {
+		t.Fatalf("retried Settle() = %+v, %v", resp, err)
+	}
+
+	// Another payment reusing the key is refused rather than answered with the first hash
+	_, err = scheme.Settle(context.Background(), withKey(2), requirements)
+	var sErr *x402.SettleError
+	if !errors.As(err, &sErr) || sErr.Reason != "idempotency_key_reused" {
+		t.Errorf("Settle() reusing the key error = %v, want idempotency_key_reused", err)
+	}
+	if proxy.sent != 1 {
+		t.Errorf("broadcasts = %d, want 1", proxy.sent)
+	}
+}
+
+// gatedProxy holds every transaction pending until release is closed
+type gatedProxy struct {
+	*MockProxy
+	sent      atomic.Int32
+	broadcast chan struct{}
+	release   chan struct{}
+}
+
+func newGatedProxy() *gatedProxy {
+	return &gatedProxy{MockProxy: &MockProxy{sendHash: "tx_hash"}, broadcast: make(chan struct{}, 1), release: make(chan struct{})}
+}
+
+func (p *gatedProxy) SendTransaction(ctx context.Context, tx *transaction.FrontendTransaction) (string, error) {
+	p.sent.Add(1)
+	p.broadcast <- struct{}{}
+	return p.MockProxy.SendTransaction(ctx, tx)
+}
+
+func (p *gatedProxy) GetTransactionStatus(ctx context.Context, txHash string) (string, error) {
+	select {
+	case <-p.release:
+		return string(transaction.TxStatusSuccess), nil
+	default:
+		return string(transaction.TxStatusPending), nil
+	}
+}
+
+// pollQuickly replaces the status poll delays of a test scheme
+func pollQuickly(ctx context.Context, d time.Duration) error {
+	return sleepContext(ctx, time.Millisecond)
+}
+
+func TestSettle_IdempotentConcurrent(t *testing.T) {
+	proxy := newGatedProxy()
+	scheme := &ExactMultiversXScheme{proxy: proxy, sleep: pollQuickly}
+	WithIdempotencyStore(NewMemoryIdempotencyStore())(scheme)
+
+	relayed := multiversx.ExactRelayedPayload{Sender: "erd1sender", Receiver: "erd1receiver", Value: "1000", Nonce: 4, ChainID: "D"}
+	payload := types.PaymentPayload{Payload: relayed.ToMap()}
+	requirements := types.PaymentRequirements{Amount: "1000", Extra: map[string]interface{}{"assetTransferMethod": multiversx.TransferMethodDirect}}
+
+	type result struct {
+		resp *x402.SettleResponse
+		err  error
+	}
+	results := make(chan result, 2)
+	settle := func() {
+		resp, err := scheme.Settle(context.Background(), payload, requirements)
+		results <- result{resp, err}
+	}
+
+	go settle()
+	<-proxy.broadcast
+	go settle()
+	// Let the second settlement queue up behind the first, still waiting for its transaction
+	key := idempotencyKey(payload, relayed)
+	for waiting := 0; waiting < 2; {
+		scheme.settleLocks.mu.Lock()
+		if lock, ok := scheme.settleLocks.locks[key]; ok {
+			waiting = lock.refs
+		}
+		scheme.settleLocks.mu.Unlock()
+		time.Sleep(time.Millisecond)
+	}
+	close(proxy.release)
+
+	first, second := <-results, <-results
+	if first.err != nil || second.err != nil {
+		t.Fatalf("Settle() errors = %v, %v", first.err, second.err)
+	}
+	if !reflect.DeepEqual(first.resp, second.resp) {
+		t.Errorf("concurrent Settle() responses differ: %+v and %+v", first.resp, second.resp)
+	}
+	if first.resp.Extra["pending"] != nil {
+		t.Errorf("Settle() = %+v, want the final response", first.resp)
+	}
+	if sent := proxy.sent.Load(); sent != 1 {
+		t.Errorf("broadcasts = %d, want 1", sent)
+	}
+}
+
+func TestSettle_IdempotentRetryWhilePending(t *testing.T) {
+	store := NewMemoryIdempotencyStore()
+	proxy := newGatedProxy()
+	first := &ExactMultiversXScheme{proxy: proxy, sleep: pollQuickly}
+	WithIdempotencyStore(store)(first)
+	// A second facilitator sharing the store receives the retry
+	second := &ExactMultiversXScheme{proxy: proxy}
+	WithIdempotencyStore(store)(second)
+
+	relayed := multiversx.ExactRelayedPayload{Sender: "erd1sender", Receiver: "erd1receiver", Value: "1000", Nonce: 4, ChainID: "D"}
+	payload := types.PaymentPayload{Payload: relayed.ToMap()}
+	requirements := types.PaymentRequirements{Amount: "1000", Extra: map[string]interface{}{"assetTransferMethod": multiversx.TransferMethodDirect}}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := first.Settle(context.Background(), payload, requirements)
+		done <- err
+	}()
+	<-proxy.broadcast
+
+	resp, err := second.Settle(context.Background(), payload, requirements)
+	if err != nil || resp.Transaction != "tx_hash" || resp.Extra["pending"] != true {
+		t.Errorf("retried Settle() while pending = %+v, %v; want the pending tx_hash", resp, err)
+	}
+
+	close(proxy.release)
+	if err := <-done; err != nil {
+		t.Fatalf("Settle() error = %v", err)
+	}
+	resp, err = second.Settle(context.Background(), payload, requirements)
+	if err != nil || resp.Extra["pending"] != nil {
+		t.Errorf("retried Settle() after confirmation = %+v, %v; want the final response", resp, err)
+	}
+	if sent := proxy.sent.Load(); sent != 1 {
+		t.Errorf("broadcasts = %d, want 1", sent)
+	}
+}
+
+func TestSettle_IdempotentFailureAfterBroadcast(t *testing.T) {
+	proxy := &countingProxy{MockProxy: &MockProxy{
+		sendHash:        "tx_hash",
+		statusResponses: []transaction.TxStatus{transaction.TxStatusFail},
+	}}
+	scheme := &ExactMultiversXScheme{proxy: proxy, sleep: func(context.Context, time.Duration) error { return nil }}
+	WithIdempotencyStore(NewMemoryIdempotencyStore())(scheme)
+
+	relayed := multiversx.ExactRelayedPayload{Sender: "erd1sender", Receiver: "erd1receiver", Value: "1000", Nonce: 4, ChainID: "D"}
+	payload := types.PaymentPayload{Payload: relayed.ToMap()}
+	requirements := types.PaymentRequirements{Amount: "1000", Extra: map[string]interface{}{"assetTransferMethod": multiversx.TransferMethodDirect}}
+
+	for attempt := 1; attempt <= 2; attempt++ {
+		_, err := scheme.Settle(context.Background(), payload, requirements)
+		var sErr *x402.SettleError
+		if !errors.As(err, &sErr) || sErr.Reason != "tx_failed" || sErr.Transaction != "tx_hash" {
+			t.Errorf("Settle() attempt %d error = %v, want tx_failed in tx_hash", attempt, err)
+		}
+	}
+	if proxy.sent != 1 {
+		t.Errorf("broadcasts = %d, want 1", proxy.sent)
+	}
+}
+
+func TestSettle_IdempotentRefreshesAsyncSettlement(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     transaction.TxStatus
+		wantReason string
+	}{
+		{name: "confirmed", status: transaction.TxStatusSuccess},
+		{name: "failed", status: transaction.TxStatusFail, wantReason: "tx_failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proxy := &countingProxy{MockProxy: &MockProxy{sendHash: "tx_hash"}}
+			scheme := &ExactMultiversXScheme{proxy: proxy, asyncSettle: true}
+			WithIdempotencyStore(NewMemoryIdempotencyStore())(scheme)
+
+			relayed := multiversx.ExactRelayedPayload{Sender: "erd1sender", Receiver: "erd1receiver", Value: "1000", Nonce: 4, ChainID: "D"}
+			payload := types.PaymentPayload{Payload: relayed.ToMap()}
+			requirements := types.PaymentRequirements{Amount: "1000", Extra: map[string]interface{}{"assetTransferMethod": multiversx.TransferMethodDirect}}
+
+			resp, err := scheme.Settle(context.Background(), payload, requirements)
+			if err != nil || resp.Extra["pending"] != true {
+				t.Fatalf("Settle() = %+v, %v; want a pending response", resp, err)
+			}
+			// Still pending on the network: the retry gets the pending response again
+			resp, err = scheme.Settle(context.Background(), payload, requirements)
+			if err != nil || resp.Extra["pending"] != true {
+				t.Fatalf("retried Settle() = %+v, %v; want a pending response", resp, err)
+			}
+
+			proxy.statusResponses = []transaction.TxStatus{tt.status}
+			for attempt := 1; attempt <= 2; attempt++ {
+				resp, err = scheme.Settle(context.Background(), payload, requirements)
+				if tt.wantReason == "" {
+					if err != nil || !resp.Success || resp.Extra["pending"] != nil || resp.Transaction != "tx_hash" {
+						t.Errorf("retried Settle() attempt %d = %+v, %v; want the confirmed tx_hash", attempt, resp, err)
+					}
+					continue
+				}
+				var sErr *x402.SettleError
+				if !errors.As(err, &sErr) || sErr.Reason != tt.wantReason || sErr.Transaction != "tx_hash" {
+					t.Errorf("retried Settle() attempt %d error = %v, want %s in tx_hash", attempt, err, tt.wantReason)
+				}
+			}
+			if proxy.sent != 1 {
+				t.Errorf("broadcasts = %d, want 1", proxy.sent)
+			}
+		})
+	}
+}
+
+func TestMemoryIdempotencyStore_SettlementsExpire(t *testing.T) {
+	store := NewMemoryIdempotencyStore().WithTTL(time.Hour)
+	current := time.Unix(1700000000, 0)
+	store.settlements.now = func() time.Time { return current }
+	ctx := context.Background()
+
+	settlement := IdempotentSettlement{Response: x402.SettleResponse{Success: true, Transaction: "hash"}}
+	store.PutSettlement(ctx, "key", settlement)
+	if got, ok, _ := store.GetSettlement(ctx, "key"); !ok || got.Response.Transaction != "hash" {
+		t.Fatalf("GetSettlement() = %+v, %v, want the recorded settlement", got, ok)
+	}
+
+	current = current.Add(time.Hour)
+	if _, ok, _ := store.GetSettlement(ctx, "key"); ok {
+		t.Error("Expected the settlement to be forgotten after the TTL")
+	}
+	store.PutSettlement(ctx, "other", settlement)
+	if len(store.settlements.entries) != 1 {
+		t.Errorf("entries = %d, want the expired settlement swept", len(store.settlements.entries))
+	}
+}
@@ -0,0 +1,39 @@
+package facilitator
+
+import "time"
+
+// MetricsSink receives the facilitator metrics, e.g. to export them as Prometheus counters and histograms
+// Methods are called synchronously on the verifying and settling goroutines and must return quickly.
+type MetricsSink interface {
+	// IncVerify counts a Verify call and whether the payment was valid
+	IncVerify(success bool)
+	// IncSettle counts a settlement and whether it succeeded
+	IncSettle(success bool)
+	// ObserveSimulationDuration records the duration of a simulation on the gateway, retries included
+	ObserveSimulationDuration(d time.Duration)
+	// ObserveSettleDuration records how long a settled transaction took to confirm after its broadcast
+	ObserveSettleDuration(d time.Duration)
+}
+
+// WithMetrics reports verification, simulation and settlement metrics to sink
+func WithMetrics(sink MetricsSink) Option {
+	return func(s *ExactMultiversXScheme) {
+		s.metrics = sink
+	}
+}
+
+// noopMetrics is the MetricsSink used when none is configured
+type noopMetrics struct{}
+
+func (noopMetrics) IncVerify(bool)                          {}
+func (noopMetrics) IncSettle(bool)                          {}
+func (noopMetrics) ObserveSimulationDuration(time.Duration) {}
+func (noopMetrics) ObserveSettleDuration(time.Duration)     {}
+
+// sink returns the configured metrics sink, or one discarding the metrics
+func (s *ExactMultiversXScheme) sink() MetricsSink {
+	if s.metrics == nil {
+		return noopMetrics{}
+	}
+	return s.metrics
+}
@@ -0,0 +1,114 @@
+package facilitator
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/multiversx/mx-chain-core-go/data/transaction"
+	"github.com/multiversx/mx-sdk-go/data"
+
+	"github.com/coinbase/x402/go/mechanisms/multiversx"
+	"github.com/coinbase/x402/go/types"
+)
+
+// recordingMetrics counts the metrics reported to it
+type recordingMetrics struct {
+	verifies    map[bool]int
+	settles     map[bool]int
+	simulations []time.Duration
+	settleTimes []time.Duration
+}
+
+func newRecordingMetrics() *recordingMetrics {
+	return &recordingMetrics{verifies: map[bool]int{}, settles: map[bool]int{}}
+}
+
+func (m *recordingMetrics) IncVerify(success bool) { m.verifies[success]++ }
+func (m *recordingMetrics) IncSettle(success bool) { m.settles[success]++ }
+func (m *recordingMetrics) ObserveSimulationDuration(d time.Duration) {
+	m.simulations = append(m.simulations, d)
+}
+func (m *recordingMetrics) ObserveSettleDuration(d time.Duration) {
+	m.settleTimes = append(m.settleTimes, d)
+}
+
+func TestWithMetrics_Verify(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":{"result":{"status":"success","hash":"sim_hash"}},"error":""}`))
+	}))
+	defer server.Close()
+
+	metrics := newRecordingMetrics()
+	scheme, _ := NewExactMultiversXScheme(server.URL, &MockSigner{}, WithMetrics(metrics))
+
+	pubKey, privKey, _ := ed25519.GenerateKey(nil)
+	senderAddr, _ := data.NewAddressFromBytes(pubKey).AddressAsBech32String()
+	payload := multiversx.ExactRelayedPayload{
+		Nonce:    1,
+		Value:    "1000",
+		Receiver: senderAddr,
+		Sender:   senderAddr,
+		GasPrice: 1000000000,
+		GasLimit: 50000,
+		ChainID:  "D",
+		Version:  1,
+	}
+	tx := payload.ToTransaction()
+	txBytes, _ := multiversx.SerializeTransaction(&tx)
+	payload.Signature = hex.EncodeToString(ed25519.Sign(privKey, txBytes))
+	req := types.PaymentRequirements{
+		PayTo:  senderAddr,
+		Amount: "1000",
+		Asset:  multiversx.NativeTokenTicker,
+		Extra: map[string]interface{}{
+			"assetTransferMethod": multiversx.TransferMethodDirect,
+		},
+	}
+
+	if _, err := scheme.Verify(context.Background(), types.PaymentPayload{Payload: payload.ToMap()}, req); err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	// A malformed sender fails before the payment is simulated
+	payload.Sender = "erd1invalid"
+	if _, err := scheme.Verify(context.Background(), types.PaymentPayload{Payload: payload.ToMap()}, req); err == nil {
+		t.Fatal("Verify() of a malformed sender succeeded")
+	}
+
+	if metrics.verifies[true] != 1 || metrics.verifies[false] != 1 {
+		t.Errorf("verify counts = %v, want one success and one failure", metrics.verifies)
+	}
+	if len(metrics.simulations) != 1 {
+		t.Errorf("simulation durations = %v, want one", metrics.simulations)
+	}
+}
+
+func TestWithMetrics_Settle(t *testing.T) {
+	metrics := newRecordingMetrics()
+	scheme := &ExactMultiversXScheme{
+		proxy: &MockProxy{sendHash: "tx_hash", statusResponses: []transaction.TxStatus{transaction.TxStatusPending, transaction.TxStatusSuccess}},
+		sleep: func(ctx context.Context, d time.Duration) error { return nil },
+	}
+	WithMetrics(metrics)(scheme)
+
+	payload := multiversx.ExactRelayedPayload{Sender: "erd1sender", Nonce: 1, ChainID: "D"}
+	requirements := types.PaymentRequirements{Extra: map[string]interface{}{"assetTransferMethod": multiversx.TransferMethodDirect}}
+	if _, err := scheme.Settle(context.Background(), types.PaymentPayload{Payload: payload.ToMap()}, requirements); err != nil {
+		t.Fatalf("Settle() error = %v", err)
+	}
+	// The replayed payment is refused before it is broadcast
+	if _, err := scheme.Settle(context.Background(), types.PaymentPayload{Payload: payload.ToMap()}, requirements); err == nil {
+		t.Fatal("Settle() of a replayed payment succeeded")
+	}
+
+	if metrics.settles[true] != 1 || metrics.settles[false] != 1 {
+		t.Errorf("settle counts = %v, want one success and one failure", metrics.settles)
+	}
+	if len(metrics.settleTimes) != 1 {
+		t.Errorf("settle durations = %v, want one for the confirmed transaction", metrics.settleTimes)
+	}
+}
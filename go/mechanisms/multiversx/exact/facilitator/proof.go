@@ -0,0 +1,50 @@
+package facilitator
+
+import (
+	"context"
+
+	"github.com/coinbase/x402/go/mechanisms/multiversx"
+	"github.com/coinbase/x402/go/types"
+)
+
+// WithSettlementProofs attaches a signed SettlementProof to completed settlements
+// The proof is signed with the first address of signer, which clients and resource servers
+// use to check it with multiversx.VerifySettlementProof. It is not added in async mode,
+// where the transaction is not final when Settle returns.
+func WithSettlementProofs(signer multiversx.ExternalSigner) Option {
+	return func(s *ExactMultiversXScheme) {
+		s.proofSigner = signer
+	}
+}
+
+// settlementProof builds and signs the proof of a completed settlement
+// The proof names the on-chain receiver, so a herotag PayTo is resolved first (usually from the cache
+// filled by Verify). The settlement already happened, so a resolution or signing failure only leaves the proof out.
+func (s *ExactMultiversXScheme) settlementProof(ctx context.Context, payload multiversx.ExactRelayedPayload, requirements types.PaymentRequirements, hash string, amount string) (multiversx.SettlementProof, bool) {
+	if s.proofSigner == nil {
+		return multiversx.SettlementProof{}, false
+	}
+	addresses := s.proofSigner.GetAddresses()
+	if len(addresses) == 0 {
+		return multiversx.SettlementProof{}, false
+	}
+	requirements, err := s.resolveRequirements(ctx, requirements)
+	if err != nil {
+		return multiversx.SettlementProof{}, false
+	}
+
+	proof, err := multiversx.SignSettlementProof(ctx, s.proofSigner, multiversx.SettlementProof{
+		Transaction: hash,
+		Network:     "multiversx:" + payload.ChainID,
+		Payer:       payload.Sender,
+		PayTo:       requirements.PayTo,
+		Asset:       requirements.Asset,
+		Amount:      amount,
+		SettledAt:   s.now().Unix(),
+		Facilitator: addresses[0],
+	})
+	if err != nil {
+		return multiversx.SettlementProof{}, false
+	}
+	return proof, true
+}
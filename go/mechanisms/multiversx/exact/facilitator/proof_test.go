@@ -0,0 +1,138 @@
+package facilitator
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/multiversx/mx-chain-core-go/data/transaction"
+	"github.com/multiversx/mx-sdk-go/data"
+
+	"github.com/coinbase/x402/go/mechanisms/multiversx"
+	"github.com/coinbase/x402/go/types"
+)
+
+// proofSigner holds a single Ed25519 attestation key
+type proofSigner struct {
+	address string
+	privKey ed25519.PrivateKey
+}
+
+func (p *proofSigner) GetAddresses() []string {
+	return []string{p.address}
+}
+
+func (p *proofSigner) SignBytes(ctx context.Context, address string, message []byte) ([]byte, error) {
+	return ed25519.Sign(p.privKey, message), nil
+}
+
+func TestSettle_AttachesSettlementProof(t *testing.T) {
+	pubKey, privKey, _ := ed25519.GenerateKey(nil)
+	address, _ := data.NewAddressFromBytes(pubKey).AddressAsBech32String()
+
+	scheme := &ExactMultiversXScheme{
+		proxy: &MockProxy{
+			sendHash:        "tx_hash_proof",
+			statusResponses: []transaction.TxStatus{transaction.TxStatusSuccess},
+		},
+		pollInterval: 10 * time.Millisecond,
+		clock:        func() time.Time { return time.Unix(1700000000, 0) },
+	}
+	WithSettlementProofs(&proofSigner{address: address, privKey: privKey})(scheme)
+
+	requirements := types.PaymentRequirements{
+		PayTo:  "erd1merchant",
+		Asset:  multiversx.NativeTokenTicker,
+		Amount: "1000",
+		Extra:  map[string]interface{}{"assetTransferMethod": multiversx.TransferMethodDirect},
+	}
+	payload := multiversx.ExactRelayedPayload{Sender: "erd1payer", ChainID: "D"}
+
+	resp, err := scheme.Settle(context.Background(), types.PaymentPayload{Payload: payload.ToMap()}, requirements)
+	if err != nil {
+		t.Fatalf("Settle() error = %v", err)
+	}
+
+	// The resource server forwards the response in the payment response header
+	body, _ := json.Marshal(resp)
+	proof, err := multiversx.SettlementProofFromHeader(base64.StdEncoding.EncodeToString(body))
+	if err != nil {
+		t.Fatalf("SettlementProofFromHeader() error = %v", err)
+	}
+	if err := multiversx.VerifySettlementProof(proof, address); err != nil {
+		t.Fatalf("VerifySettlementProof() error = %v", err)
+	}
+
+	want := multiversx.SettlementProof{
+		Transaction: "tx_hash_proof",
+		Network:     "multiversx:D",
+		Payer:       "erd1payer",
+		PayTo:       "erd1merchant",
+		Asset:       multiversx.NativeTokenTicker,
+		Amount:      "1000",
+		SettledAt:   1700000000,
+		Facilitator: address,
+		Signature:   proof.Signature,
+	}
+	if proof != want {
+		t.Errorf("proof = %+v, want %+v", proof, want)
+	}
+}
+
+func TestSettle_ProofNamesResolvedHerotag(t *testing.T) {
+	pubKey, privKey, _ := ed25519.GenerateKey(nil)
+	address, _ := data.NewAddressFromBytes(pubKey).AddressAsBech32String()
+	merchant := "erd1qyu5wthldzr8wx5c9ucg8kjagg0jfs53s8nr3zpz3hypefsdd8ssycr6th"
+
+	scheme := &ExactMultiversXScheme{
+		proxy: &MockProxy{
+			sendHash:        "tx_hash_herotag",
+			statusResponses: []transaction.TxStatus{transaction.TxStatusSuccess},
+		},
+		pollInterval: 10 * time.Millisecond,
+	}
+	WithSettlementProofs(&proofSigner{address: address, privKey: privKey})(scheme)
+	WithHerotagResolver(func(ctx context.Context, herotag string) (string, error) {
+		return merchant, nil
+	})(scheme)
+
+	requirements := types.PaymentRequirements{
+		PayTo:  "@alice",
+		Asset:  multiversx.NativeTokenTicker,
+		Amount: "1000",
+		Extra:  map[string]interface{}{"assetTransferMethod": multiversx.TransferMethodDirect},
+	}
+	payload := multiversx.ExactRelayedPayload{Sender: "erd1payer", ChainID: "D"}
+
+	resp, err := scheme.Settle(context.Background(), types.PaymentPayload{Payload: payload.ToMap()}, requirements)
+	if err != nil {
+		t.Fatalf("Settle() error = %v", err)
+	}
+	proof, ok := resp.Extra[multiversx.SettlementProofExtraKey].(multiversx.SettlementProof)
+	if !ok {
+		t.Fatalf("Expected a settlement proof, got %v", resp.Extra[multiversx.SettlementProofExtraKey])
+	}
+	if proof.PayTo != merchant {
+		t.Errorf("proof PayTo = %s, want the resolved address %s", proof.PayTo, merchant)
+	}
+}
+
+func TestSettle_NoProofWhenAsync(t *testing.T) {
+	pubKey, privKey, _ := ed25519.GenerateKey(nil)
+	address, _ := data.NewAddressFromBytes(pubKey).AddressAsBech32String()
+
+	scheme := &ExactMultiversXScheme{proxy: &MockProxy{sendHash: "tx_hash_async"}, asyncSettle: true}
+	WithSettlementProofs(&proofSigner{address: address, privKey: privKey})(scheme)
+
+	requirements := types.PaymentRequirements{Extra: map[string]interface{}{"assetTransferMethod": multiversx.TransferMethodDirect}}
+	resp, err := scheme.Settle(context.Background(), types.PaymentPayload{Payload: map[string]interface{}{}}, requirements)
+	if err != nil {
+		t.Fatalf("Settle() error = %v", err)
+	}
+	if _, ok := resp.Extra[multiversx.SettlementProofExtraKey]; ok {
+		t.Error("Expected no proof for a pending settlement")
+	}
+}
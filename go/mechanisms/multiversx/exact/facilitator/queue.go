@@ -0,0 +1,147 @@
+package facilitator
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/mechanisms/multiversx"
+	"github.com/coinbase/x402/go/types"
+)
+
+const (
+	// DefaultSettleConcurrency is how many settlements the queue broadcasts at once
+	DefaultSettleConcurrency = 4
+	// DefaultSettleQueueCapacity is how many settlements may wait in the queue before submitters block
+	DefaultSettleQueueCapacity = 256
+)
+
+// ErrSettlementQueueClosed is returned when submitting to a closed settlement queue
+var ErrSettlementQueueClosed = errors.New("settlement queue closed")
+
+// SettleResult is the outcome of a queued settlement
+type SettleResult struct {
+	Response *x402.SettleResponse
+	Err      error
+}
+
+// settleFunc performs a single settlement
+type settleFunc func(ctx context.Context, payload types.PaymentPayload, requirements types.PaymentRequirements) (*x402.SettleResponse, error)
+
+// SettlementQueue runs settlements through a bounded pool of workers.
+// At most concurrency settlements run at once and at most capacity settlements are
+// pending; Submit blocks once the queue is full. Settlements from the same sender
+// run one at a time, in submission order, so their nonces reach the node in order.
+type SettlementQueue struct {
+	settle  settleFunc
+	slots   chan struct{}
+	pending chan struct{}
+
+	mu      sync.Mutex
+	closed  bool
+	senders map[string]chan struct{}
+	wg      sync.WaitGroup
+}
+
+// newSettlementQueue creates a queue that settles through the given function
+func newSettlementQueue(settle settleFunc, concurrency, capacity int) *SettlementQueue {
+	if concurrency <= 0 {
+		concurrency = DefaultSettleConcurrency
+	}
+	if capacity < concurrency {
+		capacity = concurrency
+	}
+
+	return &SettlementQueue{
+		settle:  settle,
+		slots:   make(chan struct{}, concurrency),
+		pending: make(chan struct{}, capacity),
+		senders: make(map[string]chan struct{}),
+	}
+}
+
+// Submit enqueues a settlement and returns a channel that receives its result.
+// It blocks while the queue is full and fails if ctx is done before a place frees up.
+func (q *SettlementQueue) Submit(ctx context.Context, payload types.PaymentPayload, requirements types.PaymentRequirements) (<-chan SettleResult, error) {
+	select {
+	case q.pending <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		<-q.pending
+		return nil, ErrSettlementQueueClosed
+	}
+
+	sender := settlementSender(payload)
+	previous := q.senders[sender]
+	done := make(chan struct{})
+	q.senders[sender] = done
+	q.wg.Add(1)
+	q.mu.Unlock()
+
+	result := make(chan SettleResult, 1)
+	go func() {
+		defer q.wg.Done()
+		defer func() { <-q.pending }()
+		defer q.release(sender, done)
+
+		result <- q.run(ctx, previous, payload, requirements)
+	}()
+
+	return result, nil
+}
+
+// run waits for the previous settlement of the same sender and a free worker, then settles
+func (q *SettlementQueue) run(ctx context.Context, previous chan struct{}, payload types.PaymentPayload, requirements types.PaymentRequirements) SettleResult {
+	if previous != nil {
+		select {
+		case <-previous:
+		case <-ctx.Done():
+			return SettleResult{Err: ctx.Err()}
+		}
+	}
+
+	select {
+	case q.slots <- struct{}{}:
+	case <-ctx.Done():
+		return SettleResult{Err: ctx.Err()}
+	}
+	defer func() { <-q.slots }()
+
+	resp, err := q.settle(ctx, payload, requirements)
+	return SettleResult{Response: resp, Err: err}
+}
+
+// release unblocks the next settlement of the sender and forgets the sender once idle
+func (q *SettlementQueue) release(sender string, done chan struct{}) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	close(done)
+	if q.senders[sender] == done {
+		delete(q.senders, sender)
+	}
+}
+
+// Close stops accepting settlements and waits for the queued ones to finish
+func (q *SettlementQueue) Close() {
+	q.mu.Lock()
+	q.closed = true
+	q.mu.Unlock()
+
+	q.wg.Wait()
+}
+
+// settlementSender returns the key used to order settlements of the same sender
+func settlementSender(payload types.PaymentPayload) string {
+	relayedPayload, err := multiversx.PayloadFromMap(payload.Payload)
+	if err != nil {
+		return ""
+	}
+	return relayedPayload.Sender
+}
@@ -0,0 +1,150 @@
+package facilitator
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/mechanisms/multiversx"
+	"github.com/coinbase/x402/go/types"
+)
+
+func queuedPayload(sender string, nonce uint64) types.PaymentPayload {
+	relayed := multiversx.ExactRelayedPayload{Sender: sender, Nonce: nonce}
+	return types.PaymentPayload{Payload: relayed.ToMap()}
+}
+
+func TestSettlementQueue_BoundsConcurrency(t *testing.T) {
+	const concurrency = 3
+	const total = 20
+
+	var running, maxRunning int32
+	queue := newSettlementQueue(func(ctx context.Context, payload types.PaymentPayload, requirements types.PaymentRequirements) (*x402.SettleResponse, error) {
+		current := atomic.AddInt32(&running, 1)
+		for {
+			observed := atomic.LoadInt32(&maxRunning)
+			if current <= observed || atomic.CompareAndSwapInt32(&maxRunning, observed, current) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return &x402.SettleResponse{Success: true, Transaction: settlementSender(payload)}, nil
+	}, concurrency, total)
+
+	var results []<-chan SettleResult
+	for i := 0; i < total; i++ {
+		ch, err := queue.Submit(context.Background(), queuedPayload(fmt.Sprintf("sender-%d", i), 0), types.PaymentRequirements{})
+		if err != nil {
+			t.Fatalf("Submit() error = %v", err)
+		}
+		results = append(results, ch)
+	}
+
+	for i, ch := range results {
+		result := <-ch
+		if result.Err != nil {
+			t.Fatalf("settlement %d failed: %v", i, result.Err)
+		}
+		if want := fmt.Sprintf("sender-%d", i); result.Response.Transaction != want {
+			t.Errorf("settlement %d result = %s, want %s", i, result.Response.Transaction, want)
+		}
+	}
+
+	if maxRunning > concurrency {
+		t.Errorf("max concurrent settlements = %d, want <= %d", maxRunning, concurrency)
+	}
+	if maxRunning < 2 {
+		t.Errorf("max concurrent settlements = %d, expected settlements to run in parallel", maxRunning)
+	}
+}
+
+func TestSettlementQueue_OrdersPerSender(t *testing.T) {
+	var mu sync.Mutex
+	var order []uint64
+
+	queue := newSettlementQueue(func(ctx context.Context, payload types.PaymentPayload, requirements types.PaymentRequirements) (*x402.SettleResponse, error) {
+		relayed, _ := multiversx.PayloadFromMap(payload.Payload)
+		// Earlier nonces sleep longer, so any reordering would show up
+		time.Sleep(time.Duration(10-relayed.Nonce) * time.Millisecond)
+		mu.Lock()
+		order = append(order, relayed.Nonce)
+		mu.Unlock()
+		return &x402.SettleResponse{Success: true}, nil
+	}, 4, 16)
+
+	var results []<-chan SettleResult
+	for nonce := uint64(0); nonce < 5; nonce++ {
+		ch, err := queue.Submit(context.Background(), queuedPayload("erd1same", nonce), types.PaymentRequirements{})
+		if err != nil {
+			t.Fatalf("Submit() error = %v", err)
+		}
+		results = append(results, ch)
+	}
+	for _, ch := range results {
+		if result := <-ch; result.Err != nil {
+			t.Fatalf("settlement failed: %v", result.Err)
+		}
+	}
+
+	for i, nonce := range order {
+		if nonce != uint64(i) {
+			t.Fatalf("settlement order = %v, want ascending nonces", order)
+		}
+	}
+}
+
+func TestSettlementQueue_BlocksWhenFull(t *testing.T) {
+	release := make(chan struct{})
+	queue := newSettlementQueue(func(ctx context.Context, payload types.PaymentPayload, requirements types.PaymentRequirements) (*x402.SettleResponse, error) {
+		<-release
+		return &x402.SettleResponse{Success: true}, nil
+	}, 1, 1)
+
+	if _, err := queue.Submit(context.Background(), queuedPayload("erd1a", 0), types.PaymentRequirements{}); err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := queue.Submit(ctx, queuedPayload("erd1b", 0), types.PaymentRequirements{}); err != context.DeadlineExceeded {
+		t.Errorf("Submit() on full queue error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	close(release)
+	queue.Close()
+
+	if _, err := queue.Submit(context.Background(), queuedPayload("erd1c", 0), types.PaymentRequirements{}); err != ErrSettlementQueueClosed {
+		t.Errorf("Submit() after Close error = %v, want %v", err, ErrSettlementQueueClosed)
+	}
+}
+
+func TestSettle_ThroughQueue(t *testing.T) {
+	mockProxy := &MockProxy{sendHash: "queued-hash"}
+	scheme := &ExactMultiversXScheme{proxy: mockProxy, asyncSettle: true}
+	scheme.queue = newSettlementQueue(scheme.settle, 2, 4)
+	defer scheme.Close()
+
+	payload := queuedPayload("erd1sender", 1)
+	requirements := types.PaymentRequirements{Extra: map[string]interface{}{"assetTransferMethod": multiversx.TransferMethodDirect}}
+
+	resp, err := scheme.Settle(context.Background(), payload, requirements)
+	if err != nil {
+		t.Fatalf("Settle() error = %v", err)
+	}
+	if resp.Transaction != "queued-hash" {
+		t.Errorf("Settle() transaction = %s, want queued-hash", resp.Transaction)
+	}
+
+	results, err := scheme.SubmitSettle(context.Background(), queuedPayload("erd1sender", 2), requirements)
+	if err != nil {
+		t.Fatalf("SubmitSettle() error = %v", err)
+	}
+	if result := <-results; result.Err != nil || result.Response.Transaction != "queued-hash" {
+		t.Errorf("SubmitSettle() result = %+v", result)
+	}
+}
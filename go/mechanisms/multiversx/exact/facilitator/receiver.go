@@ -0,0 +1,37 @@
+package facilitator
+
+import (
+	"context"
+
+	"github.com/multiversx/mx-sdk-go/data"
+)
+
+// ReceiverInactiveExtraKey flags, in the VerifyResponse Extra, a receiver account without any activity
+// A valid but unused address is often a typo: the payment would still succeed, so this is only a
+// warning for the resource server to double-check the receiver.
+const ReceiverInactiveExtraKey = "receiverInactive"
+
+// WithReceiverActivityCheck makes Verify look up the receiver account and flag it when inactive
+// It costs one account lookup per verification; lookup failures leave the response unflagged.
+func WithReceiverActivityCheck() Option {
+	return func(s *ExactMultiversXScheme) {
+		s.receiverCheck = true
+	}
+}
+
+// receiverInactive reports whether the account has never sent a transaction, holds no EGLD,
+// has no code and no username
+func (s *ExactMultiversXScheme) receiverInactive(ctx context.Context, receiver string) bool {
+	addr, err := data.NewAddressFromBech32String(receiver)
+	if err != nil {
+		return false
+	}
+	account, err := s.proxy.GetAccount(ctx, addr)
+	if err != nil || account == nil {
+		return false
+	}
+	return account.Nonce == 0 &&
+		(account.Balance == "" || account.Balance == "0") &&
+		account.Code == "" &&
+		account.Username == ""
+}
@@ -0,0 +1,81 @@
+package facilitator
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/multiversx/mx-sdk-go/data"
+
+	"github.com/coinbase/x402/go/mechanisms/multiversx"
+	"github.com/coinbase/x402/go/types"
+)
+
+func TestVerify_FlagsInactiveReceiver(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":{"result":{"status":"success","hash":"sim_hash"}},"error":""}`))
+	}))
+	defer server.Close()
+
+	pubKey, privKey, _ := ed25519.GenerateKey(nil)
+	senderAddr, _ := data.NewAddressFromBytes(pubKey).AddressAsBech32String()
+	receiverAddr := "erd1qyu5wthldzr8wx5c9ucg8kjagg0jfs53s8nr3zpz3hypefsdd8ssycr6th"
+
+	payload := multiversx.ExactRelayedPayload{
+		Value:    "1000",
+		Receiver: receiverAddr,
+		Sender:   senderAddr,
+		GasPrice: 1000000000,
+		GasLimit: 50000,
+		ChainID:  "D",
+		Version:  1,
+	}
+	tx := payload.ToTransaction()
+	txBytes, _ := multiversx.SerializeTransaction(&tx)
+	payload.Signature = hex.EncodeToString(ed25519.Sign(privKey, txBytes))
+
+	req := types.PaymentRequirements{
+		PayTo:  receiverAddr,
+		Amount: "1000",
+		Asset:  multiversx.NativeTokenTicker,
+		Extra:  map[string]interface{}{"assetTransferMethod": multiversx.TransferMethodDirect},
+	}
+
+	tests := []struct {
+		name    string
+		check   bool
+		account *data.Account
+		want    bool
+	}{
+		{"fresh account", true, &data.Account{Address: receiverAddr, Balance: "0"}, true},
+		{"funded account", true, &data.Account{Address: receiverAddr, Balance: "5"}, false},
+		{"account with transactions", true, &data.Account{Address: receiverAddr, Nonce: 3, Balance: "0"}, false},
+		{"lookup unavailable", true, nil, false},
+		{"check disabled", false, &data.Account{Address: receiverAddr, Balance: "0"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts []Option
+			if tt.check {
+				opts = append(opts, WithReceiverActivityCheck())
+			}
+			scheme, _ := NewExactMultiversXScheme(server.URL, &MockSigner{}, opts...)
+			scheme.proxy = &MockProxy{account: tt.account}
+
+			resp, err := scheme.Verify(context.Background(), types.PaymentPayload{Payload: payload.ToMap()}, req)
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if !resp.IsValid {
+				t.Fatal("Expected an inactive receiver to remain valid")
+			}
+			if got := resp.Extra[ReceiverInactiveExtraKey] == true; got != tt.want {
+				t.Errorf("receiverInactive = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
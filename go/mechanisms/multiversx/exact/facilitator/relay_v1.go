@@ -0,0 +1,102 @@
+package facilitator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/multiversx/mx-chain-core-go/data/transaction"
+	"github.com/multiversx/mx-sdk-go/builders"
+	"github.com/multiversx/mx-sdk-go/data"
+)
+
+// RelayVersion selects how the facilitator relays the transactions it pays the gas of
+type RelayVersion int
+
+const (
+	// RelayV3 sets the relayer fields of the signed transaction and adds the relayer signature (default)
+	RelayV3 RelayVersion = 3
+	// RelayV1 wraps the signed transaction in a legacy "relayedTx@<inner tx>" transaction sent by the relayer
+	RelayV1 RelayVersion = 1
+)
+
+// WithRelayVersion selects the relayed transaction version used by Settle
+// RelayV1 is for older contracts that only accept relayedTxV1: GetExtra then advertises it in
+// Extra["relayVersion"] so clients sign a plain transaction, without the relayer fields of V3, and the
+// facilitator wraps it in a transaction signed by its first address, or the one picked by
+// WithRelayerSelector. Wrapper nonces are reserved in the nonce store like sender nonces.
+func WithRelayVersion(version RelayVersion) Option {
+	return func(s *ExactMultiversXScheme) {
+		s.relayVersion = version
+	}
+}
+
+// maxPendingRelayerNonces bounds how many wrapper nonces above the on-chain relayer nonce are searched
+const maxPendingRelayerNonces = 1000
+
+// wrapRelayedV1 wraps the signed inner transaction in a relayedTxV1 transaction signed by relayer
+// The wrapper nonce stays reserved; the caller releases it if the wrapper is never broadcast.
+func (s *ExactMultiversXScheme) wrapRelayedV1(ctx context.Context, inner *transaction.FrontendTransaction, relayer string) (*transaction.FrontendTransaction, error) {
+	if inner.RelayerAddr != "" {
+		return nil, fmt.Errorf("relayedTxV1 inner transactions must not set a relayer, got %s", inner.RelayerAddr)
+	}
+
+	networkConfig, err := s.proxy.GetNetworkConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch network config: %w", err)
+	}
+
+	nonce, err := s.reserveRelayerNonce(ctx, relayer)
+	if err != nil {
+		return nil, err
+	}
+	nonces, _ := s.stateStores()
+
+	wrapped, err := builders.NewRelayedTxV1Builder().
+		SetInnerTransaction(inner).
+		SetRelayerAccount(&data.Account{Address: relayer, Nonce: nonce}).
+		SetNetworkConfig(networkConfig).
+		Build()
+	if err != nil {
+		_ = nonces.release(context.WithoutCancel(ctx), relayer, nonce)
+		return nil, fmt.Errorf("failed to build relayedTxV1: %w", err)
+	}
+
+	// The wrapper has no relayer of its own: the facilitator signs it as its sender
+	signature, err := s.signer.Sign(ctx, wrapped)
+	if err != nil {
+		_ = nonces.release(context.WithoutCancel(ctx), relayer, nonce)
+		return nil, fmt.Errorf("failed to sign relayedTxV1: %w", err)
+	}
+	wrapped.Signature = signature
+	return wrapped, nil
+}
+
+// reserveRelayerNonce reserves the lowest free relayer nonce from the on-chain account nonce upwards
+// Wrappers still pending on the network keep their nonces reserved in the nonce store, so concurrent
+// settlements, and facilitators sharing the store, never sign two wrappers with the same nonce.
+func (s *ExactMultiversXScheme) reserveRelayerNonce(ctx context.Context, relayer string) (uint64, error) {
+	address, err := data.NewAddressFromBech32String(relayer)
+	if err != nil {
+		return 0, fmt.Errorf("invalid relayer address %s: %w", relayer, err)
+	}
+	account, err := s.proxy.GetAccount(ctx, address)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch relayer account: %w", err)
+	}
+	if account == nil {
+		return 0, errors.New("relayer account not found")
+	}
+
+	nonces, _ := s.stateStores()
+	for nonce := account.Nonce; nonce < account.Nonce+maxPendingRelayerNonces; nonce++ {
+		err := nonces.reserve(ctx, relayer, nonce)
+		if err == nil {
+			return nonce, nil
+		}
+		if !errors.Is(err, errNonceInUse) {
+			return 0, err
+		}
+	}
+	return 0, fmt.Errorf("relayer %s has %d pending wrappers", relayer, maxPendingRelayerNonces)
+}
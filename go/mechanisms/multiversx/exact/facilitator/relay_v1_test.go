@@ -0,0 +1,321 @@
+package facilitator
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/multiversx/mx-chain-core-go/data/transaction"
+	"github.com/multiversx/mx-sdk-go/core"
+	"github.com/multiversx/mx-sdk-go/data"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/mechanisms/multiversx"
+	"github.com/coinbase/x402/go/mechanisms/multiversx/exact/client"
+	"github.com/coinbase/x402/go/mechanisms/multiversx/exact/server"
+	mxsigners "github.com/coinbase/x402/go/signers/multiversx"
+	"github.com/coinbase/x402/go/types"
+)
+
+// localKeys is an ExternalSigner holding Ed25519 keys in memory
+type localKeys struct {
+	addresses []string
+	keys      map[string]ed25519.PrivateKey
+}
+
+func newLocalKeys(t *testing.T, n int) *localKeys {
+	t.Helper()
+	k := &localKeys{keys: make(map[string]ed25519.PrivateKey)}
+	for i := 0; i < n; i++ {
+		pubKey, privKey, err := ed25519.GenerateKey(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		address, _ := data.NewAddressFromBytes(pubKey).AddressAsBech32String()
+		k.addresses = append(k.addresses, address)
+		k.keys[address] = privKey
+	}
+	return k
+}
+
+func (k *localKeys) GetAddresses() []string {
+	return k.addresses
+}
+
+func (k *localKeys) SignBytes(ctx context.Context, address string, message []byte) ([]byte, error) {
+	key, ok := k.keys[address]
+	if !ok {
+		return nil, fmt.Errorf("unknown key %s", address)
+	}
+	return ed25519.Sign(key, message), nil
+}
+
+// signer exposes the keys as a facilitator signer, as a KMS would be
+func (k *localKeys) signer(t *testing.T) multiversx.FacilitatorMultiversXSigner {
+	t.Helper()
+	adapter, err := multiversx.NewExternalSignerAdapter(k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return adapter
+}
+
+// signPayload sets the sender signature of payload, whose sender must be one of the keys
+func (k *localKeys) signPayload(t *testing.T, payload *multiversx.ExactRelayedPayload) {
+	t.Helper()
+	msg, err := multiversx.SigningBytes(*payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := k.SignBytes(context.Background(), payload.Sender, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload.Signature = hex.EncodeToString(sig)
+}
+
+// capturingProxy records the transactions broadcast through it
+type capturingProxy struct {
+	*MockProxy
+	sent []*transaction.FrontendTransaction
+}
+
+func (p *capturingProxy) SendTransaction(ctx context.Context, tx *transaction.FrontendTransaction) (string, error) {
+	p.sent = append(p.sent, tx)
+	return p.MockProxy.SendTransaction(ctx, tx)
+}
+
+// accountsProxy serves the on-chain nonces of several accounts
+type accountsProxy struct {
+	*capturingProxy
+	nonces map[string]uint64
+}
+
+func (p *accountsProxy) GetAccount(ctx context.Context, address core.AddressHandler) (*data.Account, error) {
+	bech32, err := address.AddressAsBech32String()
+	if err != nil {
+		return nil, err
+	}
+	return &data.Account{Address: bech32, Nonce: p.nonces[bech32], Balance: "0"}, nil
+}
+
+// checkRelayedV1 checks that wrapped is a relayedTxV1 of inner signed by relayer
+func checkRelayedV1(t *testing.T, wrapped *transaction.FrontendTransaction, inner multiversx.ExactRelayedPayload, relayer string) {
+	t.Helper()
+
+	if wrapped.Sender != relayer || wrapped.Receiver != inner.Sender || wrapped.Value != inner.Value {
+		t.Errorf("wrapper = %s -> %s, value %s; want %s -> %s, value %s", wrapped.Sender, wrapped.Receiver, wrapped.Value, relayer, inner.Sender, inner.Value)
+	}
+	if wrapped.RelayerAddr != "" || wrapped.RelayerSignature != "" {
+		t.Errorf("wrapper relayer fields = %q/%q, want the relayer as plain signer", wrapped.RelayerAddr, wrapped.RelayerSignature)
+	}
+	if want := uint64(50000+1500*len(wrapped.Data)) + inner.GasLimit; wrapped.GasLimit != want {
+		t.Errorf("wrapper gas limit = %d, want %d", wrapped.GasLimit, want)
+	}
+
+	// The wrapper signature must be the relayer's over the canonical wrapper bytes
+	msg, err := multiversx.SigningBytes(multiversx.ExactRelayedPayload{
+		Nonce:    wrapped.Nonce,
+		Value:    wrapped.Value,
+		Receiver: wrapped.Receiver,
+		Sender:   wrapped.Sender,
+		GasPrice: wrapped.GasPrice,
+		GasLimit: wrapped.GasLimit,
+		Data:     string(wrapped.Data),
+		ChainID:  wrapped.ChainID,
+		Version:  wrapped.Version,
+		Options:  wrapped.Options,
+	})
+	if err != nil {
+		t.Fatalf("SigningBytes(wrapper) error = %v", err)
+	}
+	relayerAddr, _ := data.NewAddressFromBech32String(relayer)
+	sig, _ := hex.DecodeString(wrapped.Signature)
+	if !ed25519.Verify(relayerAddr.AddressBytes(), msg, sig) {
+		t.Errorf("wrapper signature %q is not the relayer signature of the wrapper", wrapped.Signature)
+	}
+
+	innerHex, ok := strings.CutPrefix(string(wrapped.Data), "relayedTx@")
+	if !ok {
+		t.Fatalf("wrapper data = %q, want relayedTx@<inner>", wrapped.Data)
+	}
+	innerJSON, err := hex.DecodeString(innerHex)
+	if err != nil {
+		t.Fatalf("inner transaction hex: %v", err)
+	}
+	var decoded transaction.Transaction
+	if err := json.Unmarshal(innerJSON, &decoded); err != nil {
+		t.Fatalf("inner transaction JSON: %v", err)
+	}
+
+	senderAddr, _ := data.NewAddressFromBech32String(inner.Sender)
+	receiverAddr, _ := data.NewAddressFromBech32String(inner.Receiver)
+	value, _ := new(big.Int).SetString(inner.Value, 10)
+	if decoded.Nonce != inner.Nonce || decoded.Value.Cmp(value) != 0 ||
+		!bytes.Equal(decoded.SndAddr, senderAddr.AddressBytes()) || !bytes.Equal(decoded.RcvAddr, receiverAddr.AddressBytes()) ||
+		decoded.GasPrice != inner.GasPrice || decoded.GasLimit != inner.GasLimit || string(decoded.Data) != inner.Data ||
+		string(decoded.ChainID) != inner.ChainID || decoded.Version != inner.Version || decoded.Options != inner.Options {
+		t.Errorf("inner transaction = %+v, want the signed payload %+v", decoded, inner)
+	}
+	if hex.EncodeToString(decoded.Signature) != inner.Signature {
+		t.Errorf("inner signature = %x, want the sender signature %s", decoded.Signature, inner.Signature)
+	}
+}
+
+func TestSettle_RelayV1(t *testing.T) {
+	senders := newLocalKeys(t, 1)
+	relayers := newLocalKeys(t, 1)
+	sender, relayer := senders.addresses[0], relayers.addresses[0]
+
+	networkConfig := &data.NetworkConfig{ChainID: "D", MinGasLimit: 50000, GasPerDataByte: 1500, MinTransactionVersion: 1}
+	proxy := &accountsProxy{
+		capturingProxy: &capturingProxy{MockProxy: &MockProxy{sendHash: "wrapped_hash", networkConfig: networkConfig}},
+		nonces:         map[string]uint64{relayer: 42},
+	}
+	scheme := &ExactMultiversXScheme{proxy: proxy, signer: relayers.signer(t), asyncSettle: true}
+	WithRelayVersion(RelayV1)(scheme)
+
+	requirements := types.PaymentRequirements{PayTo: relayer, Amount: "1000", Asset: multiversx.NativeTokenTicker}
+	settle := func(nonce uint64) (multiversx.ExactRelayedPayload, error) {
+		inner := multiversx.ExactRelayedPayload{
+			Nonce:    nonce,
+			Value:    "1000",
+			Receiver: relayer,
+			Sender:   sender,
+			GasPrice: 1000000000,
+			GasLimit: 100000,
+			ChainID:  "D",
+			Version:  multiversx.TxVersionRelayedV1Inner,
+		}
+		senders.signPayload(t, &inner)
+		_, err := scheme.Settle(context.Background(), types.PaymentPayload{Payload: inner.ToMap()}, requirements)
+		return inner, err
+	}
+
+	inner, err := settle(7)
+	if err != nil {
+		t.Fatalf("Settle() error = %v", err)
+	}
+	if len(proxy.sent) != 1 {
+		t.Fatalf("%d broadcasts, want 1", len(proxy.sent))
+	}
+	checkRelayedV1(t, proxy.sent[0], inner, relayer)
+
+	// The on-chain relayer nonce has not moved yet: the pending wrapper keeps 42 reserved
+	if _, err := settle(8); err != nil {
+		t.Fatalf("Settle() error = %v", err)
+	}
+	// A wrapper that fails to broadcast gives its nonce back
+	proxy.sendErr = errors.New("connection reset")
+	if _, err := settle(9); err == nil {
+		t.Fatal("Settle() with a failing gateway succeeded")
+	}
+	proxy.sendErr = nil
+	if _, err := settle(10); err != nil {
+		t.Fatalf("Settle() error = %v", err)
+	}
+
+	var wrapperNonces []uint64
+	for _, tx := range proxy.sent {
+		wrapperNonces = append(wrapperNonces, tx.Nonce)
+	}
+	if want := []uint64{42, 43, 44, 44}; fmt.Sprint(wrapperNonces) != fmt.Sprint(want) {
+		t.Errorf("wrapper nonces = %v, want %v", wrapperNonces, want)
+	}
+
+	// A payload signed for relayed V3 cannot be wrapped: its signature covers the relayer fields
+	v3 := inner
+	v3.Nonce = 11
+	v3.Relayer = relayer
+	v3.Version = multiversx.TxVersionRelayed
+	senders.signPayload(t, &v3)
+	_, err = scheme.Settle(context.Background(), types.PaymentPayload{Payload: v3.ToMap()}, requirements)
+	var sErr *x402.SettleError
+	if !errors.As(err, &sErr) || sErr.Reason != "relay_wrapping_failed" {
+		t.Errorf("Settle() of a V3 payload error = %v, want relay_wrapping_failed", err)
+	}
+}
+
+func TestRelayV1_ClientToFacilitator(t *testing.T) {
+	buyer, err := mxsigners.NewClientSignerFromPrivateKey("413f42575f7f26fad3317a778771212fdb80245850981e48b58a4f25e344e8f9")
+	if err != nil {
+		t.Fatal(err)
+	}
+	relayers := newLocalKeys(t, 1)
+	relayer := relayers.addresses[0]
+	payTo := "erd1spyavw0956vq68xj8y4tenjpq2wd5a9p2c6j8gsz7ztyrnpxrruqzu66jx"
+
+	simulator := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":{"result":{"status":"success","hash":"sim_hash"}},"code":"successful"}`))
+	}))
+	defer simulator.Close()
+
+	networkConfig := &data.NetworkConfig{ChainID: "D", MinGasLimit: 50000, GasPerDataByte: 1500, MinGasPrice: 1000000000, MinTransactionVersion: 1}
+	proxy := &accountsProxy{
+		capturingProxy: &capturingProxy{MockProxy: &MockProxy{sendHash: "wrapped_hash", networkConfig: networkConfig}},
+		nonces:         map[string]uint64{buyer.Address(): 5, relayer: 42},
+	}
+	facilitatorScheme := &ExactMultiversXScheme{
+		config:      multiversx.NetworkConfig{ApiUrl: simulator.URL},
+		proxy:       proxy,
+		signer:      relayers.signer(t),
+		asyncSettle: true,
+	}
+	WithRelayVersion(RelayV1)(facilitatorScheme)
+
+	// The resource server builds the requirements from what the facilitator advertises
+	network := x402.Network("multiversx:D")
+	kind := types.SupportedKind{X402Version: 2, Scheme: multiversx.SchemeExact, Network: string(network), Extra: facilitatorScheme.GetExtra(network)}
+	requirements, err := server.NewExactMultiversXScheme().EnhancePaymentRequirements(context.Background(), types.PaymentRequirements{
+		Scheme:  multiversx.SchemeExact,
+		Network: string(network),
+		PayTo:   payTo,
+		Amount:  "1000000",
+		Asset:   "USDC-c76f1f",
+	}, kind, nil)
+	if err != nil {
+		t.Fatalf("EnhancePaymentRequirements() error = %v", err)
+	}
+
+	clientScheme, err := client.NewExactMultiversXScheme(buyer, network, client.WithProxy(proxy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, err := clientScheme.CreatePaymentPayload(context.Background(), requirements)
+	if err != nil {
+		t.Fatalf("CreatePaymentPayload() error = %v", err)
+	}
+	signed, err := multiversx.PayloadFromMap(payload.Payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signed.Relayer != "" || signed.Version != multiversx.TxVersionRelayedV1Inner {
+		t.Fatalf("client signed relayer %q with version %d, want a plain version %d transaction", signed.Relayer, signed.Version, multiversx.TxVersionRelayedV1Inner)
+	}
+
+	verified, err := facilitatorScheme.Verify(context.Background(), payload, requirements)
+	if err != nil || !verified.IsValid {
+		t.Fatalf("Verify() = %+v, %v", verified, err)
+	}
+	settled, err := facilitatorScheme.Settle(context.Background(), payload, requirements)
+	if err != nil || !settled.Success {
+		t.Fatalf("Settle() = %+v, %v", settled, err)
+	}
+
+	if len(proxy.sent) != 1 {
+		t.Fatalf("%d broadcasts, want 1", len(proxy.sent))
+	}
+	if proxy.sent[0].Nonce != 42 {
+		t.Errorf("wrapper nonce = %d, want the relayer nonce 42", proxy.sent[0].Nonce)
+	}
+	checkRelayedV1(t, proxy.sent[0], *signed, relayer)
+}
@@ -0,0 +1,51 @@
+package facilitator
+
+import (
+	"slices"
+	"sync/atomic"
+)
+
+// RelayerSelector picks the facilitator address relaying a settlement among the signer addresses
+// It is called with the non-empty list returned by GetAddresses, once per RelayV1 settlement and,
+// for relayed V3, once per GetExtra call publishing the relayer clients sign for.
+type RelayerSelector func(addresses []string) string
+
+// WithRelayerSelector sets how the facilitator picks the relayer among the signer addresses, to spread
+// the relayed gas fees over several wallets. RelayV1 wrappers are signed by the selected address; for
+// relayed V3 the sender signs the relayer, so GetExtra publishes the selected address in Extra["relayer"]
+// and Settle relays through the one the payload names, which must be a signer address.
+// By default the first address relays.
+func WithRelayerSelector(selector RelayerSelector) Option {
+	return func(s *ExactMultiversXScheme) {
+		s.relayerSelector = selector
+	}
+}
+
+// RoundRobinRelayers returns a RelayerSelector cycling through the signer addresses
+func RoundRobinRelayers() RelayerSelector {
+	var next atomic.Uint64
+	return func(addresses []string) string {
+		return addresses[(next.Add(1)-1)%uint64(len(addresses))]
+	}
+}
+
+// selectRelayer returns the address relaying the next settlement
+func (s *ExactMultiversXScheme) selectRelayer(addresses []string) string {
+	if s.relayerSelector == nil {
+		return addresses[0]
+	}
+	return s.relayerSelector(addresses)
+}
+
+// publishedRelayer selects the relayer GetExtra advertises to the clients of relayed V3 payments
+func (s *ExactMultiversXScheme) publishedRelayer() (string, bool) {
+	if s.signer == nil {
+		return "", false
+	}
+	addresses := s.signer.GetAddresses()
+	if len(addresses) == 0 {
+		return "", false
+	}
+	relayer := s.selectRelayer(addresses)
+	return relayer, slices.Contains(addresses, relayer)
+}
@@ -0,0 +1,147 @@
+package facilitator
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/multiversx/mx-sdk-go/data"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/mechanisms/multiversx"
+	"github.com/coinbase/x402/go/mechanisms/multiversx/exact/client"
+	"github.com/coinbase/x402/go/mechanisms/multiversx/exact/server"
+	mxsigners "github.com/coinbase/x402/go/signers/multiversx"
+	"github.com/coinbase/x402/go/types"
+)
+
+func TestWithRelayerSelector_RoundRobin(t *testing.T) {
+	senders := newLocalKeys(t, 1)
+	relayers := newLocalKeys(t, 2)
+	sender, first, second := senders.addresses[0], relayers.addresses[0], relayers.addresses[1]
+
+	networkConfig := &data.NetworkConfig{ChainID: "D", MinGasLimit: 50000, GasPerDataByte: 1500, MinTransactionVersion: 1}
+	proxy := &accountsProxy{capturingProxy: &capturingProxy{MockProxy: &MockProxy{sendHash: "wrapped_hash", networkConfig: networkConfig}}}
+	scheme := &ExactMultiversXScheme{proxy: proxy, signer: relayers.signer(t), asyncSettle: true}
+	WithRelayVersion(RelayV1)(scheme)
+	WithRelayerSelector(RoundRobinRelayers())(scheme)
+
+	for nonce := uint64(1); nonce <= 3; nonce++ {
+		inner := multiversx.ExactRelayedPayload{
+			Nonce:    nonce,
+			Value:    "1000",
+			Receiver: first,
+			Sender:   sender,
+			GasPrice: 1000000000,
+			GasLimit: 100000,
+			ChainID:  "D",
+			Version:  1,
+		}
+		senders.signPayload(t, &inner)
+		requirements := types.PaymentRequirements{PayTo: first, Amount: "1000", Asset: multiversx.NativeTokenTicker}
+		if _, err := scheme.Settle(context.Background(), types.PaymentPayload{Payload: inner.ToMap()}, requirements); err != nil {
+			t.Fatalf("Settle(nonce %d) error = %v", nonce, err)
+		}
+	}
+
+	want := []string{first, second, first}
+	if len(proxy.sent) != len(want) {
+		t.Fatalf("%d broadcasts, want %d", len(proxy.sent), len(want))
+	}
+	for i, tx := range proxy.sent {
+		if tx.Sender != want[i] {
+			t.Errorf("settlement %d relayed by %s, want %s", i, tx.Sender, want[i])
+		}
+	}
+}
+
+func TestWithRelayerSelector_RoundRobinRelayedV3(t *testing.T) {
+	buyer, err := mxsigners.NewClientSignerFromPrivateKey("413f42575f7f26fad3317a778771212fdb80245850981e48b58a4f25e344e8f9")
+	if err != nil {
+		t.Fatal(err)
+	}
+	relayers := newLocalKeys(t, 2)
+	first, second := relayers.addresses[0], relayers.addresses[1]
+
+	networkConfig := &data.NetworkConfig{ChainID: "D", MinGasPrice: 1000000000}
+	proxy := &accountsProxy{
+		capturingProxy: &capturingProxy{MockProxy: &MockProxy{sendHash: "relayed_hash", networkConfig: networkConfig}},
+		nonces:         map[string]uint64{buyer.Address(): 5},
+	}
+	scheme := &ExactMultiversXScheme{proxy: proxy, signer: relayers.signer(t), asyncSettle: true}
+	WithRelayerSelector(RoundRobinRelayers())(scheme)
+
+	network := x402.Network("multiversx:D")
+	clientScheme, err := client.NewExactMultiversXScheme(buyer, network, client.WithProxy(proxy))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Each payment is built from the supported kind the facilitator advertises at that time
+	for i := 0; i < 2; i++ {
+		kind := types.SupportedKind{X402Version: 2, Scheme: multiversx.SchemeExact, Network: string(network), Extra: scheme.GetExtra(network)}
+		requirements, err := server.NewExactMultiversXScheme().EnhancePaymentRequirements(context.Background(), types.PaymentRequirements{
+			Scheme:  multiversx.SchemeExact,
+			Network: string(network),
+			PayTo:   "erd1spyavw0956vq68xj8y4tenjpq2wd5a9p2c6j8gsz7ztyrnpxrruqzu66jx",
+			Amount:  "1000000",
+			Asset:   "USDC-c76f1f",
+		}, kind, nil)
+		if err != nil {
+			t.Fatalf("EnhancePaymentRequirements() error = %v", err)
+		}
+		payload, err := clientScheme.CreatePaymentPayload(context.Background(), requirements)
+		if err != nil {
+			t.Fatalf("CreatePaymentPayload() error = %v", err)
+		}
+		if _, err := scheme.Settle(context.Background(), payload, requirements); err != nil {
+			t.Fatalf("Settle() error = %v", err)
+		}
+		proxy.nonces[buyer.Address()]++
+	}
+
+	want := []string{first, second}
+	if len(proxy.sent) != len(want) {
+		t.Fatalf("%d broadcasts, want %d", len(proxy.sent), len(want))
+	}
+	for i, tx := range proxy.sent {
+		if tx.Sender != buyer.Address() || tx.RelayerAddr != want[i] {
+			t.Errorf("settlement %d sent by %s relayed by %s, want %s relayed by %s", i, tx.Sender, tx.RelayerAddr, buyer.Address(), want[i])
+		}
+		payload := multiversx.ExactRelayedPayload{
+			Nonce:            tx.Nonce,
+			Value:            tx.Value,
+			Receiver:         tx.Receiver,
+			Sender:           tx.Sender,
+			GasPrice:         tx.GasPrice,
+			GasLimit:         tx.GasLimit,
+			Data:             string(tx.Data),
+			ChainID:          tx.ChainID,
+			Version:          tx.Version,
+			Options:          tx.Options,
+			Relayer:          tx.RelayerAddr,
+			Signature:        tx.Signature,
+			RelayerSignature: tx.RelayerSignature,
+		}
+		if ok, err := multiversx.VerifyRelayerSignature(payload); !ok || err != nil {
+			t.Errorf("settlement %d relayer signature does not verify: %v", i, err)
+		}
+	}
+}
+
+func TestWithRelayerSelector_RejectsUnknownRelayer(t *testing.T) {
+	proxy := &capturingProxy{MockProxy: &MockProxy{sendHash: "wrapped_hash"}}
+	scheme := &ExactMultiversXScheme{proxy: proxy, signer: newLocalKeys(t, 1).signer(t), asyncSettle: true}
+	WithRelayVersion(RelayV1)(scheme)
+	WithRelayerSelector(func([]string) string { return "erd1other" })(scheme)
+
+	inner := multiversx.ExactRelayedPayload{Sender: "erd1sender", Nonce: 1, ChainID: "D", Version: 1}
+	_, err := scheme.Settle(context.Background(), types.PaymentPayload{Payload: inner.ToMap()}, types.PaymentRequirements{})
+	var sErr *x402.SettleError
+	if !errors.As(err, &sErr) || sErr.Reason != "relayer_mismatch" {
+		t.Fatalf("Settle() error = %v, want relayer_mismatch", err)
+	}
+	if len(proxy.sent) != 0 {
+		t.Errorf("%d transactions broadcast through an unknown relayer", len(proxy.sent))
+	}
+}
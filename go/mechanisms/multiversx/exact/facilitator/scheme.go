@@ -3,20 +3,22 @@ package facilitator
 import (
 	"bytes"
 	"context"
-	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"math/big"
+	"math/rand/v2"
 	"net/http"
-	"strings"
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/multiversx/mx-chain-core-go/data/transaction"
 	"github.com/multiversx/mx-sdk-go/blockchain"
 	"github.com/multiversx/mx-sdk-go/core"
 	"github.com/multiversx/mx-sdk-go/data"
+	"golang.org/x/sync/singleflight"
 
 	"github.com/coinbase/x402/go/mechanisms/multiversx"
 
@@ -30,18 +32,218 @@ type Proxy interface {
 	GetTransactionInfo(ctx context.Context, hash string) (*data.TransactionInfo, error)
 	GetTransactionInfoWithResults(ctx context.Context, hash string) (*data.TransactionInfo, error)
 	GetAccount(ctx context.Context, address core.AddressHandler) (*data.Account, error)
+	GetNetworkConfig(ctx context.Context) (*data.NetworkConfig, error)
 	SendTransaction(ctx context.Context, tx *transaction.FrontendTransaction) (string, error)
 }
 
+const (
+	// DefaultSettleTimeout is how long Settle waits for the transaction to complete
+	DefaultSettleTimeout = 120 * time.Second
+	// DefaultPollInterval is the first delay between transaction status polls in Settle
+	DefaultPollInterval = 500 * time.Millisecond
+	// DefaultMaxPollInterval caps the exponentially growing delay between status polls
+	DefaultMaxPollInterval = 5 * time.Second
+	// DefaultResultLookups is how many times Settle reads the results of a successful transaction
+	// before giving up and reporting result_unknown
+	DefaultResultLookups = 3
+	// DefaultSimulationAttempts is how many times a simulation is attempted on network errors or 5xx responses
+	DefaultSimulationAttempts = 3
+
+	// DefaultHTTPTimeout bounds each request the facilitator sends to the gateway API
+	DefaultHTTPTimeout = 30 * time.Second
+
+	// simulationRetryBackoff is the first delay between simulation attempts, doubled after each retry
+	simulationRetryBackoff = 200 * time.Millisecond
+)
+
 // ExactMultiversXScheme implements SchemeNetworkFacilitator
 type ExactMultiversXScheme struct {
-	config multiversx.NetworkConfig
-	proxy  Proxy
-	signer multiversx.FacilitatorMultiversXSigner
+	config              multiversx.NetworkConfig
+	proxy               Proxy
+	signer              multiversx.FacilitatorMultiversXSigner
+	nodeErrorMappings   []multiversx.NodeErrorMapping
+	settleTimeout       time.Duration
+	pollInterval        time.Duration
+	maxPollInterval     time.Duration
+	asyncSettle         bool
+	clock               func() time.Time
+	supportedVersions   []int
+	simulationAttempts  int
+	httpClient          *http.Client
+	maxGasPriceMultiple uint64
+	batchConcurrency    int
+	herotagResolver     HerotagResolver
+	settleQueue         settleQueueConfig
+	queue               *SettlementQueue
+	nonceStore          NonceStore
+	settledStore        SettledStore
+	replayStore         ReplayStore
+	apiOverrides        map[string]string
+	proofSigner         multiversx.ExternalSigner
+	receiverCheck       bool
+	feeConverter        multiversx.FeeConverter
+	balanceCheck        bool
+	hints               bool
+	idempotencyStore    IdempotencyStore
+	relayVersion        RelayVersion
+	settleHooks         []SettleHook
+	metrics             MetricsSink
+	relayerSelector     RelayerSelector
+	selfPaymentGuard    bool
+
+	// storesOnce creates the in-memory stores and the nonce manager on first use
+	storesOnce sync.Once
+	nonces     *nonceManager
+
+	// herotags caches herotag PayTo resolutions
+	herotags sync.Map
+
+	// sleep waits between status polls and simulation retries; replaced in tests to observe the backoff
+	sleep func(ctx context.Context, d time.Duration) error
+
+	// simulations deduplicates concurrent simulations of the same payload
+	simulations singleflight.Group
+
+	// settleLocks serializes the settlements sharing an idempotency key
+	settleLocks keyLocks
+}
+
+// Option defines functional options for ExactMultiversXScheme
+type Option func(*ExactMultiversXScheme)
+
+// WithNodeErrorMappings overrides how node error messages are translated into typed x402 errors
+func WithNodeErrorMappings(mappings []multiversx.NodeErrorMapping) Option {
+	return func(s *ExactMultiversXScheme) {
+		s.nodeErrorMappings = mappings
+	}
+}
+
+// WithSettleTimeout overrides how long Settle waits for the transaction to complete
+func WithSettleTimeout(d time.Duration) Option {
+	return func(s *ExactMultiversXScheme) {
+		s.settleTimeout = d
+	}
+}
+
+// WithPollInterval overrides the first delay between transaction status polls in Settle
+// The delay doubles after every poll, with jitter, up to the max poll interval.
+func WithPollInterval(d time.Duration) Option {
+	return func(s *ExactMultiversXScheme) {
+		s.pollInterval = d
+	}
+}
+
+// WithMaxPollInterval overrides the cap of the delay between transaction status polls
+func WithMaxPollInterval(d time.Duration) Option {
+	return func(s *ExactMultiversXScheme) {
+		s.maxPollInterval = d
+	}
+}
+
+// WithAsyncSettle makes Settle return right after broadcasting the transaction
+// The response is marked pending in Extra["pending"] and does not guarantee finality:
+// callers must poll GetSettlementStatus until it reports success or failure.
+func WithAsyncSettle() Option {
+	return func(s *ExactMultiversXScheme) {
+		s.asyncSettle = true
+	}
+}
+
+// WithSupportedVersions overrides the x402 protocol versions Verify accepts and GetExtra advertises
+func WithSupportedVersions(versions ...int) Option {
+	return func(s *ExactMultiversXScheme) {
+		s.supportedVersions = versions
+	}
+}
+
+// WithSimulationRetries overrides how many times a simulation is attempted when the API is unreachable
+// or answers with a 5xx status. Explicit rejections (4xx, simulation errors) are never retried.
+func WithSimulationRetries(attempts int) Option {
+	return func(s *ExactMultiversXScheme) {
+		s.simulationAttempts = attempts
+	}
+}
+
+// WithHTTPClient sets the HTTP client used for the gateway simulation and transaction endpoints
+// Use it to configure timeouts, proxies or TLS for private gateways.
+func WithHTTPClient(client *http.Client) Option {
+	return func(s *ExactMultiversXScheme) {
+		s.httpClient = client
+	}
+}
+
+// WithAPIURLOverride sends the simulations and transaction lookups of payloads signed for chainID to url
+// Other chains keep using the gateway given to NewExactMultiversXScheme, through which transactions
+// are always broadcast and polled.
+func WithAPIURLOverride(chainID string, url string) Option {
+	return func(s *ExactMultiversXScheme) {
+		if s.apiOverrides == nil {
+			s.apiOverrides = make(map[string]string)
+		}
+		s.apiOverrides[chainID] = url
+	}
+}
+
+// WithGasPriceBounds makes Verify and Settle check the payload gas price against the current network
+// minimum gas price: payloads below it are rejected as the node would refuse them, and payloads above
+// maxMultiple times the minimum are rejected to protect the relayer from fee draining.
+func WithGasPriceBounds(maxMultiple uint64) Option {
+	return func(s *ExactMultiversXScheme) {
+		s.maxGasPriceMultiple = maxMultiple
+	}
+}
+
+// WithNonceStore persists the reserved sender nonces in the given store instead of memory
+func WithNonceStore(store NonceStore) Option {
+	return func(s *ExactMultiversXScheme) {
+		s.nonceStore = store
+	}
+}
+
+// WithSettledStore persists the settled payloads in the given store instead of memory
+// With a persistent store a restarted facilitator still refuses to settle a payload twice.
+func WithSettledStore(store SettledStore) Option {
+	return func(s *ExactMultiversXScheme) {
+		s.settledStore = store
+	}
+}
+
+// WithReplayStore records settled (sender, nonce, chainID) payments in the given store instead of memory
+// Verify and Settle reject payments found in the store as replayed.
+func WithReplayStore(store ReplayStore) Option {
+	return func(s *ExactMultiversXScheme) {
+		s.replayStore = store
+	}
+}
+
+// WithClock overrides the time source used to check payment validity windows
+func WithClock(clock func() time.Time) Option {
+	return func(s *ExactMultiversXScheme) {
+		s.clock = clock
+	}
+}
+
+// settleQueueConfig holds the settlement queue limits requested through WithSettlementQueue
+type settleQueueConfig struct {
+	enabled     bool
+	concurrency int
+	capacity    int
+}
+
+// WithSettlementQueue routes Settle through a bounded settlement queue
+// At most concurrency settlements are broadcast at once, at most capacity wait in the queue,
+// and settlements of the same sender are processed in order. Non-positive values use the defaults.
+func WithSettlementQueue(concurrency, capacity int) Option {
+	return func(s *ExactMultiversXScheme) {
+		if capacity <= 0 {
+			capacity = DefaultSettleQueueCapacity
+		}
+		s.settleQueue = settleQueueConfig{enabled: true, concurrency: concurrency, capacity: capacity}
+	}
 }
 
 // NewExactMultiversXScheme creates a new facilitator scheme instance
-func NewExactMultiversXScheme(apiUrl string, signer multiversx.FacilitatorMultiversXSigner) (*ExactMultiversXScheme, error) {
+func NewExactMultiversXScheme(apiUrl string, signer multiversx.FacilitatorMultiversXSigner, opts ...Option) (*ExactMultiversXScheme, error) {
 	args := blockchain.ArgsProxy{
 		ProxyURL:            apiUrl,
 		Client:              nil,
@@ -61,11 +263,25 @@ func NewExactMultiversXScheme(apiUrl string, signer multiversx.FacilitatorMultiv
 		return nil, fmt.Errorf("proxy does not implement the required interface")
 	}
 
-	return &ExactMultiversXScheme{
-		config: multiversx.NetworkConfig{ApiUrl: apiUrl},
-		proxy:  p,
-		signer: signer,
-	}, nil
+	s := &ExactMultiversXScheme{
+		config:             multiversx.NetworkConfig{ApiUrl: apiUrl},
+		proxy:              p,
+		signer:             signer,
+		nodeErrorMappings:  multiversx.DefaultNodeErrorMappings,
+		settleTimeout:      DefaultSettleTimeout,
+		pollInterval:       DefaultPollInterval,
+		maxPollInterval:    DefaultMaxPollInterval,
+		simulationAttempts: DefaultSimulationAttempts,
+		httpClient:         &http.Client{Timeout: DefaultHTTPTimeout},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.settleQueue.enabled {
+		s.queue = newSettlementQueue(s.settle, s.settleQueue.concurrency, s.settleQueue.capacity)
+	}
+
+	return s, nil
 }
 
 // Scheme returns the scheme identifier ("exact")
@@ -78,9 +294,46 @@ func (s *ExactMultiversXScheme) CaipFamily() string {
 	return "multiversx:*"
 }
 
-// GetExtra returns any extra configuration (none for this scheme)
+// GetExtra advertises the x402 protocol versions and the asset transfer methods this facilitator accepts
+// With RelayV1 it also asks clients, through Extra["relayVersion"], for plain transactions to wrap;
+// otherwise it publishes in Extra["relayer"] the relayer picked for the next relayed V3 payments.
 func (s *ExactMultiversXScheme) GetExtra(network x402.Network) map[string]interface{} {
-	return nil
+	extra := map[string]interface{}{
+		multiversx.ExtraX402Versions: s.versions(),
+		"assetTransferMethods":       multiversx.TransferMethods(),
+	}
+	if s.relayVersion == RelayV1 {
+		extra[multiversx.ExtraRelayVersion] = int(RelayV1)
+	} else if relayer, ok := s.publishedRelayer(); ok {
+		extra["relayer"] = relayer
+	}
+	return extra
+}
+
+// SupportedKinds describes every MultiversX network and x402 version this facilitator settles
+// The facilitator GetSupported reports the same kinds for the networks the scheme is registered on;
+// register it on multiversx.Networks to advertise all of them.
+func (s *ExactMultiversXScheme) SupportedKinds() []types.SupportedKind {
+	kinds := make([]types.SupportedKind, 0, len(multiversx.Networks)*len(s.versions()))
+	for _, network := range multiversx.Networks {
+		for _, version := range s.versions() {
+			kinds = append(kinds, types.SupportedKind{
+				X402Version: version,
+				Scheme:      s.Scheme(),
+				Network:     string(network),
+				Extra:       s.GetExtra(network),
+			})
+		}
+	}
+	return kinds
+}
+
+// versions returns the accepted x402 protocol versions
+func (s *ExactMultiversXScheme) versions() []int {
+	if len(s.supportedVersions) > 0 {
+		return s.supportedVersions
+	}
+	return multiversx.SupportedX402Versions
 }
 
 // GetSigners returns the addresses of available signers
@@ -93,188 +346,640 @@ func (s *ExactMultiversXScheme) GetSigners(network x402.Network) []string {
 
 // Verify validates a payment payload against requirements
 func (s *ExactMultiversXScheme) Verify(ctx context.Context, payload types.PaymentPayload, requirements types.PaymentRequirements) (*x402.VerifyResponse, error) {
+	resp, err := s.verify(ctx, payload, requirements)
+	s.sink().IncVerify(err == nil && resp.IsValid)
+	if err != nil {
+		return nil, s.withRemediationHint(err, payload)
+	}
+	return resp, nil
+}
+
+func (s *ExactMultiversXScheme) verify(ctx context.Context, payload types.PaymentPayload, requirements types.PaymentRequirements) (*x402.VerifyResponse, error) {
+	// A zero version means the wrapper was built without one; the core routes by version before reaching the scheme
+	if payload.X402Version != 0 && !multiversx.ContainsX402Version(s.versions(), payload.X402Version) {
+		return nil, x402.NewVerifyError(multiversx.ErrCodeUnsupportedVersion, "", "multiversx", fmt.Errorf("version mismatch: payload declares x402 version %d, supported %v", payload.X402Version, s.versions()))
+	}
+
 	relayedPayloadPtr, err := multiversx.PayloadFromMap(payload.Payload)
 	if err != nil {
 		return nil, x402.NewVerifyError(x402.ErrCodeInvalidPayment, "", "multiversx", fmt.Errorf("invalid payload format: %v", err))
 	}
 	relayedPayload := *relayedPayloadPtr
+	if s.proxy == nil && s.verifyNeedsProxy() {
+		return nil, x402.NewVerifyError("configuration_error", relayedPayload.Sender, "multiversx", errNoProxy)
+	}
 
-	isValid, err := multiversx.VerifyPayment(ctx, relayedPayload, requirements, s.verifyViaSimulation)
+	// Reject malformed addresses before any signature or simulation work
+	if !multiversx.IsValidAddress(relayedPayload.Sender) {
+		return nil, x402.NewVerifyError(multiversx.ErrCodeInvalidSender, relayedPayload.Sender, "multiversx", fmt.Errorf("invalid sender address: %s", relayedPayload.Sender))
+	}
+	if !multiversx.IsValidAddress(relayedPayload.Receiver) {
+		return nil, x402.NewVerifyError(multiversx.ErrCodeInvalidAddress, relayedPayload.Sender, "multiversx", fmt.Errorf("invalid receiver address: %s", relayedPayload.Receiver))
+	}
+
+	if err := s.checkSelfPayment(relayedPayload); err != nil {
+		return nil, err
+	}
+
+	// Malformed signatures and values are rejected with their precise reason before any network call
+	if err := multiversx.ValidateSignatureFormat(relayedPayload); err != nil {
+		return nil, err
+	}
+	if err := multiversx.ValidateValueFormat(relayedPayload); err != nil {
+		return nil, err
+	}
+	if err := checkChainID(relayedPayload, requirements); err != nil {
+		return nil, err
+	}
+
+	requirements, err = s.resolveRequirements(ctx, requirements)
 	if err != nil {
 		return nil, err
 	}
-	if !isValid {
-		return nil, x402.NewVerifyError(x402.ErrCodeSignatureInvalid, relayedPayload.Sender, "multiversx", nil)
+
+	if err := s.checkReplay(ctx, relayedPayload); err != nil {
+		return nil, x402.NewVerifyError(multiversx.ErrCodePaymentReplayed, relayedPayload.Sender, "multiversx", err)
 	}
 
-	now := uint64(time.Now().Unix())
+	// Check the validity window before simulating: scheduled payments are rejected until validAfter
+	now := uint64(s.now().Unix())
 	if relayedPayload.ValidBefore > 0 && now > relayedPayload.ValidBefore {
-		return nil, fmt.Errorf("payment expired (validBefore: %d, now: %d)", relayedPayload.ValidBefore, now)
+		return nil, x402.NewVerifyError(x402.ErrCodePaymentExpired, relayedPayload.Sender, "multiversx", fmt.Errorf("payment expired (validBefore: %d, now: %d)", relayedPayload.ValidBefore, now))
 	}
 	if relayedPayload.ValidAfter > 0 && now < relayedPayload.ValidAfter {
-		return nil, fmt.Errorf("payment not yet valid (validAfter: %d, now: %d)", relayedPayload.ValidAfter, now)
+		return nil, x402.NewVerifyError(multiversx.ErrCodePaymentNotYetValid, relayedPayload.Sender, "multiversx", fmt.Errorf("payment not yet valid (validAfter: %d, now: %d)", relayedPayload.ValidAfter, now))
 	}
 
-	expectedReceiver := requirements.PayTo
-	expectedAmount := requirements.Amount
-	if expectedAmount == "" {
-		return nil, errors.New("requirement amount is empty")
+	if reason, err := s.checkGasPrice(ctx, relayedPayload); err != nil {
+		return nil, x402.NewVerifyError(reason, relayedPayload.Sender, "multiversx", err)
 	}
 
-	reqAsset := requirements.Asset
-	if reqAsset == "" {
-		return nil, errors.New("requirement asset is required")
+	if err := s.checkBalance(ctx, relayedPayload); err != nil {
+		return nil, err
 	}
 
-	txData := relayedPayload
-	transferMethod, _ := requirements.Extra["assetTransferMethod"].(string)
+	if requirements.Amount == "" {
+		return nil, x402.NewVerifyError(multiversx.ErrCodeInvalidRequirements, relayedPayload.Sender, "multiversx", errors.New("requirement amount is empty"))
+	}
 
-	if reqAsset == multiversx.NativeTokenTicker && transferMethod != multiversx.TransferMethodESDT {
-		if txData.Receiver != expectedReceiver {
-			return nil, fmt.Errorf("receiver mismatch: expected %s, got %s", expectedReceiver, txData.Receiver)
-		}
-		if !multiversx.CheckBigInt(txData.Value, expectedAmount) {
-			return nil, fmt.Errorf("amount mismatch: expected %s, got %s", expectedAmount, txData.Value)
-		}
-	} else {
-		parts := strings.Split(txData.Data, "@")
-		if len(parts) < 6 || parts[0] != "MultiESDTNFTTransfer" {
-			return nil, errors.New("invalid ESDT transfer data format (expected MultiESDTNFTTransfer)")
-		}
+	if requirements.Asset == "" {
+		return nil, x402.NewVerifyError(multiversx.ErrCodeInvalidRequirements, relayedPayload.Sender, "multiversx", errors.New("requirement asset is required"))
+	}
 
-		destHex := parts[1]
-		if !multiversx.IsValidHex(destHex) {
-			return nil, fmt.Errorf("invalid receiver hex")
-		}
+	// VerifyPayment also checks the transfer against the requirements before simulating
+	isValid, err := multiversx.VerifyPayment(ctx, relayedPayload, requirements, s.verifyViaSimulation)
+	if err != nil {
+		return nil, err
+	}
+	if !isValid {
+		return nil, x402.NewVerifyError(x402.ErrCodeSignatureInvalid, relayedPayload.Sender, "multiversx", nil)
+	}
 
-		expectedAddr, err := data.NewAddressFromBech32String(expectedReceiver)
-		if err != nil {
-			return nil, fmt.Errorf("invalid expected receiver format: %v", err)
-		}
-		expectedHex := hex.EncodeToString(expectedAddr.AddressBytes())
+	if err := s.checkFeeCoverage(ctx, relayedPayload, requirements); err != nil {
+		return nil, err
+	}
 
-		if destHex != expectedHex {
-			return nil, fmt.Errorf("receiver mismatch: encoded destination %s does not match requirement %s", destHex, expectedReceiver)
-		}
+	resp := verifiedResponse(relayedPayload, requirements)
+	// The transfer matched, so PayTo is the actual destination, also for ESDT transfers sent to self
+	if s.receiverCheck && s.receiverInactive(ctx, requirements.PayTo) {
+		resp.Extra[ReceiverInactiveExtraKey] = true
+	}
+	return resp, nil
+}
+
+// VerifyAgainstAny verifies the payload against each of the offered requirements in order
+// It returns the response of the first requirement the payload satisfies along with its index.
+// Requirements whose transfer does not match the payload are skipped without simulating it;
+// once a transfer matches, a failed verification is returned along with that requirement's index.
+func (s *ExactMultiversXScheme) VerifyAgainstAny(ctx context.Context, payload types.PaymentPayload, requirements []types.PaymentRequirements) (*x402.VerifyResponse, int, error) {
+	relayedPayload, err := multiversx.PayloadFromMap(payload.Payload)
+	if err != nil {
+		return nil, -1, x402.NewVerifyError(x402.ErrCodeInvalidPayment, "", "multiversx", fmt.Errorf("invalid payload format: %v", err))
+	}
 
-		tokenBytes, err := hex.DecodeString(parts[3])
+	var mismatches []error
+	for i, req := range requirements {
+		req, err := s.resolveRequirements(ctx, req)
 		if err != nil {
-			return nil, fmt.Errorf("invalid token hex")
+			mismatches = append(mismatches, fmt.Errorf("requirement %d: %w", i, err))
+			continue
 		}
-		if string(tokenBytes) != reqAsset {
-			return nil, fmt.Errorf("asset mismatch: expected %s, got %s", reqAsset, string(tokenBytes))
+		if err := verifyTransfer(*relayedPayload, req); err != nil {
+			mismatches = append(mismatches, fmt.Errorf("requirement %d: %w", i, err))
+			continue
 		}
 
-		amountBytes, err := hex.DecodeString(parts[5])
+		resp, err := s.Verify(ctx, payload, req)
 		if err != nil {
-			return nil, fmt.Errorf("invalid amount hex")
-		}
-		amountBig := new(big.Int).SetBytes(amountBytes)
-		expectedBig, ok := new(big.Int).SetString(expectedAmount, 10)
-		if !ok {
-			return nil, fmt.Errorf("invalid expected amount: %s", expectedAmount)
-		}
-		if amountBig.Cmp(expectedBig) < 0 {
-			return nil, fmt.Errorf("amount too low or invalid")
+			return nil, i, err
 		}
+		return resp, i, nil
 	}
 
-	return &x402.VerifyResponse{
-		IsValid: true,
-	}, nil
+	if len(mismatches) == 0 {
+		mismatches = append(mismatches, errors.New("no requirements offered"))
+	}
+	return nil, -1, x402.NewVerifyError(multiversx.ErrCodeNoMatchingRequirement, relayedPayload.Sender, "multiversx", errors.Join(mismatches...))
+}
+
+// checkChainID rejects a payload signed for another chain than the requirements network
+// A payload signed for devnet must not be accepted by a mainnet facilitator, and vice versa.
+func checkChainID(payload multiversx.ExactRelayedPayload, requirements types.PaymentRequirements) error {
+	if requirements.Network == "" {
+		return nil
+	}
+	chainID, err := multiversx.GetMultiversXChainId(requirements.Network)
+	if err != nil {
+		return x402.NewVerifyError(multiversx.ErrCodeInvalidRequirements, payload.Sender, "multiversx", err)
+	}
+	if payload.ChainID != chainID {
+		return x402.NewVerifyError(multiversx.ErrCodeChainMismatch, payload.Sender, "multiversx", fmt.Errorf("payload chain %q does not match network %s (chain %q)", payload.ChainID, requirements.Network, chainID))
+	}
+	return nil
+}
+
+// verifyTransfer checks the payload transfer against the requirements with the resolved transfer method
+func verifyTransfer(payload multiversx.ExactRelayedPayload, requirements types.PaymentRequirements) error {
+	return multiversx.VerifyTransfer(payload, requirements)
 }
 
 // Settle executes the payment defined in the payload
 // It handles both Direct and Relayed V3 transactions
+// When a settlement queue is configured, Settle waits for its turn in the queue.
 func (s *ExactMultiversXScheme) Settle(ctx context.Context, payload types.PaymentPayload, requirements types.PaymentRequirements) (*x402.SettleResponse, error) {
+	if s.queue == nil {
+		return s.settle(ctx, payload, requirements)
+	}
+
+	results, err := s.queue.Submit(ctx, payload, requirements)
+	if err != nil {
+		return nil, x402.NewSettleError("settlement_queue_unavailable", settlementSender(payload), "multiversx", "", err)
+	}
+	result := <-results
+	return result.Response, result.Err
+}
+
+// SubmitSettle enqueues the payment for settlement and returns a channel receiving the result
+// Without a settlement queue the payment is settled right away in its own goroutine.
+func (s *ExactMultiversXScheme) SubmitSettle(ctx context.Context, payload types.PaymentPayload, requirements types.PaymentRequirements) (<-chan SettleResult, error) {
+	if s.queue != nil {
+		return s.queue.Submit(ctx, payload, requirements)
+	}
+
+	results := make(chan SettleResult, 1)
+	go func() {
+		resp, err := s.settle(ctx, payload, requirements)
+		results <- SettleResult{Response: resp, Err: err}
+	}()
+	return results, nil
+}
+
+// Close waits for queued settlements to finish and stops accepting new ones
+func (s *ExactMultiversXScheme) Close() {
+	if s.queue != nil {
+		s.queue.Close()
+	}
+}
+
+// settlePayment broadcasts the payment and, unless async settlement is enabled, waits for the outcome
+// onBroadcast, when set, receives the pending response as soon as the transaction is broadcast.
+func (s *ExactMultiversXScheme) settlePayment(ctx context.Context, payload types.PaymentPayload, requirements types.PaymentRequirements, onBroadcast func(pending *x402.SettleResponse)) (*x402.SettleResponse, error) {
 	relayedPayloadPtr, err := multiversx.PayloadFromMap(payload.Payload)
 	if err != nil {
 		return nil, x402.NewSettleError("invalid_payload", "", "multiversx", "", err)
 	}
 	relayedPayload := *relayedPayloadPtr
+	if s.proxy == nil {
+		return nil, x402.NewSettleError("configuration_error", relayedPayload.Sender, "multiversx", "", errNoProxy)
+	}
+
+	if reason, err := s.checkGasPrice(ctx, relayedPayload); err != nil {
+		return nil, x402.NewSettleError(reason, relayedPayload.Sender, "multiversx", "", err)
+	}
+
+	if err := s.checkReplay(ctx, relayedPayload); err != nil {
+		return nil, x402.NewSettleError(multiversx.ErrCodePaymentReplayed, relayedPayload.Sender, "multiversx", "", err)
+	}
+
+	nonces, settled := s.stateStores()
+	key, err := payloadFingerprint(relayedPayload)
+	if err != nil {
+		return nil, x402.NewSettleError("invalid_payload", relayedPayload.Sender, "multiversx", "", err)
+	}
+	if txHash, ok, err := settled.GetSettled(ctx, key); err != nil {
+		return nil, x402.NewSettleError("settlement_store_unavailable", relayedPayload.Sender, "multiversx", "", err)
+	} else if ok {
+		return nil, x402.NewSettleError("already_settled", relayedPayload.Sender, "multiversx", txHash, fmt.Errorf("payload already settled in transaction %s", txHash))
+	}
+
+	if err := nonces.reserve(ctx, relayedPayload.Sender, relayedPayload.Nonce); err != nil {
+		reason := "settlement_store_unavailable"
+		if errors.Is(err, errNonceInUse) {
+			reason = "nonce_in_use"
+		}
+		return nil, x402.NewSettleError(reason, relayedPayload.Sender, "multiversx", "", err)
+	}
+	broadcast := false
+	defer func() {
+		if !broadcast {
+			_ = nonces.release(context.WithoutCancel(ctx), relayedPayload.Sender, relayedPayload.Nonce)
+		}
+	}()
 
 	tx := relayedPayload.ToTransaction()
 
 	var hash string
 
 	// Default to relayed unless explicit "direct" transfer method is requested
-	transferMethod, _ := requirements.Extra["assetTransferMethod"].(string)
+	transferMethod, _ := multiversx.ExtraString(requirements.Extra, "assetTransferMethod")
 
 	if transferMethod != multiversx.TransferMethodDirect {
-		// RELAYED TRANSFER (Relayed V3) - Default
+		// RELAYED TRANSFER (Relayed V3 by default, relayedTxV1 with WithRelayVersion)
 		// Strictly require a signer for relayed transactions
 		if s.signer == nil {
-			return nil, x402.NewSettleError("configuration_error", relayedPayload.Sender, "multiversx", "", fmt.Errorf("signer required for relayed translation"))
+			return nil, x402.NewSettleError("configuration_error", relayedPayload.Sender, "multiversx", "", errors.New("signer required for relayed transactions"))
 		}
 
 		addresses := s.signer.GetAddresses()
 		if len(addresses) == 0 {
 			return nil, x402.NewSettleError("no_signer_address", relayedPayload.Sender, "multiversx", "", errors.New("signer has no addresses"))
 		}
-		facilitatorAddr := addresses[0]
 
-		tx.RelayerAddr = facilitatorAddr
-		tx.Version = 2 // Relayed V3 uses version 2
-
-		// Store signature in temporary error variable to avoid shadowing 'err'
-		var sig string
-		var signErr error
-		sig, signErr = s.signer.Sign(ctx, &tx)
-		if signErr != nil {
-			return nil, x402.NewSettleError("signing_failed", relayedPayload.Sender, "multiversx", "", signErr)
+		if s.relayVersion == RelayV1 {
+			relayer := s.selectRelayer(addresses)
+			if !slices.Contains(addresses, relayer) {
+				return nil, x402.NewSettleError("relayer_mismatch", relayedPayload.Sender, "multiversx", "", fmt.Errorf("selected relayer %q is not a facilitator address", relayer))
+			}
+			wrapped, err := s.wrapRelayedV1(ctx, &tx, relayer)
+			if err != nil {
+				return nil, x402.NewSettleError("relay_wrapping_failed", relayedPayload.Sender, "multiversx", "", err)
+			}
+			defer func() {
+				if !broadcast {
+					_ = nonces.release(context.WithoutCancel(ctx), wrapped.Sender, wrapped.Nonce)
+				}
+			}()
+			tx = *wrapped
+		} else if err := s.relayV3(ctx, &tx, relayedPayload, addresses); err != nil {
+			return nil, err
 		}
-		tx.RelayerSignature = sig
 	}
 
 	hash, err = s.proxy.SendTransaction(ctx, &tx)
 
 	if err != nil {
-		return nil, x402.NewSettleError("broadcast_failed", relayedPayload.Sender, "multiversx", "", err)
+		reason := "broadcast_failed"
+		if mapped, ok := s.mapNodeError(err); ok {
+			reason = mapped
+		}
+		return nil, x402.NewSettleError(reason, relayedPayload.Sender, "multiversx", "", err)
+	}
+	broadcast = true
+	broadcastAt := time.Now()
+	tracker := s.trackSettlement(hash, relayedPayload.Sender)
+
+	// The transaction is already on its way: a store failure is not reported since the
+	// nonce reservation keeps blocking a second broadcast of the payload
+	_ = settled.MarkSettled(ctx, key, hash)
+	_ = s.replays().Record(ctx, paymentKey(relayedPayload))
+
+	pending := &x402.SettleResponse{
+		Success:     true,
+		Transaction: hash,
+		Extra: map[string]interface{}{
+			"pending":           true,
+			ExplorerURLExtraKey: s.explorerURL(relayedPayload.ChainID, hash),
+		},
+	}
+	if onBroadcast != nil {
+		onBroadcast(pending)
+	}
+	if s.asyncSettle {
+		return pending, nil
 	}
 
-	if err := s.waitForTx(ctx, hash); err != nil {
-		return nil, x402.NewSettleError("tx_failed", relayedPayload.Sender, "multiversx", hash, err)
+	if err := s.waitForTx(ctx, hash, tracker.observer()); err != nil {
+		tracker.stopped(err)
+		reason := "tx_failed"
+		var rejected *multiversx.ContractRejectedError
+		if errors.As(err, &rejected) {
+			reason = multiversx.ErrCodeContractRejected
+		} else if errors.Is(err, errResultUnknown) {
+			reason = multiversx.ErrCodeResultUnknown
+		}
+		return nil, x402.NewSettleError(reason, relayedPayload.Sender, "multiversx", hash, err)
+	}
+	s.sink().ObserveSettleDuration(time.Since(broadcastAt))
+
+	// Gas used and fee are best effort: they stay zero if the gateway has not indexed the results yet
+	processed := processedTransaction{Fee: "0"}
+	if fetched, err := s.getProcessedTransaction(ctx, relayedPayload.ChainID, hash); err == nil {
+		processed = *fetched
+	}
+
+	extra := map[string]interface{}{
+		"gasUsed":           processed.GasUsed,
+		"fee":               processed.Fee,
+		ExplorerURLExtraKey: s.explorerURL(relayedPayload.ChainID, hash),
+	}
+	// The charged amount is only reported when it could be read from the confirmed transaction
+	amount := requirements.Amount
+	if charged, ok := processed.amountCharged(requirements.Asset); ok {
+		extra["amountCharged"] = charged
+		amount = charged
+	}
+	if proof, ok := s.settlementProof(ctx, relayedPayload, requirements, hash, amount); ok {
+		extra[multiversx.SettlementProofExtraKey] = proof
 	}
 
 	return &x402.SettleResponse{
 		Success:     true,
 		Transaction: hash,
+		Extra:       extra,
 	}, nil
 }
 
-// waitForTx polls the transaction status using the proxy
-func (s *ExactMultiversXScheme) waitForTx(ctx context.Context, txHash string) error {
-	ticker := time.NewTicker(2 * time.Second)
-	defer ticker.Stop()
+// relayV3 signs tx as the relayer of a relayed V3 transaction, using the relayer and version the sender signed
+func (s *ExactMultiversXScheme) relayV3(ctx context.Context, tx *transaction.FrontendTransaction, relayedPayload multiversx.ExactRelayedPayload, addresses []string) error {
+	// The sender signature commits to the relayer and version, so they are used as signed:
+	// relaying through another address would invalidate the transaction
+	if !slices.Contains(addresses, relayedPayload.Relayer) {
+		return x402.NewSettleError("relayer_mismatch", relayedPayload.Sender, "multiversx", "", fmt.Errorf("payload relayer %q is not a facilitator address", relayedPayload.Relayer))
+	}
+	if tx.Version != multiversx.TxVersionRelayed {
+		return x402.NewSettleError(multiversx.ErrCodeInconsistentTransaction, relayedPayload.Sender, "multiversx", "", fmt.Errorf("relayed transfers require version %d, got %d", multiversx.TxVersionRelayed, tx.Version))
+	}
 
-	// Wait up to 120 seconds
-	timeout := time.After(120 * time.Second)
+	sig, err := s.signer.Sign(ctx, tx)
+	if err != nil {
+		return x402.NewSettleError("signing_failed", relayedPayload.Sender, "multiversx", "", err)
+	}
+	tx.RelayerSignature = sig
+	return nil
+}
+
+// processedTransaction holds the transfer, gas consumed and fee paid of a processed transaction
+type processedTransaction struct {
+	GasUsed uint64 `json:"gasUsed"`
+	Fee     string `json:"fee"`
+	Value   string `json:"value"`
+	// Data is sent base64 encoded by the gateway
+	Data []byte `json:"data"`
+}
+
+// amountCharged returns the amount of asset the transaction actually transferred
+// Native EGLD sent through the transaction value is read from Value; tokens, and EGLD sent
+// as EGLD-000000, are summed over the MultiESDTNFTTransfer entries of the asset.
+func (t processedTransaction) amountCharged(asset string) (string, bool) {
+	transfer, err := multiversx.DecodeMultiESDTTransfer(string(t.Data))
+	if err != nil {
+		if asset != multiversx.NativeTokenTicker || t.Value == "" {
+			return "", false
+		}
+		if _, err := multiversx.CheckAmount(t.Value); err != nil {
+			return "", false
+		}
+		return t.Value, true
+	}
+
+	total := new(big.Int)
+	found := false
+	for _, token := range transfer.Transfers {
+		if token.Asset != multiversx.MultiTransferAssetID(asset) {
+			continue
+		}
+		amount, ok := new(big.Int).SetString(token.Amount, 10)
+		if !ok {
+			return "", false
+		}
+		total.Add(total, amount)
+		found = true
+	}
+	if !found {
+		return "", false
+	}
+	return total.String(), true
+}
+
+// getProcessedTransaction reads the transfer, gas used and fee of a processed transaction from the gateway
+// The SDK TransactionInfo returned by GetTransactionInfoWithResults does not expose gas used and fee,
+// so the transaction endpoint is queried directly.
+func (s *ExactMultiversXScheme) getProcessedTransaction(ctx context.Context, chainID string, txHash string) (*processedTransaction, error) {
+	apiURL := s.apiURL(chainID)
+	if apiURL == "" {
+		return nil, errors.New("api url not configured")
+	}
+
+	url := fmt.Sprintf("%s/transaction/%s?withResults=true", apiURL, txHash)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
 
+	resp, err := s.client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("transaction api error: %s", resp.Status)
+	}
+
+	var res struct {
+		Data struct {
+			Transaction processedTransaction `json:"transaction"`
+		} `json:"data"`
+		Error string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return nil, err
+	}
+	if res.Error != "" {
+		return nil, errors.New(res.Error)
+	}
+	if res.Data.Transaction.Fee == "" {
+		res.Data.Transaction.Fee = "0"
+	}
+
+	return &res.Data.Transaction, nil
+}
+
+// waitForTx polls the transaction status using the proxy, reporting each classified status to observe
+func (s *ExactMultiversXScheme) waitForTx(ctx context.Context, txHash string, observe statusObserver) error {
+	interval := s.pollInterval
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	maxInterval := s.maxPollInterval
+	if maxInterval <= 0 {
+		maxInterval = DefaultMaxPollInterval
+	}
+	if maxInterval < interval {
+		maxInterval = interval
+	}
+	settleTimeout := s.settleTimeout
+	if settleTimeout <= 0 {
+		settleTimeout = DefaultSettleTimeout
+	}
+	sleep := s.sleep
+	if sleep == nil {
+		sleep = sleepContext
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, settleTimeout)
+	defer cancel()
+
+	lookups := 0
 	for {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-timeout:
-			return fmt.Errorf("timeout waiting for tx %s", txHash)
-		case <-ticker.C:
-			status, err := s.getTransactionStatus(ctx, txHash)
-			if err != nil {
-				continue // retry on transient errors
+		if err := sleep(waitCtx, jitter(interval)); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
 			}
+			return fmt.Errorf("timeout waiting for tx %s: %w", txHash, context.DeadlineExceeded)
+		}
 
-			switch status {
-			case "success", "successful", "executed":
+		status, err := s.getTransactionStatus(waitCtx, txHash)
+		if err == nil {
+			switch settlementStatusOf(status) {
+			case SettlementStatusSuccess:
+				rejection, err := s.contractRejection(waitCtx, txHash)
+				if err != nil {
+					// The status is final, so only the results are retried, and only a few times
+					lookups++
+					if lookups >= DefaultResultLookups {
+						return fmt.Errorf("%w: tx %s executed with status %s: %v", errResultUnknown, txHash, status, err)
+					}
+					break
+				}
+				if rejection != nil {
+					observe.observe(SettlementStatusFailed, status, rejection)
+					return fmt.Errorf("transaction executed with status %s but its contract call failed: %w", status, rejection)
+				}
+				observe.observe(SettlementStatusSuccess, status, nil)
 				return nil
-			case "fail", "failed", "invalid":
+			case SettlementStatusFailed:
+				observe.observe(SettlementStatusFailed, status, nil)
 				return fmt.Errorf("transaction failed with status: %s", status)
-			case "pending", "processing", "received", "partially-executed":
-				continue
 			default:
-				// t.Logf("Unknown transaction status: %s", status)
+				observe.observe(SettlementStatusPending, status, nil)
+			}
+		}
+		// retry on transient errors and pending statuses, backing off exponentially
+		interval *= 2
+		if interval > maxInterval {
+			interval = maxInterval
+		}
+	}
+}
+
+// errResultUnknown is returned when a transaction executed but its smart contract results could not be read
+var errResultUnknown = errors.New("settlement result unknown")
+
+// jitter returns a random delay in [d/2, d] so concurrent settlements do not poll in lockstep
+func jitter(d time.Duration) time.Duration {
+	half := d / 2
+	return half + time.Duration(rand.Int64N(int64(d-half)+1))
+}
+
+// sleepContext waits for d or until ctx is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// SettlementStatus is the normalized state of a broadcast settlement transaction
+type SettlementStatus string
+
+const (
+	// SettlementStatusPending means the transaction is not final yet
+	SettlementStatusPending SettlementStatus = "pending"
+	// SettlementStatusSuccess means the transaction was executed successfully
+	SettlementStatusSuccess SettlementStatus = "success"
+	// SettlementStatusFailed means the transaction failed or was invalid
+	SettlementStatusFailed SettlementStatus = "failed"
+)
+
+// GetSettlementStatus reports the state of a settlement broadcast in async mode
+// A successful transaction whose contract call failed is reported as failed.
+func (s *ExactMultiversXScheme) GetSettlementStatus(ctx context.Context, txHash string) (SettlementStatus, error) {
+	status, err := s.getTransactionStatus(ctx, txHash)
+	if err != nil {
+		return "", err
+	}
+	settlementStatus := settlementStatusOf(status)
+	if settlementStatus == SettlementStatusSuccess {
+		rejection, err := s.contractRejection(ctx, txHash)
+		if err != nil {
+			return "", err
+		}
+		if rejection != nil {
+			return SettlementStatusFailed, nil
+		}
+	}
+	return settlementStatus, nil
+}
+
+// contractErrorEvents are the log identifiers the VM emits when a contract call fails
+var contractErrorEvents = map[string]bool{
+	"signalError":      true,
+	"internalVMErrors": true,
+}
+
+// contractRejection looks for contract errors in the logs of a transaction and of its smart contract results
+// A relayed ESDT transfer lands with status "success" even when its inner call reverts; only the events tell.
+func (s *ExactMultiversXScheme) contractRejection(ctx context.Context, txHash string) (*multiversx.ContractRejectedError, error) {
+	info, err := s.proxy.GetTransactionInfoWithResults(ctx, txHash)
+	if err != nil {
+		return nil, err
+	}
+	if info == nil {
+		return nil, nil
+	}
+
+	tx := info.Data.Transaction
+	logs := []*transaction.ApiLogs{tx.Logs}
+	for _, result := range tx.ScResults {
+		if result != nil {
+			logs = append(logs, result.Logs)
+		}
+	}
+	for _, log := range logs {
+		if log == nil {
+			continue
+		}
+		for _, event := range log.Events {
+			if event == nil || !contractErrorEvents[event.Identifier] {
 				continue
 			}
+			// signalError carries the message in its second topic; internalVMErrors in its data
+			message := string(event.Data)
+			if event.Identifier == "signalError" && len(event.Topics) > 1 {
+				message = string(event.Topics[1])
+			}
+			return &multiversx.ContractRejectedError{Message: message}, nil
 		}
 	}
+	return nil, nil
+}
+
+// settlementStatusOf normalizes a transaction status; unknown statuses are reported as pending
+func settlementStatusOf(status string) SettlementStatus {
+	switch multiversx.NormalizeTxStatus(status) {
+	case multiversx.TxOutcomeSuccess:
+		return SettlementStatusSuccess
+	case multiversx.TxOutcomeFailed:
+		return SettlementStatusFailed
+	default:
+		return SettlementStatusPending
+	}
 }
 
 // getTransactionStatus fetches status via the proxy engine
@@ -284,7 +989,7 @@ func (s *ExactMultiversXScheme) getTransactionStatus(ctx context.Context, txHash
 		return "", err
 	}
 
-	if status == "fail" || status == "failed" || status == "invalid" {
+	if multiversx.NormalizeTxStatus(status) == multiversx.TxOutcomeFailed {
 		txInfo, err := s.proxy.GetTransactionInfo(ctx, txHash)
 		if err == nil && txInfo.Error != "" {
 
@@ -295,7 +1000,158 @@ func (s *ExactMultiversXScheme) getTransactionStatus(ctx context.Context, txHash
 	return status, nil
 }
 
-func (s *ExactMultiversXScheme) verifyViaSimulation(payload multiversx.ExactRelayedPayload) (string, error) {
+// checkGasPrice rejects gas prices outside [minGasPrice, maxGasPriceMultiple * minGasPrice]
+// It is a no-op unless WithGasPriceBounds is configured, and returns the error reason on rejection.
+func (s *ExactMultiversXScheme) checkGasPrice(ctx context.Context, payload multiversx.ExactRelayedPayload) (string, error) {
+	if s.maxGasPriceMultiple == 0 {
+		return "", nil
+	}
+
+	config, err := s.proxy.GetNetworkConfig(ctx)
+	if err != nil {
+		return "network_config_unavailable", fmt.Errorf("failed to fetch network config: %w", err)
+	}
+	if config == nil || config.MinGasPrice == 0 {
+		return "network_config_unavailable", errors.New("network config has no minimum gas price")
+	}
+
+	if payload.GasPrice < config.MinGasPrice {
+		return multiversx.ErrCodeGasTooLow, fmt.Errorf("gas price %d is below the network minimum %d", payload.GasPrice, config.MinGasPrice)
+	}
+	maxGasPrice := new(big.Int).Mul(new(big.Int).SetUint64(config.MinGasPrice), new(big.Int).SetUint64(s.maxGasPriceMultiple))
+	if new(big.Int).SetUint64(payload.GasPrice).Cmp(maxGasPrice) > 0 {
+		return multiversx.ErrCodeGasPriceTooHigh, fmt.Errorf("gas price %d exceeds %d times the network minimum %d", payload.GasPrice, s.maxGasPriceMultiple, config.MinGasPrice)
+	}
+
+	return "", nil
+}
+
+// stateStores returns the nonce manager and settled store, creating in-memory defaults on first use
+func (s *ExactMultiversXScheme) stateStores() (*nonceManager, SettledStore) {
+	s.storesOnce.Do(func() {
+		if s.nonceStore == nil {
+			s.nonceStore = NewMemoryNonceStore()
+		}
+		if s.settledStore == nil {
+			s.settledStore = NewMemorySettledStore()
+		}
+		if s.replayStore == nil {
+			s.replayStore = NewMemoryReplayStore()
+		}
+		s.nonces = &nonceManager{store: s.nonceStore}
+	})
+	return s.nonces, s.settledStore
+}
+
+// replays returns the replay store, creating the in-memory default on first use
+func (s *ExactMultiversXScheme) replays() ReplayStore {
+	s.stateStores()
+	return s.replayStore
+}
+
+// paymentKey returns the replay protection key of the payload
+func paymentKey(payload multiversx.ExactRelayedPayload) PaymentKey {
+	return PaymentKey{Sender: payload.Sender, Nonce: payload.Nonce, ChainID: payload.ChainID}
+}
+
+// checkReplay fails when the payload's (sender, nonce, chainID) was already settled
+// A store failure is reported as an error as well, so replays are never let through.
+func (s *ExactMultiversXScheme) checkReplay(ctx context.Context, payload multiversx.ExactRelayedPayload) error {
+	key := paymentKey(payload)
+	seen, err := s.replays().Seen(ctx, key)
+	if err != nil {
+		return fmt.Errorf("failed to check replay store: %w", err)
+	}
+	if seen {
+		return fmt.Errorf("payment of %s with nonce %d on chain %s was already settled", key.Sender, key.Nonce, key.ChainID)
+	}
+	return nil
+}
+
+// errNoProxy reports a scheme without a gateway proxy, e.g. one built as a struct literal instead of with NewExactMultiversXScheme
+var errNoProxy = errors.New("facilitator scheme has no gateway proxy, create it with NewExactMultiversXScheme")
+
+// verifyNeedsProxy reports whether the enabled Verify checks query the gateway through the proxy
+func (s *ExactMultiversXScheme) verifyNeedsProxy() bool {
+	return s.maxGasPriceMultiple > 0 || s.balanceCheck || s.receiverCheck
+}
+
+// client returns the configured HTTP client, falling back to http.DefaultClient
+func (s *ExactMultiversXScheme) client() *http.Client {
+	if s.httpClient != nil {
+		return s.httpClient
+	}
+	return http.DefaultClient
+}
+
+// apiURL returns the gateway API serving chainID
+func (s *ExactMultiversXScheme) apiURL(chainID string) string {
+	if url, ok := s.apiOverrides[chainID]; ok && url != "" {
+		return url
+	}
+	return s.config.ApiUrl
+}
+
+// now returns the current time from the configured clock
+func (s *ExactMultiversXScheme) now() time.Time {
+	if s.clock != nil {
+		return s.clock()
+	}
+	return time.Now()
+}
+
+// mapNodeError translates a node error message into an x402 error reason using the configured mappings
+func (s *ExactMultiversXScheme) mapNodeError(err error) (string, bool) {
+	mappings := s.nodeErrorMappings
+	if mappings == nil {
+		mappings = multiversx.DefaultNodeErrorMappings
+	}
+	return multiversx.MapNodeError(err.Error(), mappings)
+}
+
+// verifyViaSimulation simulates the transaction, returning known node rejections as typed verify errors
+// Concurrent calls for the same payload share a single in-flight simulation bound to the first caller's
+// context; a caller whose shared simulation was cancelled by another caller simulates again on its own.
+func (s *ExactMultiversXScheme) verifyViaSimulation(ctx context.Context, payload multiversx.ExactRelayedPayload) (string, error) {
+	key, err := payloadFingerprint(payload)
+	if err != nil {
+		return "", err
+	}
+
+	result, err, shared := s.simulations.Do(key, func() (interface{}, error) {
+		return s.simulate(ctx, payload)
+	})
+	if err != nil && shared && ctx.Err() == nil && isContextError(err) {
+		result, err = s.simulate(ctx, payload)
+	}
+	if err != nil {
+		var verifyErr *x402.VerifyError
+		if errors.As(err, &verifyErr) {
+			return "", err
+		}
+		if reason, ok := s.mapNodeError(err); ok {
+			return "", x402.NewVerifyError(reason, payload.Sender, "multiversx", err)
+		}
+		return "", err
+	}
+	return result.(string), nil
+}
+
+// isContextError reports whether err comes from a cancelled or expired context
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// payloadFingerprint returns a stable identifier of the payload used to deduplicate simulations
+func payloadFingerprint(payload multiversx.ExactRelayedPayload) (string, error) {
+	return multiversx.PaymentFingerprint(payload)
+}
+
+// simulate submits the transaction to the simulation endpoint and returns the simulated hash
+func (s *ExactMultiversXScheme) simulate(ctx context.Context, payload multiversx.ExactRelayedPayload) (string, error) {
+	start := time.Now()
+	defer func() { s.sink().ObserveSimulationDuration(time.Since(start)) }()
+
 	tx := payload.ToTransaction()
 	if tx.Version >= 2 && tx.RelayerAddr != "" && s.signer != nil {
 		// Attempt to sign as relayer if we hold the key
@@ -303,7 +1159,7 @@ func (s *ExactMultiversXScheme) verifyViaSimulation(payload multiversx.ExactRela
 		for _, addr := range addresses {
 			if addr == tx.RelayerAddr {
 				// We are the relayer
-				sig, err := s.signer.Sign(context.TODO(), &tx)
+				sig, err := s.signer.Sign(ctx, &tx)
 				if err != nil {
 					return "", fmt.Errorf("failed to sign as relayer: %w", err)
 				}
@@ -320,30 +1176,19 @@ func (s *ExactMultiversXScheme) verifyViaSimulation(payload multiversx.ExactRela
 		return "", err
 	}
 
-	url := fmt.Sprintf("%s/transaction/simulate", s.config.ApiUrl)
-
-	req, err := http.NewRequestWithContext(context.Background(), "POST", url, bytes.NewBuffer(txBytes))
-	if err != nil {
-		return "", err
-	}
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := s.postSimulation(ctx, tx.ChainID, "simulate", txBytes)
 	if err != nil {
 		return "", err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("simulation api error: %s - %s", resp.Status, string(body))
-	}
-
 	var res struct {
 		Data struct {
 			Result struct {
-				Status string `json:"status"`
-				Hash   string `json:"hash"`
+				Status     string          `json:"status"`
+				Hash       string          `json:"hash"`
+				FailReason string          `json:"failReason"`
+				Logs       *simulationLogs `json:"logs"`
 			} `json:"result"`
 		} `json:"data"`
 		Error string `json:"error"`
@@ -356,6 +1201,14 @@ func (s *ExactMultiversXScheme) verifyViaSimulation(payload multiversx.ExactRela
 		return "", errors.New(res.Error)
 	}
 
+	// A contract rejection can come with a "successful" request code, so check it first
+	if message, ok := res.Data.Result.Logs.signalError(); ok {
+		if message == "" {
+			message = res.Data.Result.FailReason
+		}
+		return "", x402.NewVerifyError(multiversx.ErrCodeContractRejected, payload.Sender, "multiversx", &multiversx.ContractRejectedError{Message: message})
+	}
+
 	if res.Code == "successful" {
 		hash := res.Data.Result.Hash
 		if hash == "" {
@@ -364,9 +1217,82 @@ func (s *ExactMultiversXScheme) verifyViaSimulation(payload multiversx.ExactRela
 		return hash, nil
 	}
 
-	if res.Data.Result.Status != "success" && res.Data.Result.Status != "successful" {
+	if multiversx.NormalizeTxStatus(res.Data.Result.Status) != multiversx.TxOutcomeSuccess {
 		return "", fmt.Errorf("simulation status not success: %s (code: %s)", res.Data.Result.Status, res.Code)
 	}
 
 	return res.Data.Result.Hash, nil
 }
+
+// simulationLogs holds the events emitted by a simulated transaction
+type simulationLogs struct {
+	Events []struct {
+		Identifier string   `json:"identifier"`
+		Topics     [][]byte `json:"topics"`
+	} `json:"events"`
+}
+
+// signalError returns the message of the first signalError event, emitted when a contract rejects the call
+// The message is the second topic; the first one is the address of the caller.
+func (l *simulationLogs) signalError() (string, bool) {
+	if l == nil {
+		return "", false
+	}
+	for _, event := range l.Events {
+		if event.Identifier != "signalError" {
+			continue
+		}
+		if len(event.Topics) > 1 {
+			return string(event.Topics[1]), true
+		}
+		return "", true
+	}
+	return "", false
+}
+
+// postSimulation sends the transaction to a dry-run endpoint ("simulate" or "cost"), retrying network errors
+// and 5xx responses. 4xx responses are returned right away: retrying a rejected transaction cannot change the outcome.
+func (s *ExactMultiversXScheme) postSimulation(ctx context.Context, chainID string, endpoint string, txBytes []byte) (*http.Response, error) {
+	attempts := s.simulationAttempts
+	if attempts <= 0 {
+		attempts = DefaultSimulationAttempts
+	}
+	sleep := s.sleep
+	if sleep == nil {
+		sleep = sleepContext
+	}
+
+	url := fmt.Sprintf("%s/transaction/%s", s.apiURL(chainID), endpoint)
+	backoff := simulationRetryBackoff
+
+	var lastErr error
+	for attempt := 1; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(txBytes))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := s.client().Do(req)
+		if err == nil {
+			if resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusCreated {
+				return resp, nil
+			}
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			err = fmt.Errorf("simulation api error: %s - %s", resp.Status, string(body))
+			if resp.StatusCode < http.StatusInternalServerError {
+				return nil, err
+			}
+		}
+		lastErr = err
+
+		if attempt >= attempts {
+			return nil, lastErr
+		}
+		if err := sleep(ctx, jitter(backoff)); err != nil {
+			return nil, lastErr
+		}
+		backoff *= 2
+	}
+}
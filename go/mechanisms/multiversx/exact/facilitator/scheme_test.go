@@ -2,10 +2,19 @@ package facilitator
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -17,6 +26,7 @@ import (
 	"github.com/multiversx/mx-sdk-go/core"
 	"github.com/multiversx/mx-sdk-go/data"
 
+	x402 "github.com/coinbase/x402/go"
 	"github.com/coinbase/x402/go/mechanisms/multiversx"
 	"github.com/coinbase/x402/go/types"
 )
@@ -142,6 +152,123 @@ func TestVerify_AssetMismatch(t *testing.T) {
 	}
 }
 
+func TestVerify_MultiTransferUnderpaysSecondToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":{"result":{"status":"success","hash":"sim_hash"}},"error":""}`))
+	}))
+	defer server.Close()
+	scheme, _ := NewExactMultiversXScheme(server.URL, &MockSigner{})
+
+	pubKey, privKey, _ := ed25519.GenerateKey(nil)
+	senderAddr, _ := data.NewAddressFromBytes(pubKey).AddressAsBech32String()
+	payTo := "erd1spyavw0956vq68xj8y4tenjpq2wd5a9p2c6j8gsz7ztyrnpxrruqzu66jx"
+
+	// The payload sends 100 USDC and 4 WEGLD, the requirements ask for 100 USDC and 5 WEGLD
+	dataField := strings.Join([]string{
+		"MultiESDTNFTTransfer",
+		"8049d639e5a6980d1cd2392abcce41029cda74a1563523a202f09641cc2618f8",
+		"02",
+		hex.EncodeToString([]byte("USDC-c76f1f")), "00", "64",
+		hex.EncodeToString([]byte("WEGLD-bd4d79")), "00", "04",
+	}, "@")
+	payload := multiversx.ExactRelayedPayload{
+		Nonce:    1,
+		Value:    "0",
+		Receiver: senderAddr,
+		Sender:   senderAddr,
+		GasPrice: 1000000000,
+		GasLimit: multiversx.GasLimitESDT,
+		Data:     dataField,
+		ChainID:  "D",
+		Version:  1,
+	}
+	tx := payload.ToTransaction()
+	txBytes, _ := multiversx.SerializeTransaction(&tx)
+	payload.Signature = hex.EncodeToString(ed25519.Sign(privKey, txBytes))
+
+	req := types.PaymentRequirements{
+		PayTo:  payTo,
+		Amount: "100",
+		Asset:  "USDC-c76f1f",
+		Extra: map[string]interface{}{
+			"transfers": []interface{}{
+				map[string]interface{}{"asset": "USDC-c76f1f", "amount": "100"},
+				map[string]interface{}{"asset": "WEGLD-bd4d79", "amount": "5"},
+			},
+		},
+	}
+
+	_, err := scheme.Verify(context.Background(), types.PaymentPayload{Payload: payload.ToMap()}, req)
+	var vErr *x402.VerifyError
+	if !errors.As(err, &vErr) || vErr.Reason != multiversx.ErrCodeAmountMismatch {
+		t.Fatalf("Verify() error = %v, want %s", err, multiversx.ErrCodeAmountMismatch)
+	}
+
+	// The same payload verifies once every token is covered
+	req.Extra["transfers"].([]interface{})[1].(map[string]interface{})["amount"] = "4"
+	if _, err := scheme.Verify(context.Background(), types.PaymentPayload{Payload: payload.ToMap()}, req); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+}
+
+func TestVerify_ChainMismatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":{"result":{"status":"success","hash":"sim_hash"}},"error":""}`))
+	}))
+	defer server.Close()
+	scheme, _ := NewExactMultiversXScheme(server.URL, &MockSigner{})
+
+	pubKey, privKey, _ := ed25519.GenerateKey(nil)
+	senderAddr, _ := data.NewAddressFromBytes(pubKey).AddressAsBech32String()
+
+	payload := multiversx.ExactRelayedPayload{
+		Nonce:    1,
+		Value:    "1000",
+		Receiver: senderAddr,
+		Sender:   senderAddr,
+		GasPrice: 1000000000,
+		GasLimit: 50000,
+		ChainID:  "D",
+		Version:  1,
+	}
+	tx := payload.ToTransaction()
+	txBytes, _ := multiversx.SerializeTransaction(&tx)
+	payload.Signature = hex.EncodeToString(ed25519.Sign(privKey, txBytes))
+
+	tests := []struct {
+		network string
+		want    string
+	}{
+		{"multiversx:D", ""},
+		{"multiversx:1", multiversx.ErrCodeChainMismatch},
+		{"multiversx:T", multiversx.ErrCodeChainMismatch},
+		{"multiversx:unknown", multiversx.ErrCodeInvalidRequirements},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.network, func(t *testing.T) {
+			req := types.PaymentRequirements{
+				PayTo:   senderAddr,
+				Amount:  "1000",
+				Asset:   multiversx.NativeTokenTicker,
+				Network: tt.network,
+				Extra:   map[string]interface{}{"assetTransferMethod": multiversx.TransferMethodDirect},
+			}
+			_, err := scheme.Verify(context.Background(), types.PaymentPayload{Payload: payload.ToMap()}, req)
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("Verify() error = %v", err)
+				}
+				return
+			}
+			var vErr *x402.VerifyError
+			if !errors.As(err, &vErr) || vErr.Reason != tt.want {
+				t.Errorf("Verify() error = %v, want %s", err, tt.want)
+			}
+		})
+	}
+}
+
 // MockProxy implements ProxyWithStatus
 type MockProxy struct {
 	blockchain.Proxy
@@ -149,6 +276,12 @@ type MockProxy struct {
 	statusIndex     int
 	sendHash        string
 	sendErr         error
+	networkConfig   *data.NetworkConfig
+	account         *data.Account
+	txResults       *data.TransactionInfo
+	// txResultsFailures makes the first results lookups fail
+	txResultsFailures int
+	txResultsCalls    int
 }
 
 func (m *MockProxy) SendTransaction(ctx context.Context, tx *transaction.FrontendTransaction) (string, error) {
@@ -173,10 +306,10 @@ func (m *MockProxy) IsInterfaceNil() bool {
 
 // Helpers required by Proxy interface (stubs)
 func (m *MockProxy) GetNetworkConfig(ctx context.Context) (*data.NetworkConfig, error) {
-	return nil, nil
+	return m.networkConfig, nil
 }
 func (m *MockProxy) GetAccount(ctx context.Context, address core.AddressHandler) (*data.Account, error) {
-	return nil, nil
+	return m.account, nil
 }
 func (m *MockProxy) GetGuardianData(ctx context.Context, address core.AddressHandler) (*api.GuardianData, error) {
 	return nil, nil
@@ -193,6 +326,13 @@ func (m *MockProxy) GetTransactionInfo(ctx context.Context, hash string) (*data.
 }
 
 func (m *MockProxy) GetTransactionInfoWithResults(ctx context.Context, hash string) (*data.TransactionInfo, error) {
+	m.txResultsCalls++
+	if m.txResultsCalls <= m.txResultsFailures {
+		return nil, errors.New("results not indexed yet")
+	}
+	if m.txResults != nil {
+		return m.txResults, nil
+	}
 	return &data.TransactionInfo{}, nil
 }
 
@@ -231,6 +371,51 @@ func TestSettle_Success(t *testing.T) {
 	}
 }
 
+func TestSchemeWithoutProxyOrSigner(t *testing.T) {
+	relayed := multiversx.ExactRelayedPayload{Sender: "erd1sender", Nonce: 1, ChainID: "D"}
+	payload := types.PaymentPayload{Payload: relayed.ToMap()}
+	direct := types.PaymentRequirements{Extra: map[string]interface{}{"assetTransferMethod": multiversx.TransferMethodDirect}}
+
+	assertConfigurationError := func(t *testing.T, err error, want string) {
+		t.Helper()
+		var reason string
+		var sErr *x402.SettleError
+		var vErr *x402.VerifyError
+		switch {
+		case errors.As(err, &sErr):
+			reason = sErr.Reason
+		case errors.As(err, &vErr):
+			reason = vErr.Reason
+		}
+		if reason != "configuration_error" || !strings.Contains(err.Error(), want) {
+			t.Errorf("error = %v, want a configuration_error mentioning %q", err, want)
+		}
+	}
+
+	t.Run("Settle without proxy", func(t *testing.T) {
+		_, err := (&ExactMultiversXScheme{}).Settle(context.Background(), payload, direct)
+		assertConfigurationError(t, err, "no gateway proxy")
+	})
+
+	t.Run("relayed Settle without signer", func(t *testing.T) {
+		scheme := &ExactMultiversXScheme{proxy: &MockProxy{sendHash: "tx_hash"}}
+		_, err := scheme.Settle(context.Background(), payload, types.PaymentRequirements{})
+		assertConfigurationError(t, err, "signer required")
+	})
+
+	t.Run("Verify with gateway checks without proxy", func(t *testing.T) {
+		scheme := &ExactMultiversXScheme{}
+		WithBalanceCheck()(scheme)
+		valid := multiversx.ExactRelayedPayload{
+			Sender:   "erd1spyavw0956vq68xj8y4tenjpq2wd5a9p2c6j8gsz7ztyrnpxrruqzu66jx",
+			Receiver: "erd1qyu5wthldzr8wx5c9ucg8kjagg0jfs53s8nr3zpz3hypefsdd8ssycr6th",
+			ChainID:  "D",
+		}
+		_, err := scheme.Verify(context.Background(), types.PaymentPayload{Payload: valid.ToMap()}, direct)
+		assertConfigurationError(t, err, "no gateway proxy")
+	})
+}
+
 func TestSettle_Failure(t *testing.T) {
 	mockProxy := &MockProxy{
 		sendHash:        "tx_hash_456",
@@ -254,6 +439,43 @@ func TestSettle_Failure(t *testing.T) {
 	}
 }
 
+func TestSettle_ContractFailureWithSuccessStatus(t *testing.T) {
+	txResults := &data.TransactionInfo{}
+	txResults.Data.Transaction.Status = string(transaction.TxStatusSuccess)
+	txResults.Data.Transaction.ScResults = []*transaction.ApiSmartContractResult{{
+		Logs: &transaction.ApiLogs{Events: []*transaction.Events{{
+			Identifier: "signalError",
+			Topics:     [][]byte{[]byte("caller"), []byte("insufficient stock")},
+		}}},
+	}}
+	mockProxy := &MockProxy{
+		sendHash:        "tx_hash_reverted",
+		statusResponses: []transaction.TxStatus{transaction.TxStatusSuccess},
+		txResults:       txResults,
+	}
+	scheme := &ExactMultiversXScheme{proxy: mockProxy}
+	scheme.sleep = func(ctx context.Context, d time.Duration) error { return nil }
+
+	_, err := scheme.Settle(context.Background(), types.PaymentPayload{Payload: map[string]interface{}{}}, types.PaymentRequirements{
+		Extra: map[string]interface{}{
+			"assetTransferMethod": multiversx.TransferMethodDirect,
+		},
+	})
+	var rejected *multiversx.ContractRejectedError
+	if !errors.As(err, &rejected) || rejected.Message != "insufficient stock" {
+		t.Fatalf("Settle() error = %v, want the contract rejection", err)
+	}
+	var sErr *x402.SettleError
+	if !errors.As(err, &sErr) || sErr.Transaction != "tx_hash_reverted" || sErr.Reason != multiversx.ErrCodeContractRejected {
+		t.Errorf("Expected a contract_rejected settle error for tx_hash_reverted, got %v", err)
+	}
+
+	status, err := scheme.GetSettlementStatus(context.Background(), "tx_hash_reverted")
+	if err != nil || status != SettlementStatusFailed {
+		t.Errorf("GetSettlementStatus() = %s (err: %v), want failed", status, err)
+	}
+}
+
 func TestSettle_Polling(t *testing.T) {
 	// Mock returns Pending once, then Success
 	mockProxy := &MockProxy{
@@ -290,3 +512,1229 @@ func TestSettle_Polling(t *testing.T) {
 		t.Errorf("Expected 2 status checks, got %d", mockProxy.statusIndex)
 	}
 }
+
+func TestVerifyViaSimulation_MapsNodeErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		body       string
+		wantReason string
+	}{
+		{"Insufficient Funds", http.StatusOK, `{"error":"insufficient funds","code":"bad_request"}`, x402.ErrCodeInsufficientFunds},
+		{"Lower Nonce", http.StatusBadRequest, `{"error":"transaction generation failed: lowerNonceInTx: true, veryHighNonceInTx: false"}`, multiversx.ErrCodeInvalidNonce},
+		{"Gas Limit", http.StatusBadRequest, `{"error":"insufficient gas limit in tx"}`, multiversx.ErrCodeGasTooLow},
+		{"Gas Price", http.StatusOK, `{"error":"insufficient gas price in tx"}`, multiversx.ErrCodeGasTooLow},
+		{"Invalid Signature", http.StatusOK, `{"error":"invalid signature"}`, x402.ErrCodeSignatureInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			scheme, _ := NewExactMultiversXScheme(server.URL, nil)
+
+			_, err := scheme.verifyViaSimulation(context.Background(), multiversx.ExactRelayedPayload{Sender: "erd1sender", Value: "0"})
+			var vErr *x402.VerifyError
+			if !errors.As(err, &vErr) {
+				t.Fatalf("Expected *x402.VerifyError, got %T: %v", err, err)
+			}
+			if vErr.Reason != tt.wantReason {
+				t.Errorf("Expected reason %s, got %s", tt.wantReason, vErr.Reason)
+			}
+		})
+	}
+}
+
+func TestVerifyViaSimulation_CustomNodeErrorMappings(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"error":"account is frozen"}`))
+	}))
+	defer server.Close()
+
+	scheme, _ := NewExactMultiversXScheme(server.URL, nil, WithNodeErrorMappings([]multiversx.NodeErrorMapping{
+		{Match: "frozen", Reason: "account_frozen"},
+	}))
+
+	_, err := scheme.verifyViaSimulation(context.Background(), multiversx.ExactRelayedPayload{Sender: "erd1sender", Value: "0"})
+	var vErr *x402.VerifyError
+	if !errors.As(err, &vErr) {
+		t.Fatalf("Expected *x402.VerifyError, got %T: %v", err, err)
+	}
+	if vErr.Reason != "account_frozen" {
+		t.Errorf("Expected reason account_frozen, got %s", vErr.Reason)
+	}
+
+	// Unmapped errors are returned untyped and reported as simulation failures by VerifyPayment
+	scheme.nodeErrorMappings = []multiversx.NodeErrorMapping{}
+	_, err = scheme.verifyViaSimulation(context.Background(), multiversx.ExactRelayedPayload{Sender: "erd1sender", Value: "0"})
+	if err == nil || errors.As(err, &vErr) {
+		t.Errorf("Expected untyped error for unmapped message, got %T: %v", err, err)
+	}
+}
+
+func TestSettle_CustomPollInterval(t *testing.T) {
+	mockProxy := &MockProxy{
+		sendHash: "tx_hash_fast",
+		statusResponses: []transaction.TxStatus{
+			transaction.TxStatusPending,
+			transaction.TxStatusSuccess,
+		},
+	}
+	scheme := &ExactMultiversXScheme{proxy: mockProxy}
+	WithPollInterval(10 * time.Millisecond)(scheme)
+
+	start := time.Now()
+	resp, err := scheme.Settle(context.Background(), types.PaymentPayload{Payload: map[string]interface{}{}}, types.PaymentRequirements{
+		Extra: map[string]interface{}{
+			"assetTransferMethod": multiversx.TransferMethodDirect,
+		},
+	})
+	if err != nil {
+		t.Fatalf("Settle failed: %v", err)
+	}
+	if !resp.Success {
+		t.Error("Expected success")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected settle to complete quickly with 10ms polling, took %s", elapsed)
+	}
+	if mockProxy.statusIndex != 2 {
+		t.Errorf("Expected 2 status checks, got %d", mockProxy.statusIndex)
+	}
+}
+
+func TestSettle_CustomTimeout(t *testing.T) {
+	mockProxy := &MockProxy{
+		sendHash:        "tx_hash_stuck",
+		statusResponses: []transaction.TxStatus{transaction.TxStatusPending},
+	}
+	scheme := &ExactMultiversXScheme{proxy: mockProxy}
+	WithPollInterval(5 * time.Millisecond)(scheme)
+	WithSettleTimeout(50 * time.Millisecond)(scheme)
+
+	_, err := scheme.Settle(context.Background(), types.PaymentPayload{Payload: map[string]interface{}{}}, types.PaymentRequirements{
+		Extra: map[string]interface{}{
+			"assetTransferMethod": multiversx.TransferMethodDirect,
+		},
+	})
+	if err == nil {
+		t.Fatal("Expected timeout error for a transaction that stays pending")
+	}
+}
+
+func TestVerify_ConcurrentSimulationsAreDeduplicated(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		time.Sleep(200 * time.Millisecond)
+		w.Write([]byte(`{"data":{"result":{"status":"success","hash":"sim_hash"}},"error":""}`))
+	}))
+	defer server.Close()
+
+	scheme, _ := NewExactMultiversXScheme(server.URL, &MockSigner{})
+
+	pubKey, privKey, _ := ed25519.GenerateKey(nil)
+	senderAddr, _ := data.NewAddressFromBytes(pubKey).AddressAsBech32String()
+
+	payload := multiversx.ExactRelayedPayload{
+		Nonce:    10,
+		Value:    "1000",
+		Receiver: senderAddr,
+		Sender:   senderAddr,
+		GasPrice: 1000000000,
+		GasLimit: 50000,
+		ChainID:  "D",
+		Version:  1,
+	}
+	tx := payload.ToTransaction()
+	txBytes, _ := multiversx.SerializeTransaction(&tx)
+	payload.Signature = hex.EncodeToString(ed25519.Sign(privKey, txBytes))
+
+	req := types.PaymentRequirements{
+		PayTo:  senderAddr,
+		Amount: "1000",
+		Asset:  multiversx.NativeTokenTicker,
+		Extra: map[string]interface{}{
+			"assetTransferMethod": multiversx.TransferMethodDirect,
+		},
+	}
+
+	const workers = 20
+	start := make(chan struct{})
+	errs := make(chan error, workers)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			_, err := scheme.Verify(context.Background(), types.PaymentPayload{Payload: payload.ToMap()}, req)
+			errs <- err
+		}()
+	}
+	close(start)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Verify failed: %v", err)
+		}
+	}
+	if got := atomic.LoadInt32(&hits); got != 1 {
+		t.Errorf("Expected 1 simulation request, got %d", got)
+	}
+}
+
+func TestSettle_ReportsGasUsedAndFee(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/transaction/tx_hash_fee" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"data":{"transaction":{"hash":"tx_hash_fee","status":"success","gasUsed":50000,"fee":"50000000000000"}},"error":"","code":"successful"}`))
+	}))
+	defer server.Close()
+
+	mockProxy := &MockProxy{
+		sendHash:        "tx_hash_fee",
+		statusResponses: []transaction.TxStatus{transaction.TxStatusSuccess},
+	}
+	scheme, _ := NewExactMultiversXScheme(server.URL, nil, WithPollInterval(10*time.Millisecond))
+	scheme.proxy = mockProxy
+
+	directReq := types.PaymentRequirements{
+		Extra: map[string]interface{}{
+			"assetTransferMethod": multiversx.TransferMethodDirect,
+		},
+	}
+
+	resp, err := scheme.Settle(context.Background(), types.PaymentPayload{Payload: map[string]interface{}{}}, directReq)
+	if err != nil {
+		t.Fatalf("Settle failed: %v", err)
+	}
+	if resp.Extra["gasUsed"] != uint64(50000) {
+		t.Errorf("Expected gasUsed 50000, got %v", resp.Extra["gasUsed"])
+	}
+	if resp.Extra["fee"] != "50000000000000" {
+		t.Errorf("Expected fee 50000000000000, got %v", resp.Extra["fee"])
+	}
+
+	// Results not indexed yet: fields are left at zero
+	mockProxy.sendHash = "tx_hash_unknown"
+	mockProxy.statusIndex = 0
+	resp, err = scheme.Settle(context.Background(), types.PaymentPayload{Payload: map[string]interface{}{"nonce": float64(1)}}, directReq)
+	if err != nil {
+		t.Fatalf("Settle failed: %v", err)
+	}
+	if resp.Extra["gasUsed"] != uint64(0) || resp.Extra["fee"] != "0" {
+		t.Errorf("Expected zero gasUsed and fee, got %v and %v", resp.Extra["gasUsed"], resp.Extra["fee"])
+	}
+}
+
+func TestSettle_Async(t *testing.T) {
+	mockProxy := &MockProxy{
+		sendHash: "tx_hash_async",
+		statusResponses: []transaction.TxStatus{
+			transaction.TxStatusPending,
+			transaction.TxStatusSuccess,
+		},
+	}
+	scheme := &ExactMultiversXScheme{proxy: mockProxy}
+	WithAsyncSettle()(scheme)
+
+	resp, err := scheme.Settle(context.Background(), types.PaymentPayload{Payload: map[string]interface{}{}}, types.PaymentRequirements{
+		Extra: map[string]interface{}{
+			"assetTransferMethod": multiversx.TransferMethodDirect,
+		},
+	})
+	if err != nil {
+		t.Fatalf("Settle failed: %v", err)
+	}
+	if !resp.Success || resp.Transaction != "tx_hash_async" {
+		t.Errorf("Expected successful broadcast of tx_hash_async, got %+v", resp)
+	}
+	if resp.Extra["pending"] != true {
+		t.Errorf("Expected pending flag, got %v", resp.Extra["pending"])
+	}
+	if mockProxy.statusIndex != 0 {
+		t.Errorf("Expected no status polls before returning, got %d", mockProxy.statusIndex)
+	}
+
+	status, err := scheme.GetSettlementStatus(context.Background(), resp.Transaction)
+	if err != nil || status != SettlementStatusPending {
+		t.Errorf("Expected pending status, got %s (err: %v)", status, err)
+	}
+	status, err = scheme.GetSettlementStatus(context.Background(), resp.Transaction)
+	if err != nil || status != SettlementStatusSuccess {
+		t.Errorf("Expected success status, got %s (err: %v)", status, err)
+	}
+}
+
+func TestSettlementStatusOf(t *testing.T) {
+	tests := map[string]SettlementStatus{
+		"success":                    SettlementStatusSuccess,
+		"executed":                   SettlementStatusSuccess,
+		"fail":                       SettlementStatusFailed,
+		"fail (error: out of funds)": SettlementStatusFailed,
+		"invalid":                    SettlementStatusFailed,
+		"pending":                    SettlementStatusPending,
+		"received":                   SettlementStatusPending,
+		"partially-executed":         SettlementStatusPending,
+		"reward-reverted":            SettlementStatusFailed,
+		"unknown":                    SettlementStatusPending,
+	}
+	for status, want := range tests {
+		if got := settlementStatusOf(status); got != want {
+			t.Errorf("settlementStatusOf(%q) = %s; want %s", status, got, want)
+		}
+	}
+}
+
+func TestVerify_RejectsMalformedAddressesEarly(t *testing.T) {
+	var simulated int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&simulated, 1)
+	}))
+	defer server.Close()
+	scheme, _ := NewExactMultiversXScheme(server.URL, &MockSigner{})
+
+	validAddr := "erd1qyu5wthldzr8wx5c9ucg8kjagg0jfs53s8nr3zpz3hypefsdd8ssycr6th"
+
+	tests := []struct {
+		name     string
+		sender   string
+		receiver string
+		want     string
+	}{
+		{name: "Malformed sender", sender: "erd1invalid", receiver: validAddr, want: multiversx.ErrCodeInvalidSender},
+		{name: "Bad sender checksum", sender: validAddr[:len(validAddr)-1] + "x", receiver: validAddr, want: multiversx.ErrCodeInvalidSender},
+		{name: "Empty sender", sender: "", receiver: validAddr, want: multiversx.ErrCodeInvalidSender},
+		{name: "Malformed receiver", sender: validAddr, receiver: "not-an-address", want: multiversx.ErrCodeInvalidAddress},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload := multiversx.ExactRelayedPayload{
+				Sender:    tt.sender,
+				Receiver:  tt.receiver,
+				Value:     "1000",
+				ChainID:   "D",
+				Version:   1,
+				Signature: "00",
+			}
+			req := types.PaymentRequirements{PayTo: validAddr, Amount: "1000", Asset: multiversx.NativeTokenTicker}
+
+			_, err := scheme.Verify(context.Background(), types.PaymentPayload{Payload: payload.ToMap()}, req)
+			var verifyErr *x402.VerifyError
+			if !errors.As(err, &verifyErr) {
+				t.Fatalf("expected VerifyError, got %v", err)
+			}
+			if verifyErr.Reason != tt.want {
+				t.Errorf("reason = %s, want %s", verifyErr.Reason, tt.want)
+			}
+		})
+	}
+
+	if simulated != 0 {
+		t.Errorf("simulation called %d times for malformed addresses", simulated)
+	}
+}
+
+func TestVerify_ScheduledValidAfter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"data":{"result":{"status":"success","hash":"sim_hash"}},"error":""}`))
+	}))
+	defer server.Close()
+
+	current := time.Now()
+	scheme, _ := NewExactMultiversXScheme(server.URL, &MockSigner{}, WithClock(func() time.Time { return current }))
+
+	pubKey, privKey, _ := ed25519.GenerateKey(nil)
+	senderAddr, _ := data.NewAddressFromBytes(pubKey).AddressAsBech32String()
+
+	activation := current.Add(7 * 24 * time.Hour)
+	payload := multiversx.ExactRelayedPayload{
+		Nonce:       1,
+		Value:       "1000",
+		Receiver:    senderAddr,
+		Sender:      senderAddr,
+		GasPrice:    1000000000,
+		GasLimit:    50000,
+		ChainID:     "D",
+		Version:     1,
+		ValidAfter:  uint64(activation.Unix()),
+		ValidBefore: uint64(activation.Add(time.Hour).Unix()),
+	}
+	tx := payload.ToTransaction()
+	txBytes, _ := multiversx.SerializeTransaction(&tx)
+	payload.Signature = hex.EncodeToString(ed25519.Sign(privKey, txBytes))
+
+	req := types.PaymentRequirements{
+		PayTo:  senderAddr,
+		Amount: "1000",
+		Asset:  multiversx.NativeTokenTicker,
+		Extra: map[string]interface{}{
+			"assetTransferMethod": multiversx.TransferMethodDirect,
+		},
+	}
+	paymentPayload := types.PaymentPayload{Payload: payload.ToMap()}
+
+	if _, err := scheme.Verify(context.Background(), paymentPayload, req); err == nil || !strings.Contains(err.Error(), "not yet valid") {
+		t.Fatalf("Expected scheduled payment to be rejected before validAfter, got %v", err)
+	}
+
+	current = activation.Add(time.Minute)
+	resp, err := scheme.Verify(context.Background(), paymentPayload, req)
+	if err != nil {
+		t.Fatalf("Expected scheduled payment to verify after validAfter, got %v", err)
+	}
+	if !resp.IsValid {
+		t.Error("Expected valid")
+	}
+
+	current = activation.Add(2 * time.Hour)
+	if _, err := scheme.Verify(context.Background(), paymentPayload, req); err == nil || !strings.Contains(err.Error(), "expired") {
+		t.Errorf("Expected payment to expire after validBefore, got %v", err)
+	}
+}
+
+func TestVerifyViaSimulation_ContractRejected(t *testing.T) {
+	// Topics are base64 encoded: the caller address, then "subscription already active"
+	message := "subscription already active"
+	body := fmt.Sprintf(`{"data":{"result":{"status":"fail","failReason":"%s","logs":{"events":[`+
+		`{"identifier":"signalError","topics":["%s","%s"]}]}}},"error":"","code":"successful"}`,
+		message,
+		base64.StdEncoding.EncodeToString([]byte("caller")),
+		base64.StdEncoding.EncodeToString([]byte(message)))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	scheme, _ := NewExactMultiversXScheme(server.URL, nil)
+
+	_, err := scheme.verifyViaSimulation(context.Background(), multiversx.ExactRelayedPayload{Sender: "erd1sender", Value: "0"})
+	var vErr *x402.VerifyError
+	if !errors.As(err, &vErr) {
+		t.Fatalf("Expected *x402.VerifyError, got %T: %v", err, err)
+	}
+	if vErr.Reason != multiversx.ErrCodeContractRejected {
+		t.Errorf("Expected reason %s, got %s", multiversx.ErrCodeContractRejected, vErr.Reason)
+	}
+
+	var rejected *multiversx.ContractRejectedError
+	if !errors.As(err, &rejected) {
+		t.Fatalf("Expected *multiversx.ContractRejectedError in chain, got %v", err)
+	}
+	if rejected.Message != message {
+		t.Errorf("Expected message %q, got %q", message, rejected.Message)
+	}
+}
+
+func TestVerifyAgainstAny(t *testing.T) {
+	var simulations int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&simulations, 1)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"data":{"result":{"status":"success","hash":"sim_hash"}},"error":""}`))
+	}))
+	defer server.Close()
+
+	scheme, _ := NewExactMultiversXScheme(server.URL, &MockSigner{})
+
+	pubKey, privKey, _ := ed25519.GenerateKey(nil)
+	senderAddr, _ := data.NewAddressFromBytes(pubKey).AddressAsBech32String()
+
+	payload := multiversx.ExactRelayedPayload{
+		Nonce:    1,
+		Value:    "1000",
+		Receiver: senderAddr,
+		Sender:   senderAddr,
+		GasPrice: 1000000000,
+		GasLimit: 50000,
+		ChainID:  "D",
+		Version:  1,
+	}
+	tx := payload.ToTransaction()
+	txBytes, _ := multiversx.SerializeTransaction(&tx)
+	payload.Signature = hex.EncodeToString(ed25519.Sign(privKey, txBytes))
+	paymentPayload := types.PaymentPayload{Payload: payload.ToMap()}
+
+	direct := map[string]interface{}{"assetTransferMethod": multiversx.TransferMethodDirect}
+	reqs := []types.PaymentRequirements{
+		{PayTo: senderAddr, Amount: "1000", Asset: "USDC-c76f1f"},
+		{PayTo: senderAddr, Amount: "1000", Asset: multiversx.NativeTokenTicker, Extra: direct},
+		{PayTo: senderAddr, Amount: "5000", Asset: multiversx.NativeTokenTicker, Extra: direct},
+	}
+
+	resp, index, err := scheme.VerifyAgainstAny(context.Background(), paymentPayload, reqs)
+	if err != nil {
+		t.Fatalf("VerifyAgainstAny failed: %v", err)
+	}
+	if !resp.IsValid || index != 1 {
+		t.Errorf("Expected valid match at index 1, got valid=%v index=%d", resp.IsValid, index)
+	}
+	if simulations != 1 {
+		t.Errorf("Expected a single simulation for the matching requirement, got %d", simulations)
+	}
+
+	_, index, err = scheme.VerifyAgainstAny(context.Background(), paymentPayload, []types.PaymentRequirements{reqs[0], reqs[2]})
+	var vErr *x402.VerifyError
+	if !errors.As(err, &vErr) || vErr.Reason != multiversx.ErrCodeNoMatchingRequirement {
+		t.Fatalf("Expected %s error, got %v", multiversx.ErrCodeNoMatchingRequirement, err)
+	}
+	if index != -1 {
+		t.Errorf("Expected index -1 when nothing matches, got %d", index)
+	}
+}
+
+func TestWaitForTx_ExponentialBackoff(t *testing.T) {
+	statuses := make([]transaction.TxStatus, 0, 7)
+	for i := 0; i < 6; i++ {
+		statuses = append(statuses, transaction.TxStatusPending)
+	}
+	statuses = append(statuses, transaction.TxStatusSuccess)
+
+	var delays []time.Duration
+	scheme := &ExactMultiversXScheme{proxy: &MockProxy{statusResponses: statuses}}
+	WithPollInterval(500 * time.Millisecond)(scheme)
+	WithMaxPollInterval(5 * time.Second)(scheme)
+	scheme.sleep = func(ctx context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return nil
+	}
+
+	if err := scheme.waitForTx(context.Background(), "tx_hash", nil); err != nil {
+		t.Fatalf("waitForTx failed: %v", err)
+	}
+
+	// Base intervals double from 500ms up to the 5s cap; jitter keeps each delay in [base/2, base]
+	bases := []time.Duration{
+		500 * time.Millisecond,
+		time.Second,
+		2 * time.Second,
+		4 * time.Second,
+		5 * time.Second,
+		5 * time.Second,
+		5 * time.Second,
+	}
+	if len(delays) != len(bases) {
+		t.Fatalf("Expected %d polls, got %d (%v)", len(bases), len(delays), delays)
+	}
+	for i, base := range bases {
+		if delays[i] < base/2 || delays[i] > base {
+			t.Errorf("Poll %d delay %s outside [%s, %s]", i, delays[i], base/2, base)
+		}
+	}
+}
+
+func TestWaitForTx_TerminalStatuses(t *testing.T) {
+	tests := []struct {
+		status  transaction.TxStatus
+		wantErr bool
+	}{
+		{transaction.TxStatusSuccess, false},
+		{"successful", false},
+		{transaction.TxStatusFail, true},
+		{transaction.TxStatusInvalid, true},
+		{transaction.TxStatusRewardReverted, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			mockProxy := &MockProxy{statusResponses: []transaction.TxStatus{"partially-executed", tt.status}}
+			scheme := &ExactMultiversXScheme{proxy: mockProxy}
+			scheme.sleep = func(ctx context.Context, d time.Duration) error { return nil }
+
+			err := scheme.waitForTx(context.Background(), "tx_hash", nil)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("waitForTx() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if mockProxy.statusIndex != 2 {
+				t.Errorf("Expected 2 polls, got %d", mockProxy.statusIndex)
+			}
+		})
+	}
+}
+
+func TestWaitForTx_ResultLookups(t *testing.T) {
+	t.Run("Transient", func(t *testing.T) {
+		mockProxy := &MockProxy{statusResponses: []transaction.TxStatus{transaction.TxStatusSuccess}, txResultsFailures: DefaultResultLookups - 1}
+		scheme := &ExactMultiversXScheme{proxy: mockProxy}
+		scheme.sleep = func(ctx context.Context, d time.Duration) error { return nil }
+
+		if err := scheme.waitForTx(context.Background(), "tx_hash", nil); err != nil {
+			t.Fatalf("waitForTx() error = %v", err)
+		}
+		if mockProxy.txResultsCalls != DefaultResultLookups {
+			t.Errorf("results lookups = %d, want %d", mockProxy.txResultsCalls, DefaultResultLookups)
+		}
+	})
+
+	t.Run("Unknown", func(t *testing.T) {
+		mockProxy := &MockProxy{sendHash: "tx_hash", statusResponses: []transaction.TxStatus{transaction.TxStatusSuccess}, txResultsFailures: 100}
+		scheme := &ExactMultiversXScheme{proxy: mockProxy}
+		scheme.sleep = func(ctx context.Context, d time.Duration) error { return nil }
+
+		// Settle stops after the bounded lookups instead of polling until the timeout as tx_failed
+		requirements := types.PaymentRequirements{Extra: map[string]interface{}{"assetTransferMethod": multiversx.TransferMethodDirect}}
+		_, err := scheme.Settle(context.Background(), queuedPayload("erd1sender", 1), requirements)
+		var sErr *x402.SettleError
+		if !errors.As(err, &sErr) || sErr.Reason != multiversx.ErrCodeResultUnknown {
+			t.Fatalf("Settle() error = %v, want %s", err, multiversx.ErrCodeResultUnknown)
+		}
+		if sErr.Transaction != "tx_hash" {
+			t.Errorf("transaction = %s, want tx_hash", sErr.Transaction)
+		}
+		if mockProxy.txResultsCalls != DefaultResultLookups {
+			t.Errorf("results lookups = %d, want %d", mockProxy.txResultsCalls, DefaultResultLookups)
+		}
+	})
+}
+
+func TestWaitForTx_ContextCancelled(t *testing.T) {
+	scheme := &ExactMultiversXScheme{proxy: &MockProxy{}}
+	WithPollInterval(time.Hour)(scheme)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := scheme.waitForTx(ctx, "tx_hash", nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestVerify_UnsupportedVersion(t *testing.T) {
+	server := httptest.NewServer(nil)
+	defer server.Close()
+	scheme, _ := NewExactMultiversXScheme(server.URL, &MockSigner{})
+
+	validAddr := "erd1qyu5wthldzr8wx5c9ucg8kjagg0jfs53s8nr3zpz3hypefsdd8ssycr6th"
+	payload := multiversx.ExactRelayedPayload{Sender: validAddr, Receiver: validAddr, Value: "1000", ChainID: "D", Version: 1}
+	req := types.PaymentRequirements{PayTo: validAddr, Amount: "1000", Asset: multiversx.NativeTokenTicker}
+
+	_, err := scheme.Verify(context.Background(), types.PaymentPayload{X402Version: 3, Payload: payload.ToMap()}, req)
+	var vErr *x402.VerifyError
+	if !errors.As(err, &vErr) {
+		t.Fatalf("Expected *x402.VerifyError, got %v", err)
+	}
+	if vErr.Reason != multiversx.ErrCodeUnsupportedVersion {
+		t.Errorf("Expected reason %s, got %s", multiversx.ErrCodeUnsupportedVersion, vErr.Reason)
+	}
+
+	extra := scheme.GetExtra("multiversx:D")
+	if versions, _ := extra[multiversx.ExtraX402Versions].([]int); !multiversx.ContainsX402Version(versions, x402.ProtocolVersion) {
+		t.Errorf("Expected GetExtra to advertise version %d, got %v", x402.ProtocolVersion, extra)
+	}
+
+	// Restricting the accepted versions rejects payloads that were valid by default
+	restricted, _ := NewExactMultiversXScheme(server.URL, &MockSigner{}, WithSupportedVersions(1))
+	_, err = restricted.Verify(context.Background(), types.PaymentPayload{X402Version: 2, Payload: payload.ToMap()}, req)
+	if !errors.As(err, &vErr) || vErr.Reason != multiversx.ErrCodeUnsupportedVersion {
+		t.Errorf("Expected %s for version 2 on a v1-only facilitator, got %v", multiversx.ErrCodeUnsupportedVersion, err)
+	}
+}
+
+func TestVerifyViaSimulation_RetriesTransientFailures(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"data":{"result":{"status":"success","hash":"sim_hash"}},"error":""}`))
+	}))
+	defer server.Close()
+
+	scheme, _ := NewExactMultiversXScheme(server.URL, &MockSigner{})
+	var delays []time.Duration
+	scheme.sleep = func(ctx context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return nil
+	}
+
+	pubKey, privKey, _ := ed25519.GenerateKey(nil)
+	senderAddr, _ := data.NewAddressFromBytes(pubKey).AddressAsBech32String()
+	payload := multiversx.ExactRelayedPayload{
+		Nonce:    1,
+		Value:    "1000",
+		Receiver: senderAddr,
+		Sender:   senderAddr,
+		GasPrice: 1000000000,
+		GasLimit: 50000,
+		ChainID:  "D",
+		Version:  1,
+	}
+	tx := payload.ToTransaction()
+	txBytes, _ := multiversx.SerializeTransaction(&tx)
+	payload.Signature = hex.EncodeToString(ed25519.Sign(privKey, txBytes))
+
+	req := types.PaymentRequirements{
+		PayTo:  senderAddr,
+		Amount: "1000",
+		Asset:  multiversx.NativeTokenTicker,
+		Extra: map[string]interface{}{
+			"assetTransferMethod": multiversx.TransferMethodDirect,
+		},
+	}
+
+	resp, err := scheme.Verify(context.Background(), types.PaymentPayload{Payload: payload.ToMap()}, req)
+	if err != nil {
+		t.Fatalf("Expected Verify to pass after retries, got %v", err)
+	}
+	if !resp.IsValid {
+		t.Error("Expected valid")
+	}
+	if calls != 3 {
+		t.Errorf("Expected 3 simulation attempts, got %d", calls)
+	}
+	if len(delays) != 2 || delays[1] < delays[0]/2 {
+		t.Errorf("Expected 2 backoff delays, got %v", delays)
+	}
+}
+
+func TestVerifyViaSimulation_RetryLimits(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		attempts  int
+		wantCalls int32
+	}{
+		{"Client Error Not Retried", http.StatusBadRequest, 3, 1},
+		{"Server Error Retried Up To Limit", http.StatusBadGateway, 3, 3},
+		{"Configured Attempts", http.StatusBadGateway, 5, 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&calls, 1)
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			scheme, _ := NewExactMultiversXScheme(server.URL, nil, WithSimulationRetries(tt.attempts))
+			scheme.sleep = func(ctx context.Context, d time.Duration) error { return nil }
+
+			if _, err := scheme.verifyViaSimulation(context.Background(), multiversx.ExactRelayedPayload{Sender: "erd1sender", Value: "0"}); err == nil {
+				t.Fatal("Expected simulation error")
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("Expected %d attempts, got %d", tt.wantCalls, calls)
+			}
+		})
+	}
+}
+
+func TestVerify_HTTPClientTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := &http.Client{Timeout: time.Millisecond}
+	scheme, _ := NewExactMultiversXScheme(server.URL, &MockSigner{}, WithHTTPClient(client), WithSimulationRetries(1))
+
+	pubKey, privKey, _ := ed25519.GenerateKey(nil)
+	senderAddr, _ := data.NewAddressFromBytes(pubKey).AddressAsBech32String()
+	payload := multiversx.ExactRelayedPayload{
+		Nonce:    1,
+		Value:    "1000",
+		Receiver: senderAddr,
+		Sender:   senderAddr,
+		GasPrice: 1000000000,
+		GasLimit: 50000,
+		ChainID:  "D",
+		Version:  1,
+	}
+	tx := payload.ToTransaction()
+	txBytes, _ := multiversx.SerializeTransaction(&tx)
+	payload.Signature = hex.EncodeToString(ed25519.Sign(privKey, txBytes))
+
+	req := types.PaymentRequirements{
+		PayTo:  senderAddr,
+		Amount: "1000",
+		Asset:  multiversx.NativeTokenTicker,
+		Extra: map[string]interface{}{
+			"assetTransferMethod": multiversx.TransferMethodDirect,
+		},
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := scheme.Verify(context.Background(), types.PaymentPayload{Payload: payload.ToMap()}, req)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		var netErr net.Error
+		if !errors.As(err, &netErr) || !netErr.Timeout() {
+			t.Errorf("Expected a timeout error, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Verify hung despite the client timeout")
+	}
+}
+
+func TestGasPriceBounds(t *testing.T) {
+	validAddr := "erd1qyu5wthldzr8wx5c9ucg8kjagg0jfs53s8nr3zpz3hypefsdd8ssycr6th"
+	direct := types.PaymentRequirements{
+		PayTo:  validAddr,
+		Amount: "1000",
+		Asset:  multiversx.NativeTokenTicker,
+		Extra: map[string]interface{}{
+			"assetTransferMethod": multiversx.TransferMethodDirect,
+		},
+	}
+
+	tests := []struct {
+		name       string
+		gasPrice   uint64
+		wantReason string
+	}{
+		{"Below Network Minimum", 999_999_999, multiversx.ErrCodeGasTooLow},
+		{"Above Cap", 3_000_000_001, multiversx.ErrCodeGasPriceTooHigh},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockProxy := &MockProxy{
+				sendHash:        "tx_hash",
+				statusResponses: []transaction.TxStatus{transaction.TxStatusSuccess},
+				networkConfig:   &data.NetworkConfig{MinGasPrice: 1_000_000_000},
+			}
+			scheme := &ExactMultiversXScheme{proxy: mockProxy}
+			WithGasPriceBounds(3)(scheme)
+
+			payload := multiversx.ExactRelayedPayload{
+				Sender:    validAddr,
+				Receiver:  validAddr,
+				Value:     "1000",
+				GasPrice:  tt.gasPrice,
+				GasLimit:  50000,
+				ChainID:   "D",
+				Version:   1,
+				Signature: strings.Repeat("00", 64),
+			}
+			paymentPayload := types.PaymentPayload{Payload: payload.ToMap()}
+
+			_, err := scheme.Verify(context.Background(), paymentPayload, direct)
+			var vErr *x402.VerifyError
+			if !errors.As(err, &vErr) || vErr.Reason != tt.wantReason {
+				t.Errorf("Verify() expected reason %s, got %v", tt.wantReason, err)
+			}
+
+			_, err = scheme.Settle(context.Background(), paymentPayload, direct)
+			var sErr *x402.SettleError
+			if !errors.As(err, &sErr) || sErr.Reason != tt.wantReason {
+				t.Errorf("Settle() expected reason %s, got %v", tt.wantReason, err)
+			}
+		})
+	}
+
+	t.Run("Within Bounds", func(t *testing.T) {
+		mockProxy := &MockProxy{
+			sendHash:        "tx_hash",
+			statusResponses: []transaction.TxStatus{transaction.TxStatusSuccess},
+			networkConfig:   &data.NetworkConfig{MinGasPrice: 1_000_000_000},
+		}
+		scheme := &ExactMultiversXScheme{proxy: mockProxy, asyncSettle: true}
+		WithGasPriceBounds(3)(scheme)
+
+		payload := multiversx.ExactRelayedPayload{Sender: validAddr, Receiver: validAddr, Value: "1000", GasPrice: 3_000_000_000}
+		if _, err := scheme.Settle(context.Background(), types.PaymentPayload{Payload: payload.ToMap()}, direct); err != nil {
+			t.Errorf("Settle() within bounds failed: %v", err)
+		}
+	})
+}
+
+func TestVerify_CancelAbortsSimulation(t *testing.T) {
+	received := make(chan struct{})
+	aborted := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The server only notices a closed connection once the body has been consumed
+		io.ReadAll(r.Body)
+		close(received)
+		select {
+		case <-r.Context().Done():
+			close(aborted)
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer server.Close()
+
+	scheme, _ := NewExactMultiversXScheme(server.URL, &MockSigner{}, WithSimulationRetries(1))
+
+	pubKey, privKey, _ := ed25519.GenerateKey(nil)
+	senderAddr, _ := data.NewAddressFromBytes(pubKey).AddressAsBech32String()
+	payload := multiversx.ExactRelayedPayload{
+		Nonce:    1,
+		Value:    "1000",
+		Receiver: senderAddr,
+		Sender:   senderAddr,
+		GasPrice: 1000000000,
+		GasLimit: 50000,
+		ChainID:  "D",
+		Version:  1,
+	}
+	tx := payload.ToTransaction()
+	txBytes, _ := multiversx.SerializeTransaction(&tx)
+	payload.Signature = hex.EncodeToString(ed25519.Sign(privKey, txBytes))
+
+	req := types.PaymentRequirements{
+		PayTo:  senderAddr,
+		Amount: "1000",
+		Asset:  multiversx.NativeTokenTicker,
+		Extra: map[string]interface{}{
+			"assetTransferMethod": multiversx.TransferMethodDirect,
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := scheme.Verify(ctx, types.PaymentPayload{Payload: payload.ToMap()}, req)
+		done <- err
+	}()
+
+	<-received
+	cancel()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Verify did not return after the context was cancelled")
+	}
+
+	select {
+	case <-aborted:
+	case <-time.After(2 * time.Second):
+		t.Error("Simulation request was not aborted")
+	}
+}
+
+func TestVerify_TypedErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"data":{"result":{"status":"success","hash":"sim_hash"}},"error":""}`))
+	}))
+	defer server.Close()
+
+	current := time.Now()
+	scheme, _ := NewExactMultiversXScheme(server.URL, &MockSigner{}, WithClock(func() time.Time { return current }))
+
+	pubKey, privKey, _ := ed25519.GenerateKey(nil)
+	senderAddr, _ := data.NewAddressFromBytes(pubKey).AddressAsBech32String()
+	otherAddr := "erd1qyu5wthldzr8wx5c9ucg8kjagg0jfs53s8nr3zpz3hypefsdd8ssycr6th"
+
+	sign := func(p multiversx.ExactRelayedPayload) types.PaymentPayload {
+		tx := p.ToTransaction()
+		txBytes, _ := multiversx.SerializeTransaction(&tx)
+		p.Signature = hex.EncodeToString(ed25519.Sign(privKey, txBytes))
+		return types.PaymentPayload{Payload: p.ToMap()}
+	}
+	base := multiversx.ExactRelayedPayload{
+		Nonce:       1,
+		Value:       "1000",
+		Receiver:    senderAddr,
+		Sender:      senderAddr,
+		GasPrice:    1000000000,
+		GasLimit:    50000,
+		ChainID:     "D",
+		Version:     1,
+		ValidAfter:  uint64(current.Add(-time.Minute).Unix()),
+		ValidBefore: uint64(current.Add(time.Hour).Unix()),
+	}
+	expired := base
+	expired.ValidBefore = uint64(current.Add(-time.Second).Unix())
+	expired.ValidAfter = uint64(current.Add(-time.Hour).Unix())
+	scheduled := base
+	scheduled.ValidAfter = uint64(current.Add(time.Minute).Unix())
+
+	direct := map[string]interface{}{"assetTransferMethod": multiversx.TransferMethodDirect}
+	tests := []struct {
+		name       string
+		payload    multiversx.ExactRelayedPayload
+		req        types.PaymentRequirements
+		wantReason string
+	}{
+		{"Receiver Mismatch", base, types.PaymentRequirements{PayTo: otherAddr, Amount: "1000", Asset: multiversx.NativeTokenTicker, Extra: direct}, multiversx.ErrCodeReceiverMismatch},
+		{"Amount Mismatch", base, types.PaymentRequirements{PayTo: senderAddr, Amount: "2000", Asset: multiversx.NativeTokenTicker, Extra: direct}, multiversx.ErrCodeAmountMismatch},
+		{"Expired", expired, types.PaymentRequirements{PayTo: senderAddr, Amount: "1000", Asset: multiversx.NativeTokenTicker, Extra: direct}, x402.ErrCodePaymentExpired},
+		{"Not Yet Valid", scheduled, types.PaymentRequirements{PayTo: senderAddr, Amount: "1000", Asset: multiversx.NativeTokenTicker, Extra: direct}, multiversx.ErrCodePaymentNotYetValid},
+		{"Missing Asset", base, types.PaymentRequirements{PayTo: senderAddr, Amount: "1000", Extra: direct}, multiversx.ErrCodeInvalidRequirements},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := scheme.Verify(context.Background(), sign(tt.payload), tt.req)
+			var vErr *x402.VerifyError
+			if !errors.As(err, &vErr) {
+				t.Fatalf("expected VerifyError, got %v", err)
+			}
+			if vErr.Reason != tt.wantReason {
+				t.Errorf("reason = %s, want %s", vErr.Reason, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestSettle_ReportsAmountCharged(t *testing.T) {
+	payTo := "erd1spyavw0956vq68xj8y4tenjpq2wd5a9p2c6j8gsz7ztyrnpxrruqzu66jx"
+	esdtReq := types.PaymentRequirements{PayTo: payTo, Amount: "1000", Asset: "USDC-c76f1f"}
+	handler, _ := multiversx.GetTransferMethodHandler(multiversx.TransferMethodESDT)
+	fields, err := handler.Encode(types.PaymentRequirements{PayTo: payTo, Amount: "750", Asset: "USDC-c76f1f"}, payTo)
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+
+	egldFields, err := handler.Encode(types.PaymentRequirements{PayTo: payTo, Amount: "600", Asset: multiversx.NativeTokenMultiTransferID}, payTo)
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+
+	var txJSON string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(txJSON))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name        string
+		txJSON      string
+		req         types.PaymentRequirements
+		wantCharged interface{}
+	}{
+		{
+			name:        "ESDT Transfer",
+			txJSON:      fmt.Sprintf(`{"data":{"transaction":{"gasUsed":50000,"fee":"1","value":"0","data":"%s"}},"error":""}`, base64.StdEncoding.EncodeToString([]byte(fields.Data))),
+			req:         esdtReq,
+			wantCharged: "750",
+		},
+		{
+			name:        "EGLD Value",
+			txJSON:      `{"data":{"transaction":{"gasUsed":50000,"fee":"1","value":"400"}},"error":""}`,
+			req:         types.PaymentRequirements{PayTo: payTo, Amount: "500", Asset: multiversx.NativeTokenTicker},
+			wantCharged: "400",
+		},
+		{
+			name:        "EGLD As EGLD-000000",
+			txJSON:      fmt.Sprintf(`{"data":{"transaction":{"gasUsed":50000,"fee":"1","value":"0","data":"%s"}},"error":""}`, base64.StdEncoding.EncodeToString([]byte(egldFields.Data))),
+			req:         types.PaymentRequirements{PayTo: payTo, Amount: "600", Asset: multiversx.NativeTokenTicker},
+			wantCharged: "600",
+		},
+		{
+			name:        "Other Token Only",
+			txJSON:      fmt.Sprintf(`{"data":{"transaction":{"gasUsed":50000,"fee":"1","value":"0","data":"%s"}},"error":""}`, base64.StdEncoding.EncodeToString([]byte(fields.Data))),
+			req:         types.PaymentRequirements{PayTo: payTo, Amount: "1000", Asset: "WEGLD-bd4d79"},
+			wantCharged: nil,
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			txJSON = tt.txJSON
+			mockProxy := &MockProxy{
+				sendHash:        "tx_hash_charged",
+				statusResponses: []transaction.TxStatus{transaction.TxStatusSuccess},
+			}
+			scheme, _ := NewExactMultiversXScheme(server.URL, nil, WithPollInterval(time.Millisecond))
+			scheme.proxy = mockProxy
+
+			req := tt.req
+			req.Extra = map[string]interface{}{"assetTransferMethod": multiversx.TransferMethodDirect}
+			resp, err := scheme.Settle(context.Background(), types.PaymentPayload{Payload: map[string]interface{}{"nonce": float64(i)}}, req)
+			if err != nil {
+				t.Fatalf("Settle failed: %v", err)
+			}
+			if resp.Extra["amountCharged"] != tt.wantCharged {
+				t.Errorf("amountCharged = %v, want %v", resp.Extra["amountCharged"], tt.wantCharged)
+			}
+		})
+	}
+}
+
+func TestSettle_UsesSignedRelayer(t *testing.T) {
+	mockProxy := &MockProxy{sendHash: "relayed_hash"}
+	scheme := &ExactMultiversXScheme{proxy: mockProxy, signer: &MockSigner{}, asyncSettle: true}
+
+	tests := []struct {
+		name       string
+		payload    multiversx.ExactRelayedPayload
+		wantReason string
+	}{
+		{"Other Relayer", multiversx.ExactRelayedPayload{Sender: "erd1sender", Nonce: 1, Version: 2, Relayer: "erd1other"}, "relayer_mismatch"},
+		{"Missing Relayer", multiversx.ExactRelayedPayload{Sender: "erd1sender", Nonce: 2, Version: 2}, "relayer_mismatch"},
+		{"Version 1", multiversx.ExactRelayedPayload{Sender: "erd1sender", Nonce: 3, Version: 1, Relayer: "erd1test"}, multiversx.ErrCodeInconsistentTransaction},
+		{"Signed Relayer", multiversx.ExactRelayedPayload{Sender: "erd1sender", Nonce: 4, Version: 2, Relayer: "erd1test"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := scheme.Settle(context.Background(), types.PaymentPayload{Payload: tt.payload.ToMap()}, types.PaymentRequirements{})
+			if tt.wantReason == "" {
+				if err != nil || resp.Transaction != "relayed_hash" {
+					t.Fatalf("Settle() = %v, %v", resp, err)
+				}
+				return
+			}
+			var sErr *x402.SettleError
+			if !errors.As(err, &sErr) || sErr.Reason != tt.wantReason {
+				t.Errorf("Settle() error = %v, want %s", err, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestSupportedKinds(t *testing.T) {
+	scheme, _ := NewExactMultiversXScheme("http://localhost", &MockSigner{})
+
+	kinds := scheme.SupportedKinds()
+	if len(kinds) != len(multiversx.Networks) {
+		t.Fatalf("Expected one kind per network, got %d", len(kinds))
+	}
+
+	facilitator := x402.Newx402Facilitator().Register(multiversx.Networks, scheme)
+	supported := facilitator.GetSupported()
+
+	var devnet *types.SupportedKind
+	for i, kind := range supported.Kinds {
+		if kind.Network == "multiversx:D" && kind.Scheme == multiversx.SchemeExact {
+			devnet = &supported.Kinds[i]
+		}
+	}
+	if devnet == nil {
+		t.Fatalf("Expected devnet exact kind in %+v", supported.Kinds)
+	}
+	if devnet.X402Version != x402.ProtocolVersion {
+		t.Errorf("X402Version = %d, want %d", devnet.X402Version, x402.ProtocolVersion)
+	}
+
+	methods, ok := devnet.Extra["assetTransferMethods"].([]string)
+	if !ok || !slices.Contains(methods, multiversx.TransferMethodDirect) || !slices.Contains(methods, multiversx.TransferMethodESDT) {
+		t.Errorf("Expected direct and esdt transfer methods, got %v", devnet.Extra["assetTransferMethods"])
+	}
+
+	for _, kind := range kinds {
+		if !slices.ContainsFunc(supported.Kinds, func(k types.SupportedKind) bool {
+			return k.Network == kind.Network && k.Scheme == kind.Scheme && k.X402Version == kind.X402Version
+		}) {
+			t.Errorf("GetSupported is missing %s %s", kind.Network, kind.Scheme)
+		}
+	}
+
+	if signers := supported.Signers[scheme.CaipFamily()]; !slices.Contains(signers, "erd1test") {
+		t.Errorf("Expected facilitator signer in %v", supported.Signers)
+	}
+}
+
+func TestVerify_RejectsMalformedSignatureEarly(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+	}))
+	defer server.Close()
+	scheme, _ := NewExactMultiversXScheme(server.URL, &MockSigner{}, WithHerotagResolver(func(ctx context.Context, herotag string) (string, error) {
+		atomic.AddInt32(&requests, 1)
+		return "", errors.New("unexpected resolution")
+	}))
+
+	validAddr := "erd1qyu5wthldzr8wx5c9ucg8kjagg0jfs53s8nr3zpz3hypefsdd8ssycr6th"
+	tests := []struct {
+		name       string
+		signature  string
+		wantReason string
+	}{
+		{"Short Signature", strings.Repeat("ab", 63), "invalid_signature_length"},
+		{"Non Hex Signature", strings.Repeat("zz", 64), "invalid_signature_hex"},
+		{"Missing Signature", "", x402.ErrCodeSignatureInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload := multiversx.ExactRelayedPayload{
+				Sender:    validAddr,
+				Receiver:  validAddr,
+				Value:     "1000",
+				ChainID:   "D",
+				Version:   1,
+				Signature: tt.signature,
+			}
+			req := types.PaymentRequirements{PayTo: "@alice", Amount: "1000", Asset: multiversx.NativeTokenTicker}
+
+			_, err := scheme.Verify(context.Background(), types.PaymentPayload{Payload: payload.ToMap()}, req)
+			var vErr *x402.VerifyError
+			if !errors.As(err, &vErr) || vErr.Reason != tt.wantReason {
+				t.Errorf("Verify() error = %v, want reason %s", err, tt.wantReason)
+			}
+		})
+	}
+
+	if requests != 0 {
+		t.Errorf("network called %d times for malformed signatures", requests)
+	}
+}
+
+func TestVerify_APIURLOverride(t *testing.T) {
+	var simulations int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/transaction/simulate" {
+			simulations++
+		}
+		w.Write([]byte(`{"data":{"result":{"status":"success","hash":"sim_hash"}},"error":""}`))
+	}))
+	defer server.Close()
+
+	pubKey, privKey, _ := ed25519.GenerateKey(nil)
+	senderAddr, _ := data.NewAddressFromBytes(pubKey).AddressAsBech32String()
+	payload := multiversx.ExactRelayedPayload{
+		Nonce:    1,
+		Value:    "1000",
+		Receiver: senderAddr,
+		Sender:   senderAddr,
+		GasPrice: 1000000000,
+		GasLimit: 50000,
+		ChainID:  multiversx.ChainIDDevnet,
+		Version:  1,
+	}
+	tx := payload.ToTransaction()
+	txBytes, _ := multiversx.SerializeTransaction(&tx)
+	payload.Signature = hex.EncodeToString(ed25519.Sign(privKey, txBytes))
+
+	req := types.PaymentRequirements{
+		PayTo:  senderAddr,
+		Amount: "1000",
+		Asset:  multiversx.NativeTokenTicker,
+		Extra:  map[string]interface{}{"assetTransferMethod": multiversx.TransferMethodDirect},
+	}
+
+	// The constructor gateway is unreachable: the devnet simulation must go to the override
+	scheme, _ := NewExactMultiversXScheme("http://127.0.0.1:1", &MockSigner{}, WithAPIURLOverride(multiversx.ChainIDDevnet, server.URL), WithSimulationRetries(1))
+	scheme.proxy = &MockProxy{}
+
+	if _, err := scheme.Verify(context.Background(), types.PaymentPayload{Payload: payload.ToMap()}, req); err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if simulations != 1 {
+		t.Errorf("simulations on the override = %d, want 1", simulations)
+	}
+}
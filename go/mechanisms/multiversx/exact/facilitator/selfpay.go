@@ -0,0 +1,37 @@
+package facilitator
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/multiversx/mx-sdk-go/data"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/mechanisms/multiversx"
+)
+
+// WithSelfPaymentGuard makes Verify reject, with self_payment, payments whose funds go back to the sender
+// A transaction sent to its own sender is allowed only as the ESDT self-transfer, whose MultiESDTNFTTransfer
+// data names another destination. Such payments move no funds and usually come from a misconfigured PayTo.
+func WithSelfPaymentGuard() Option {
+	return func(s *ExactMultiversXScheme) {
+		s.selfPaymentGuard = true
+	}
+}
+
+// checkSelfPayment rejects a payload paying its own sender when the guard is enabled
+func (s *ExactMultiversXScheme) checkSelfPayment(payload multiversx.ExactRelayedPayload) error {
+	if !s.selfPaymentGuard || payload.Receiver != payload.Sender {
+		return nil
+	}
+
+	// ESDT transfers are sent to self, the actual destination is in the data
+	transfer, err := multiversx.DecodeMultiESDTTransfer(payload.Data)
+	if err == nil {
+		sender, err := data.NewAddressFromBech32String(payload.Sender)
+		if err != nil || !bytes.Equal(transfer.Destination, sender.AddressBytes()) {
+			return nil
+		}
+	}
+	return x402.NewVerifyError(multiversx.ErrCodeSelfPayment, payload.Sender, "multiversx", errors.New("payment receiver is the sender"))
+}
@@ -0,0 +1,66 @@
+package facilitator
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/mechanisms/multiversx"
+	"github.com/coinbase/x402/go/types"
+)
+
+func TestWithSelfPaymentGuard(t *testing.T) {
+	sender := "erd1spyavw0956vq68xj8y4tenjpq2wd5a9p2c6j8gsz7ztyrnpxrruqzu66jx"
+	payTo := "erd1qyu5wthldzr8wx5c9ucg8kjagg0jfs53s8nr3zpz3hypefsdd8ssycr6th"
+
+	esdtTo := func(destination string) multiversx.ExactRelayedPayload {
+		handler, _ := multiversx.GetTransferMethodHandler(multiversx.TransferMethodESDT)
+		fields, err := handler.Encode(types.PaymentRequirements{PayTo: destination, Amount: "1000", Asset: "USDC-c76f1f"}, sender)
+		if err != nil {
+			t.Fatalf("Encode() error = %v", err)
+		}
+		return multiversx.ExactRelayedPayload{Sender: sender, Receiver: fields.Receiver, Value: fields.Value, Data: fields.Data}
+	}
+
+	tests := []struct {
+		name    string
+		payload multiversx.ExactRelayedPayload
+		wantErr bool
+	}{
+		{"EGLD to self", multiversx.ExactRelayedPayload{Sender: sender, Receiver: sender, Value: "1000"}, true},
+		{"EGLD to PayTo", multiversx.ExactRelayedPayload{Sender: sender, Receiver: payTo, Value: "1000"}, false},
+		{"ESDT self-transfer to PayTo", esdtTo(payTo), false},
+		{"ESDT self-transfer to self", esdtTo(sender), true},
+	}
+
+	scheme := &ExactMultiversXScheme{}
+	WithSelfPaymentGuard()(scheme)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := scheme.checkSelfPayment(tt.payload)
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("checkSelfPayment() error = %v", err)
+				}
+				return
+			}
+			var vErr *x402.VerifyError
+			if !errors.As(err, &vErr) || vErr.Reason != multiversx.ErrCodeSelfPayment {
+				t.Fatalf("checkSelfPayment() error = %v, want %s", err, multiversx.ErrCodeSelfPayment)
+			}
+		})
+	}
+
+	// Verify rejects the self-payment before checking the signature
+	_, err := scheme.Verify(context.Background(), types.PaymentPayload{Payload: tests[0].payload.ToMap()}, types.PaymentRequirements{PayTo: sender, Amount: "1000", Asset: multiversx.NativeTokenTicker})
+	var vErr *x402.VerifyError
+	if !errors.As(err, &vErr) || vErr.Reason != multiversx.ErrCodeSelfPayment {
+		t.Errorf("Verify() error = %v, want %s", err, multiversx.ErrCodeSelfPayment)
+	}
+
+	// Without the guard self-payments are left to the transfer checks
+	if err := (&ExactMultiversXScheme{}).checkSelfPayment(tests[0].payload); err != nil {
+		t.Errorf("checkSelfPayment() without the guard error = %v", err)
+	}
+}
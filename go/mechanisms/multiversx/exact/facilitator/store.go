@@ -0,0 +1,270 @@
+package facilitator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// NonceStore persists the sender nonces the facilitator has broadcast
+// Implementations backed by Redis, a database or a file keep the state across facilitator
+// restarts; ReserveNonce must be atomic when several facilitators share the store.
+type NonceStore interface {
+	// ReserveNonce records nonce as used by sender and reports false if it was already reserved
+	ReserveNonce(ctx context.Context, sender string, nonce uint64) (bool, error)
+	// ReleaseNonce frees a reservation whose transaction never reached the network
+	ReleaseNonce(ctx context.Context, sender string, nonce uint64) error
+}
+
+// SettledStore persists the payloads the facilitator has already settled
+// Payloads are keyed by their fingerprint and mapped to the hash of the settlement transaction.
+type SettledStore interface {
+	// GetSettled returns the settlement transaction hash of the payload key, if it was settled
+	GetSettled(ctx context.Context, key string) (string, bool, error)
+	// MarkSettled records the settlement transaction hash of the payload key
+	MarkSettled(ctx context.Context, key string, txHash string) error
+}
+
+// PaymentKey identifies a payment independently of the requirements it is presented against
+type PaymentKey struct {
+	Sender  string
+	Nonce   uint64
+	ChainID string
+}
+
+// ReplayStore records the payments the facilitator has settled so they cannot be replayed
+// A signed payload stays valid after settlement; without this record it could be presented
+// again, possibly against different requirements.
+type ReplayStore interface {
+	// Seen reports whether the payment was already settled
+	Seen(ctx context.Context, key PaymentKey) (bool, error)
+	// Record marks the payment as settled
+	Record(ctx context.Context, key PaymentKey) error
+}
+
+// DefaultMemoryStoreTTL is how long the in-memory stores keep an entry unless changed with WithTTL
+// It outlives any realistic payment validity window: by then the payload has expired or its nonce was
+// consumed on chain, so the network rejects a replay without the entry.
+const DefaultMemoryStoreTTL = 24 * time.Hour
+
+// expiringMap is a map whose entries are dropped once they are older than its ttl; a zero ttl keeps them forever
+// Expired entries are hidden from lookups and swept on writes at most once per ttl, so the map holds
+// at most the entries written within the last two ttl periods.
+type expiringMap[K comparable, V any] struct {
+	ttl       time.Duration
+	now       func() time.Time
+	entries   map[K]expiringEntry[V]
+	lastSweep time.Time
+}
+
+// expiringEntry is a value of an expiringMap along with its expiry, zero when it never expires
+type expiringEntry[V any] struct {
+	value   V
+	expires time.Time
+}
+
+func newExpiringMap[K comparable, V any](ttl time.Duration) *expiringMap[K, V] {
+	return &expiringMap[K, V]{ttl: ttl, now: time.Now, entries: make(map[K]expiringEntry[V])}
+}
+
+// get returns the value of key unless it is missing or expired
+func (m *expiringMap[K, V]) get(key K) (V, bool) {
+	entry, ok := m.entries[key]
+	if !ok || entry.expired(m.now()) {
+		var zero V
+		return zero, false
+	}
+	return entry.value, true
+}
+
+// set stores the value under key with a fresh expiry, sweeping expired entries first when one is due
+func (m *expiringMap[K, V]) set(key K, value V) {
+	now := m.now()
+	m.sweep(now)
+
+	entry := expiringEntry[V]{value: value}
+	if m.ttl > 0 {
+		entry.expires = now.Add(m.ttl)
+	}
+	m.entries[key] = entry
+}
+
+// delete removes key from the map
+func (m *expiringMap[K, V]) delete(key K) {
+	delete(m.entries, key)
+}
+
+// sweep drops the expired entries, at most once per ttl
+func (m *expiringMap[K, V]) sweep(now time.Time) {
+	if m.ttl <= 0 || now.Sub(m.lastSweep) < m.ttl {
+		return
+	}
+	for key, entry := range m.entries {
+		if entry.expired(now) {
+			delete(m.entries, key)
+		}
+	}
+	m.lastSweep = now
+}
+
+func (e expiringEntry[V]) expired(now time.Time) bool {
+	return !e.expires.IsZero() && !now.Before(e.expires)
+}
+
+// nonceKey identifies a sender nonce reserved in the MemoryNonceStore
+type nonceKey struct {
+	sender string
+	nonce  uint64
+}
+
+// MemoryNonceStore is the default in-memory NonceStore; its state is lost on restart
+type MemoryNonceStore struct {
+	mu       sync.Mutex
+	reserved *expiringMap[nonceKey, struct{}]
+}
+
+// NewMemoryNonceStore creates an empty in-memory nonce store
+// Every broadcast keeps its reservation, so reservations expire after DefaultMemoryStoreTTL instead of
+// accumulating for the lifetime of the process; long-running facilitators sharing state use a NonceStore
+// backed by Redis or a database with its own expiry.
+func NewMemoryNonceStore() *MemoryNonceStore {
+	return &MemoryNonceStore{reserved: newExpiringMap[nonceKey, struct{}](DefaultMemoryStoreTTL)}
+}
+
+// WithTTL sets how long new reservations are kept; zero keeps them until released
+func (m *MemoryNonceStore) WithTTL(ttl time.Duration) *MemoryNonceStore {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.reserved.ttl = ttl
+	return m
+}
+
+// ReserveNonce implements NonceStore
+func (m *MemoryNonceStore) ReserveNonce(ctx context.Context, sender string, nonce uint64) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := nonceKey{sender: sender, nonce: nonce}
+	if _, taken := m.reserved.get(key); taken {
+		return false, nil
+	}
+	m.reserved.set(key, struct{}{})
+	return true, nil
+}
+
+// ReleaseNonce implements NonceStore
+func (m *MemoryNonceStore) ReleaseNonce(ctx context.Context, sender string, nonce uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.reserved.delete(nonceKey{sender: sender, nonce: nonce})
+	return nil
+}
+
+// MemorySettledStore is the default in-memory SettledStore; its state is lost on restart
+type MemorySettledStore struct {
+	mu      sync.RWMutex
+	settled *expiringMap[string, string]
+}
+
+// NewMemorySettledStore creates an empty in-memory settled store
+// Settled payloads are forgotten after DefaultMemoryStoreTTL so the store does not grow with every
+// settlement; a payload presented again after that fails on chain instead of as already_settled.
+func NewMemorySettledStore() *MemorySettledStore {
+	return &MemorySettledStore{settled: newExpiringMap[string, string](DefaultMemoryStoreTTL)}
+}
+
+// WithTTL sets how long new settlements are kept; zero keeps them for the lifetime of the store
+func (m *MemorySettledStore) WithTTL(ttl time.Duration) *MemorySettledStore {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.settled.ttl = ttl
+	return m
+}
+
+// GetSettled implements SettledStore
+func (m *MemorySettledStore) GetSettled(ctx context.Context, key string) (string, bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	txHash, ok := m.settled.get(key)
+	return txHash, ok, nil
+}
+
+// MarkSettled implements SettledStore
+func (m *MemorySettledStore) MarkSettled(ctx context.Context, key string, txHash string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.settled.set(key, txHash)
+	return nil
+}
+
+// MemoryReplayStore is the default in-memory ReplayStore; its state is lost on restart
+type MemoryReplayStore struct {
+	mu   sync.RWMutex
+	seen *expiringMap[PaymentKey, struct{}]
+}
+
+// NewMemoryReplayStore creates an empty in-memory replay store
+// Payments are forgotten after DefaultMemoryStoreTTL so the store does not grow with every settlement;
+// by then the sender nonce is consumed on chain and a replay fails there instead of as payment_replayed.
+func NewMemoryReplayStore() *MemoryReplayStore {
+	return &MemoryReplayStore{seen: newExpiringMap[PaymentKey, struct{}](DefaultMemoryStoreTTL)}
+}
+
+// WithTTL sets how long new payments are kept; zero keeps them for the lifetime of the store
+func (m *MemoryReplayStore) WithTTL(ttl time.Duration) *MemoryReplayStore {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.seen.ttl = ttl
+	return m
+}
+
+// Seen implements ReplayStore
+func (m *MemoryReplayStore) Seen(ctx context.Context, key PaymentKey) (bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	_, ok := m.seen.get(key)
+	return ok, nil
+}
+
+// Record implements ReplayStore
+func (m *MemoryReplayStore) Record(ctx context.Context, key PaymentKey) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.seen.set(key, struct{}{})
+	return nil
+}
+
+// errNonceInUse is returned when another payload of the same sender already used the nonce
+var errNonceInUse = errors.New("nonce already used by another settlement")
+
+// nonceManager guards against broadcasting two payloads with the same sender nonce
+type nonceManager struct {
+	store NonceStore
+}
+
+// reserve claims the sender nonce for a settlement about to be broadcast
+func (n *nonceManager) reserve(ctx context.Context, sender string, nonce uint64) error {
+	ok, err := n.store.ReserveNonce(ctx, sender, nonce)
+	if err != nil {
+		return fmt.Errorf("failed to reserve nonce %d of %s: %w", nonce, sender, err)
+	}
+	if !ok {
+		return fmt.Errorf("%w: sender %s, nonce %d", errNonceInUse, sender, nonce)
+	}
+	return nil
+}
+
+// release frees the sender nonce after a failed broadcast so the payload can be retried
+func (n *nonceManager) release(ctx context.Context, sender string, nonce uint64) error {
+	return n.store.ReleaseNonce(ctx, sender, nonce)
+}
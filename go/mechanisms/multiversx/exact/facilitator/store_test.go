@@ -0,0 +1,292 @@
+package facilitator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/mechanisms/multiversx"
+	"github.com/coinbase/x402/go/types"
+)
+
+// fakeBackend stands in for an external key-value store shared across facilitator restarts
+type fakeBackend struct {
+	mu      sync.Mutex
+	values  map[string]string
+	failing bool
+}
+
+func newFakeBackend() *fakeBackend {
+	return &fakeBackend{values: make(map[string]string)}
+}
+
+func (b *fakeBackend) ReserveNonce(ctx context.Context, sender string, nonce uint64) (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failing {
+		return false, errors.New("backend unavailable")
+	}
+	key := fmt.Sprintf("nonce:%s:%d", sender, nonce)
+	if _, ok := b.values[key]; ok {
+		return false, nil
+	}
+	b.values[key] = "1"
+	return true, nil
+}
+
+func (b *fakeBackend) ReleaseNonce(ctx context.Context, sender string, nonce uint64) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.values, fmt.Sprintf("nonce:%s:%d", sender, nonce))
+	return nil
+}
+
+func (b *fakeBackend) GetSettled(ctx context.Context, key string) (string, bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failing {
+		return "", false, errors.New("backend unavailable")
+	}
+	txHash, ok := b.values["settled:"+key]
+	return txHash, ok, nil
+}
+
+func (b *fakeBackend) MarkSettled(ctx context.Context, key string, txHash string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.values["settled:"+key] = txHash
+	return nil
+}
+
+func TestSettle_StateSurvivesRestart(t *testing.T) {
+	backend := newFakeBackend()
+	requirements := types.PaymentRequirements{Extra: map[string]interface{}{"assetTransferMethod": multiversx.TransferMethodDirect}}
+	payload := queuedPayload("erd1sender", 5)
+
+	first := &ExactMultiversXScheme{proxy: &MockProxy{sendHash: "first-hash"}, asyncSettle: true}
+	WithNonceStore(backend)(first)
+	WithSettledStore(backend)(first)
+	if _, err := first.Settle(context.Background(), payload, requirements); err != nil {
+		t.Fatalf("Settle() error = %v", err)
+	}
+
+	// A new scheme instance over the same backend simulates a facilitator restart
+	restarted := &ExactMultiversXScheme{proxy: &MockProxy{sendHash: "second-hash"}, asyncSettle: true}
+	WithNonceStore(backend)(restarted)
+	WithSettledStore(backend)(restarted)
+
+	_, err := restarted.Settle(context.Background(), payload, requirements)
+	var sErr *x402.SettleError
+	if !errors.As(err, &sErr) || sErr.Reason != "already_settled" {
+		t.Fatalf("Settle() replay error = %v, want already_settled", err)
+	}
+	if sErr.Transaction != "first-hash" {
+		t.Errorf("replay transaction = %s, want first-hash", sErr.Transaction)
+	}
+
+	// A different payload reusing the sender nonce is refused as well
+	conflicting := multiversx.ExactRelayedPayload{Sender: "erd1sender", Nonce: 5, Value: "1"}
+	_, err = restarted.Settle(context.Background(), types.PaymentPayload{Payload: conflicting.ToMap()}, requirements)
+	if !errors.As(err, &sErr) || sErr.Reason != "nonce_in_use" {
+		t.Errorf("Settle() conflicting nonce error = %v, want nonce_in_use", err)
+	}
+
+	if _, err := restarted.Settle(context.Background(), queuedPayload("erd1sender", 6), requirements); err != nil {
+		t.Errorf("Settle() next nonce error = %v", err)
+	}
+}
+
+func TestSettle_FailedBroadcastReleasesNonce(t *testing.T) {
+	backend := newFakeBackend()
+	mockProxy := &MockProxy{sendErr: errors.New("connection refused")}
+	scheme := &ExactMultiversXScheme{proxy: mockProxy, asyncSettle: true}
+	WithNonceStore(backend)(scheme)
+	WithSettledStore(backend)(scheme)
+
+	requirements := types.PaymentRequirements{Extra: map[string]interface{}{"assetTransferMethod": multiversx.TransferMethodDirect}}
+	payload := queuedPayload("erd1sender", 1)
+
+	if _, err := scheme.Settle(context.Background(), payload, requirements); err == nil {
+		t.Fatal("Expected broadcast failure")
+	}
+
+	mockProxy.sendErr = nil
+	mockProxy.sendHash = "retry-hash"
+	resp, err := scheme.Settle(context.Background(), payload, requirements)
+	if err != nil {
+		t.Fatalf("Settle() retry error = %v", err)
+	}
+	if resp.Transaction != "retry-hash" {
+		t.Errorf("retry transaction = %s, want retry-hash", resp.Transaction)
+	}
+}
+
+func TestSettle_StoreUnavailable(t *testing.T) {
+	backend := newFakeBackend()
+	backend.failing = true
+	scheme := &ExactMultiversXScheme{proxy: &MockProxy{sendHash: "hash"}, asyncSettle: true}
+	WithSettledStore(backend)(scheme)
+
+	requirements := types.PaymentRequirements{Extra: map[string]interface{}{"assetTransferMethod": multiversx.TransferMethodDirect}}
+	_, err := scheme.Settle(context.Background(), queuedPayload("erd1sender", 1), requirements)
+	var sErr *x402.SettleError
+	if !errors.As(err, &sErr) || sErr.Reason != "settlement_store_unavailable" {
+		t.Errorf("Settle() error = %v, want settlement_store_unavailable", err)
+	}
+}
+
+func TestMemoryNonceStore(t *testing.T) {
+	store := NewMemoryNonceStore()
+	ctx := context.Background()
+
+	if ok, _ := store.ReserveNonce(ctx, "erd1a", 1); !ok {
+		t.Fatal("Expected first reservation to succeed")
+	}
+	if ok, _ := store.ReserveNonce(ctx, "erd1a", 1); ok {
+		t.Error("Expected duplicate reservation to fail")
+	}
+	if ok, _ := store.ReserveNonce(ctx, "erd1b", 1); !ok {
+		t.Error("Expected reservations to be per sender")
+	}
+
+	store.ReleaseNonce(ctx, "erd1a", 1)
+	if ok, _ := store.ReserveNonce(ctx, "erd1a", 1); !ok {
+		t.Error("Expected released nonce to be reservable again")
+	}
+}
+
+func TestMemoryNonceStore_ReservationsExpire(t *testing.T) {
+	store := NewMemoryNonceStore().WithTTL(time.Hour)
+	current := time.Unix(1700000000, 0)
+	store.reserved.now = func() time.Time { return current }
+	ctx := context.Background()
+
+	if ok, _ := store.ReserveNonce(ctx, "erd1a", 1); !ok {
+		t.Fatal("Expected first reservation to succeed")
+	}
+	current = current.Add(30 * time.Minute)
+	if ok, _ := store.ReserveNonce(ctx, "erd1a", 1); ok {
+		t.Error("Expected the reservation to hold within the TTL")
+	}
+
+	current = current.Add(time.Hour)
+	if ok, _ := store.ReserveNonce(ctx, "erd1a", 1); !ok {
+		t.Error("Expected an expired reservation to be reservable again")
+	}
+	// The write after a full TTL swept the old reservations of other senders too
+	store.ReserveNonce(ctx, "erd1b", 2)
+	current = current.Add(2 * time.Hour)
+	store.ReserveNonce(ctx, "erd1c", 3)
+	if len(store.reserved.entries) != 1 {
+		t.Errorf("entries = %d, want only the latest reservation", len(store.reserved.entries))
+	}
+}
+
+func TestMemorySettledStore_SettlementsExpire(t *testing.T) {
+	store := NewMemorySettledStore().WithTTL(time.Hour)
+	current := time.Unix(1700000000, 0)
+	store.settled.now = func() time.Time { return current }
+	ctx := context.Background()
+
+	store.MarkSettled(ctx, "payload", "hash")
+	if txHash, ok, _ := store.GetSettled(ctx, "payload"); !ok || txHash != "hash" {
+		t.Fatalf("GetSettled() = %s, %v, want hash", txHash, ok)
+	}
+
+	current = current.Add(time.Hour)
+	if _, ok, _ := store.GetSettled(ctx, "payload"); ok {
+		t.Error("Expected the settlement to be forgotten after the TTL")
+	}
+	store.MarkSettled(ctx, "next", "hash")
+	if len(store.settled.entries) != 1 {
+		t.Errorf("entries = %d, want the expired settlement swept", len(store.settled.entries))
+	}
+
+	// A zero TTL keeps settlements for the lifetime of the store
+	forever := NewMemorySettledStore().WithTTL(0)
+	forever.settled.now = func() time.Time { return current }
+	forever.MarkSettled(ctx, "payload", "hash")
+	current = current.Add(365 * 24 * time.Hour)
+	if _, ok, _ := forever.GetSettled(ctx, "payload"); !ok {
+		t.Error("Expected a zero TTL to never expire")
+	}
+}
+
+func TestMemoryReplayStore_PaymentsExpire(t *testing.T) {
+	store := NewMemoryReplayStore().WithTTL(time.Hour)
+	current := time.Unix(1700000000, 0)
+	store.seen.now = func() time.Time { return current }
+	ctx := context.Background()
+	key := PaymentKey{Sender: "erd1sender", Nonce: 9, ChainID: "D"}
+
+	store.Record(ctx, key)
+	if seen, _ := store.Seen(ctx, key); !seen {
+		t.Fatal("Expected the recorded payment to be seen")
+	}
+
+	current = current.Add(time.Hour)
+	if seen, _ := store.Seen(ctx, key); seen {
+		t.Error("Expected the payment to be forgotten after the TTL")
+	}
+	store.Record(ctx, PaymentKey{Sender: "erd1sender", Nonce: 10, ChainID: "D"})
+	if len(store.seen.entries) != 1 {
+		t.Errorf("entries = %d, want the expired payment swept", len(store.seen.entries))
+	}
+}
+
+// recordingReplayStore counts the payments recorded through WithReplayStore
+type recordingReplayStore struct {
+	*MemoryReplayStore
+	recorded []PaymentKey
+}
+
+func (r *recordingReplayStore) Record(ctx context.Context, key PaymentKey) error {
+	r.recorded = append(r.recorded, key)
+	return r.MemoryReplayStore.Record(ctx, key)
+}
+
+func TestSettle_RejectsReplay(t *testing.T) {
+	store := &recordingReplayStore{MemoryReplayStore: NewMemoryReplayStore()}
+	scheme := &ExactMultiversXScheme{proxy: &MockProxy{sendHash: "hash"}, asyncSettle: true}
+	WithReplayStore(store)(scheme)
+
+	payload := multiversx.ExactRelayedPayload{Sender: "erd1sender", Nonce: 9, ChainID: "D"}
+	requirements := types.PaymentRequirements{Extra: map[string]interface{}{"assetTransferMethod": multiversx.TransferMethodDirect}}
+
+	if _, err := scheme.Settle(context.Background(), types.PaymentPayload{Payload: payload.ToMap()}, requirements); err != nil {
+		t.Fatalf("Settle() error = %v", err)
+	}
+	want := PaymentKey{Sender: "erd1sender", Nonce: 9, ChainID: "D"}
+	if len(store.recorded) != 1 || store.recorded[0] != want {
+		t.Fatalf("recorded = %v, want [%v]", store.recorded, want)
+	}
+
+	// Replayed against other requirements
+	other := types.PaymentRequirements{PayTo: "erd1other", Extra: map[string]interface{}{"assetTransferMethod": multiversx.TransferMethodDirect}}
+	_, err := scheme.Settle(context.Background(), types.PaymentPayload{Payload: payload.ToMap()}, other)
+	var sErr *x402.SettleError
+	if !errors.As(err, &sErr) || sErr.Reason != multiversx.ErrCodePaymentReplayed {
+		t.Errorf("Settle() replay error = %v, want %s", err, multiversx.ErrCodePaymentReplayed)
+	}
+
+	validAddr := "erd1qyu5wthldzr8wx5c9ucg8kjagg0jfs53s8nr3zpz3hypefsdd8ssycr6th"
+	settled := multiversx.ExactRelayedPayload{Sender: validAddr, Receiver: validAddr, Value: "0", Nonce: 9, ChainID: "D", Version: 1, Signature: strings.Repeat("00", 64)}
+	store.MemoryReplayStore.Record(context.Background(), paymentKey(settled))
+	_, err = scheme.Verify(context.Background(), types.PaymentPayload{Payload: settled.ToMap()}, other)
+	var vErr *x402.VerifyError
+	if !errors.As(err, &vErr) || vErr.Reason != multiversx.ErrCodePaymentReplayed {
+		t.Errorf("Verify() replay error = %v, want %s", err, multiversx.ErrCodePaymentReplayed)
+	}
+
+	// The same nonce on another chain is a different payment
+	otherChain := settled
+	otherChain.ChainID = "T"
+	if err := scheme.checkReplay(context.Background(), otherChain); err != nil {
+		t.Errorf("checkReplay() on another chain error = %v", err)
+	}
+}
@@ -0,0 +1,37 @@
+package facilitator
+
+import (
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/mechanisms/multiversx"
+	"github.com/coinbase/x402/go/types"
+)
+
+// VerifyResponse Extra keys describing the verified payment
+const (
+	// AssetExtraKey holds the asset the payment transfers
+	AssetExtraKey = "asset"
+	// AmountExtraKey holds the amount of the asset the payment transfers, in atomic units
+	AmountExtraKey = "amount"
+	// TransferMethodExtraKey holds the transfer method of the payment, e.g. direct or esdt
+	TransferMethodExtraKey = "assetTransferMethod"
+)
+
+// verifiedResponse describes a valid payment: its payer in Payer, and its asset, amount and
+// transfer method in Extra, so resource servers can report it without decoding the payload
+func verifiedResponse(payload multiversx.ExactRelayedPayload, requirements types.PaymentRequirements) *x402.VerifyResponse {
+	// The transfer matched the requirements, so the amount read from the payload is at least the required one
+	amount, ok := processedTransaction{Value: payload.Value, Data: []byte(payload.Data)}.amountCharged(requirements.Asset)
+	if !ok {
+		amount = requirements.Amount
+	}
+
+	return &x402.VerifyResponse{
+		IsValid: true,
+		Payer:   payload.Sender,
+		Extra: map[string]interface{}{
+			AssetExtraKey:          requirements.Asset,
+			AmountExtraKey:         amount,
+			TransferMethodExtraKey: multiversx.ResolveTransferMethod(requirements),
+		},
+	}
+}
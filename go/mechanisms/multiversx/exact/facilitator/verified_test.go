@@ -0,0 +1,85 @@
+package facilitator
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/multiversx/mx-sdk-go/data"
+
+	"github.com/coinbase/x402/go/mechanisms/multiversx"
+	"github.com/coinbase/x402/go/types"
+)
+
+func TestVerify_DescribesVerifiedPayment(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":{"result":{"status":"success","hash":"sim_hash"}},"error":""}`))
+	}))
+	defer server.Close()
+	scheme, _ := NewExactMultiversXScheme(server.URL, &MockSigner{})
+
+	pubKey, privKey, _ := ed25519.GenerateKey(nil)
+	senderAddr, _ := data.NewAddressFromBytes(pubKey).AddressAsBech32String()
+	payTo := "erd1spyavw0956vq68xj8y4tenjpq2wd5a9p2c6j8gsz7ztyrnpxrruqzu66jx"
+	relayer := "erd1qyu5wthldzr8wx5c9ucg8kjagg0jfs53s8nr3zpz3hypefsdd8ssycr6th"
+
+	tests := []struct {
+		name       string
+		req        types.PaymentRequirements
+		wantAmount string
+		wantMethod string
+	}{
+		{
+			name:       "EGLD",
+			req:        types.PaymentRequirements{PayTo: payTo, Amount: "1000", Asset: multiversx.NativeTokenTicker, Network: "multiversx:D"},
+			wantAmount: "1000",
+			wantMethod: multiversx.TransferMethodDirect,
+		},
+		{
+			name:       "ESDT",
+			req:        types.PaymentRequirements{PayTo: payTo, Amount: "2500", Asset: "USDC-c76f1f", Network: "multiversx:D"},
+			wantAmount: "2500",
+			wantMethod: multiversx.TransferMethodESDT,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, _ := multiversx.ResolveTransferMethodHandler(tt.req)
+			fields, err := handler.Encode(tt.req, senderAddr)
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			payload := multiversx.ExactRelayedPayload{
+				Nonce:    1,
+				Value:    fields.Value,
+				Receiver: fields.Receiver,
+				Sender:   senderAddr,
+				Relayer:  relayer,
+				GasPrice: 1_000_000_000,
+				GasLimit: 500_000,
+				Data:     fields.Data,
+				ChainID:  "D",
+				Version:  2,
+			}
+			tx := payload.ToTransaction()
+			txBytes, _ := multiversx.SerializeTransaction(&tx)
+			payload.Signature = hex.EncodeToString(ed25519.Sign(privKey, txBytes))
+			tt.req.Extra = map[string]interface{}{"relayer": relayer}
+
+			resp, err := scheme.Verify(context.Background(), types.PaymentPayload{Payload: payload.ToMap()}, tt.req)
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if !resp.IsValid || resp.Payer != senderAddr {
+				t.Errorf("Verify() = %+v, want a valid payment from %s", resp, senderAddr)
+			}
+			if resp.Extra[AssetExtraKey] != tt.req.Asset || resp.Extra[AmountExtraKey] != tt.wantAmount || resp.Extra[TransferMethodExtraKey] != tt.wantMethod {
+				t.Errorf("Verify() extra = %v, want %s %s via %s", resp.Extra, tt.wantAmount, tt.req.Asset, tt.wantMethod)
+			}
+		})
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"math/big"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/coinbase/x402/go/mechanisms/multiversx"
 
@@ -13,15 +15,46 @@ import (
 	"github.com/coinbase/x402/go/types"
 )
 
+const (
+	// DefaultMinTimeout is the shortest MaxTimeoutSeconds ValidatePaymentRequirements accepts by default
+	DefaultMinTimeout = time.Minute
+	// DefaultMaxTimeout is the longest MaxTimeoutSeconds ValidatePaymentRequirements accepts by default
+	DefaultMaxTimeout = 24 * time.Hour
+)
+
+// RelayerFeeEstimator estimates the EGLD fee (in base units) the relayer pays to settle a payment of asset
+type RelayerFeeEstimator func(asset string, network x402.Network) (*big.Int, error)
+
+// FeeConverter converts an EGLD fee (in base units) into base units of the payment asset
+type FeeConverter = multiversx.FeeConverter
+
 // ExactMultiversXScheme implements SchemeNetworkServer for MultiversX
 type ExactMultiversXScheme struct {
-	moneyParsers []x402.MoneyParser
+	moneyParsers  []x402.MoneyParser
+	feeEstimator  RelayerFeeEstimator
+	feeConverter  FeeConverter
+	defaultAsset  string
+	assetDecimals map[string]int
+	// assetMethods overrides the default transfer method of specific assets
+	assetMethods map[string]string
+	// tokenCheck confirms ESDT assets exist on chain, see WithTokenValidation
+	tokenCheck   *tokenValidator
+	apiOverrides map[string]string
+	// tokenDecimals caches the decimals looked up by TokenDecimals
+	tokenDecimals sync.Map
+	// minTimeout and maxTimeout bound MaxTimeoutSeconds, see WithTimeoutBounds
+	minTimeout time.Duration
+	maxTimeout time.Duration
 }
 
 // NewExactMultiversXScheme creates a new server scheme instance
 func NewExactMultiversXScheme() *ExactMultiversXScheme {
 	return &ExactMultiversXScheme{
 		moneyParsers: []x402.MoneyParser{},
+		defaultAsset: multiversx.NativeTokenTicker,
+		assetDecimals: map[string]int{
+			multiversx.NativeTokenTicker: multiversx.NativeTokenDecimals,
+		},
 	}
 }
 
@@ -36,8 +69,102 @@ func (s *ExactMultiversXScheme) RegisterMoneyParser(parser x402.MoneyParser) *Ex
 	return s
 }
 
+// RegisterAssetDecimals sets the number of decimals used to scale decimal prices of an asset
+func (s *ExactMultiversXScheme) RegisterAssetDecimals(asset string, decimals int) *ExactMultiversXScheme {
+	if s.assetDecimals == nil {
+		s.assetDecimals = make(map[string]int)
+	}
+	s.assetDecimals[asset] = decimals
+	return s
+}
+
+// WithDefaultAsset sets the asset (and its decimals) that plain money prices convert to when
+// no registered money parser handles them. Defaults to EGLD with 18 decimals.
+func (s *ExactMultiversXScheme) WithDefaultAsset(asset string, decimals int) *ExactMultiversXScheme {
+	s.defaultAsset = asset
+	return s.RegisterAssetDecimals(asset, decimals)
+}
+
+// WithDefaultToken sets an ESDT that plain money prices convert to, with its decimals read from the
+// MultiversX API of the price network through TokenDecimals.
+func (s *ExactMultiversXScheme) WithDefaultToken(tokenID string) *ExactMultiversXScheme {
+	s.defaultAsset = tokenID
+	return s
+}
+
+// WithAssetTransferMethod sets the transfer method requirements of asset default to when they
+// do not specify one, e.g. a registered SC-call method for wrapped tokens paid to a contract.
+// Other assets keep the built-in rule: direct for EGLD, esdt for tokens.
+func (s *ExactMultiversXScheme) WithAssetTransferMethod(asset string, method string) *ExactMultiversXScheme {
+	if s.assetMethods == nil {
+		s.assetMethods = make(map[string]string)
+	}
+	s.assetMethods[asset] = method
+	return s
+}
+
+// WithTimeoutBounds sets the range ValidatePaymentRequirements accepts for a non-zero MaxTimeoutSeconds
+// Defaults to DefaultMinTimeout through DefaultMaxTimeout; a zero bound keeps its default.
+func (s *ExactMultiversXScheme) WithTimeoutBounds(min, max time.Duration) *ExactMultiversXScheme {
+	s.minTimeout = min
+	s.maxTimeout = max
+	return s
+}
+
+// WithFeeInclusivePricing makes ParsePrice add the estimated relayer fee to token amounts
+// so the advertised price covers the settlement cost paid by the facilitator.
+// Direct EGLD transfers are left untouched, as the facilitator only requires fee coverage for tokens;
+// the converter may therefore be nil if only EGLD is priced.
+func (s *ExactMultiversXScheme) WithFeeInclusivePricing(estimator RelayerFeeEstimator, converter FeeConverter) *ExactMultiversXScheme {
+	s.feeEstimator = estimator
+	s.feeConverter = converter
+	return s
+}
+
 // ParsePrice converts an x402 generic price to a MultiversX-specific AssetAmount
 func (s *ExactMultiversXScheme) ParsePrice(price x402.Price, network x402.Network) (x402.AssetAmount, error) {
+	amount, err := s.parseBasePrice(price, network)
+	if err != nil {
+		return x402.AssetAmount{}, err
+	}
+
+	if s.feeEstimator == nil {
+		return amount, nil
+	}
+	return s.addRelayerFee(amount, network)
+}
+
+// addRelayerFee adds the estimated relayer fee, converted to the payment asset, to the amount
+// Assets settled with the direct method are returned unchanged.
+func (s *ExactMultiversXScheme) addRelayerFee(amount x402.AssetAmount, network x402.Network) (x402.AssetAmount, error) {
+	if multiversx.ResolveTransferMethod(types.PaymentRequirements{Asset: amount.Asset}) == multiversx.TransferMethodDirect {
+		return amount, nil
+	}
+
+	base, ok := new(big.Int).SetString(amount.Amount, 10)
+	if !ok {
+		return x402.AssetAmount{}, fmt.Errorf("invalid amount: %s", amount.Amount)
+	}
+
+	fee, err := s.feeEstimator(amount.Asset, network)
+	if err != nil {
+		return x402.AssetAmount{}, fmt.Errorf("failed to estimate relayer fee: %w", err)
+	}
+
+	if s.feeConverter == nil {
+		return x402.AssetAmount{}, fmt.Errorf("no fee converter configured for asset %s", amount.Asset)
+	}
+	fee, err = s.feeConverter(fee, amount.Asset, network)
+	if err != nil {
+		return x402.AssetAmount{}, fmt.Errorf("failed to convert relayer fee to %s: %w", amount.Asset, err)
+	}
+
+	amount.Amount = new(big.Int).Add(base, fee).String()
+	return amount, nil
+}
+
+// parseBasePrice converts the price to an AssetAmount without any fee adjustment
+func (s *ExactMultiversXScheme) parseBasePrice(price x402.Price, network x402.Network) (x402.AssetAmount, error) {
 	if pStruct, ok := price.(x402.AssetAmount); ok {
 		if pStruct.Asset == "" {
 			return x402.AssetAmount{}, fmt.Errorf("asset is required")
@@ -74,7 +201,7 @@ func (s *ExactMultiversXScheme) ParsePrice(price x402.Price, network x402.Networ
 		}
 	}
 
-	return s.defaultMoneyConversion(decimalAmount)
+	return s.defaultMoneyConversion(decimalAmount, network)
 }
 
 func (s *ExactMultiversXScheme) parseMoneyToDecimal(price x402.Price) (float64, error) {
@@ -101,18 +228,23 @@ func (s *ExactMultiversXScheme) parseMoneyToDecimal(price x402.Price) (float64,
 	}
 }
 
-func (s *ExactMultiversXScheme) defaultMoneyConversion(amount float64) (x402.AssetAmount, error) {
-	decimals := 18
-
-	bigFloat := new(big.Float).SetFloat64(amount)
-	multiplier := new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil))
-
-	result := new(big.Float).Mul(bigFloat, multiplier)
+func (s *ExactMultiversXScheme) defaultMoneyConversion(amount float64, network x402.Network) (x402.AssetAmount, error) {
+	asset := s.defaultAsset
+	if asset == "" {
+		asset = multiversx.NativeTokenTicker
+	}
 
-	finalInt, _ := result.Int(nil)
+	decimals, err := s.TokenDecimals(context.Background(), network, asset)
+	if err != nil {
+		return x402.AssetAmount{}, err
+	}
+	finalInt, err := multiversx.ParseDecimalAmount(strconv.FormatFloat(amount, 'f', -1, 64), decimals)
+	if err != nil {
+		return x402.AssetAmount{}, err
+	}
 
 	return x402.AssetAmount{
-		Asset:  multiversx.NativeTokenTicker,
+		Asset:  asset,
 		Amount: finalInt.String(),
 	}, nil
 }
@@ -125,7 +257,7 @@ func (s *ExactMultiversXScheme) EnhancePaymentRequirements(
 	extensions []string,
 ) (types.PaymentRequirements, error) {
 	// Perform strict validation
-	if err := s.ValidatePaymentRequirements(requirements); err != nil {
+	if err := s.validatePaymentRequirements(ctx, requirements); err != nil {
 		return requirements, err
 	}
 
@@ -141,13 +273,38 @@ func (s *ExactMultiversXScheme) EnhancePaymentRequirements(
 	}
 
 	if _, ok := reqCopy.Extra["assetTransferMethod"]; !ok {
-		if reqCopy.Asset == multiversx.NativeTokenTicker {
+		if method, ok := s.assetMethods[reqCopy.Asset]; ok {
+			reqCopy.Extra["assetTransferMethod"] = method
+		} else if reqCopy.Asset == multiversx.NativeTokenTicker {
 			reqCopy.Extra["assetTransferMethod"] = multiversx.TransferMethodDirect
 		} else {
 			reqCopy.Extra["assetTransferMethod"] = multiversx.TransferMethodESDT
 		}
 	}
 
+	// Advertise the x402 versions the facilitator accepts so clients can negotiate before paying
+	if _, ok := reqCopy.Extra[multiversx.ExtraX402Versions]; !ok {
+		if versions, ok := supportedKind.Extra[multiversx.ExtraX402Versions]; ok {
+			reqCopy.Extra[multiversx.ExtraX402Versions] = versions
+		} else {
+			reqCopy.Extra[multiversx.ExtraX402Versions] = multiversx.SupportedX402Versions
+		}
+	}
+
+	// A facilitator relaying with relayedTxV1 needs clients to sign plain transactions
+	if _, ok := reqCopy.Extra[multiversx.ExtraRelayVersion]; !ok {
+		if relayVersion, ok := supportedKind.Extra[multiversx.ExtraRelayVersion]; ok {
+			reqCopy.Extra[multiversx.ExtraRelayVersion] = relayVersion
+		}
+	}
+
+	// Relayed payments are signed for the relayer the facilitator published
+	if _, ok := reqCopy.Extra["relayer"]; !ok && reqCopy.Extra["assetTransferMethod"] != multiversx.TransferMethodDirect {
+		if relayer, ok := supportedKind.Extra["relayer"]; ok {
+			reqCopy.Extra["relayer"] = relayer
+		}
+	}
+
 	if _, ok := reqCopy.Extra["gasLimit"]; !ok {
 		if reqCopy.Extra["assetTransferMethod"] == multiversx.TransferMethodDirect {
 			reqCopy.Extra["gasLimit"] = uint64(multiversx.GasLimitStandard)
@@ -161,6 +318,11 @@ func (s *ExactMultiversXScheme) EnhancePaymentRequirements(
 
 // ValidatePaymentRequirements validates requirements strictly
 func (s *ExactMultiversXScheme) ValidatePaymentRequirements(requirements x402.PaymentRequirements) error {
+	return s.validatePaymentRequirements(context.Background(), requirements)
+}
+
+// validatePaymentRequirements validates requirements strictly, looking tokens up with ctx when enabled
+func (s *ExactMultiversXScheme) validatePaymentRequirements(ctx context.Context, requirements x402.PaymentRequirements) error {
 	if !multiversx.IsValidAddress(requirements.PayTo) {
 		return x402.NewPaymentError(x402.ErrCodeInvalidPayment, fmt.Sprintf("invalid PayTo address: %s", requirements.PayTo), nil)
 	}
@@ -182,5 +344,33 @@ func (s *ExactMultiversXScheme) ValidatePaymentRequirements(requirements x402.Pa
 		}
 	}
 
+	if err := s.validateTimeout(requirements.MaxTimeoutSeconds); err != nil {
+		return err
+	}
+
+	if s.tokenCheck != nil {
+		return s.tokenCheck.validate(ctx, requirements, s.apiOverrides)
+	}
+	return nil
+}
+
+// validateTimeout checks a set MaxTimeoutSeconds is within the timeout bounds
+// Zero is accepted: clients then apply their default validity window.
+func (s *ExactMultiversXScheme) validateTimeout(seconds int) error {
+	if seconds == 0 {
+		return nil
+	}
+	min, max := s.minTimeout, s.maxTimeout
+	if min <= 0 {
+		min = DefaultMinTimeout
+	}
+	if max <= 0 {
+		max = DefaultMaxTimeout
+	}
+
+	timeout := time.Duration(seconds) * time.Second
+	if timeout < min || timeout > max {
+		return x402.NewPaymentError(multiversx.ErrCodeInvalidRequirements, fmt.Sprintf("maxTimeoutSeconds %d is outside %s to %s", seconds, min, max), nil)
+	}
 	return nil
 }
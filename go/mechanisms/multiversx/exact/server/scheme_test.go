@@ -2,9 +2,13 @@ package server
 
 import (
 	"context"
+	"errors"
+	"math/big"
 	"testing"
+	"time"
 
 	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/mechanisms/multiversx"
 	"github.com/coinbase/x402/go/types"
 )
 
@@ -105,6 +109,42 @@ func TestValidatePaymentRequirements(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "Valid Wei Amount",
+			req: x402.PaymentRequirements{
+				PayTo:  "erd1spyavw0956vq68xj8y4tenjpq2wd5a9p2c6j8gsz7ztyrnpxrruqzu66jx",
+				Amount: "1000000000000000000",
+				Asset:  "EGLD",
+			},
+			wantErr: false,
+		},
+		{
+			name: "Decimal Amount",
+			req: x402.PaymentRequirements{
+				PayTo:  "erd1spyavw0956vq68xj8y4tenjpq2wd5a9p2c6j8gsz7ztyrnpxrruqzu66jx",
+				Amount: "1.5",
+				Asset:  "EGLD",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Negative Amount",
+			req: x402.PaymentRequirements{
+				PayTo:  "erd1spyavw0956vq68xj8y4tenjpq2wd5a9p2c6j8gsz7ztyrnpxrruqzu66jx",
+				Amount: "-100",
+				Asset:  "EGLD",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Scientific Notation Amount",
+			req: x402.PaymentRequirements{
+				PayTo:  "erd1spyavw0956vq68xj8y4tenjpq2wd5a9p2c6j8gsz7ztyrnpxrruqzu66jx",
+				Amount: "1e3",
+				Asset:  "EGLD",
+			},
+			wantErr: true,
+		},
 		{
 			name: "Invalid Address",
 			req: x402.PaymentRequirements{
@@ -144,6 +184,42 @@ func TestValidatePaymentRequirements(t *testing.T) {
 	}
 }
 
+func TestValidatePaymentRequirements_TimeoutBounds(t *testing.T) {
+	tests := []struct {
+		name    string
+		scheme  *ExactMultiversXScheme
+		timeout int
+		wantErr bool
+	}{
+		{"unset uses the client default", NewExactMultiversXScheme(), 0, false},
+		{"too small", NewExactMultiversXScheme(), 30, true},
+		{"too large", NewExactMultiversXScheme(), 7 * 86400, true},
+		{"in range", NewExactMultiversXScheme(), 300, false},
+		{"default bounds are inclusive", NewExactMultiversXScheme(), 86400, false},
+		{"custom bounds", NewExactMultiversXScheme().WithTimeoutBounds(10*time.Second, 2*time.Minute), 300, true},
+		{"custom minimum", NewExactMultiversXScheme().WithTimeoutBounds(10*time.Second, 0), 30, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := x402.PaymentRequirements{
+				PayTo:             "erd1spyavw0956vq68xj8y4tenjpq2wd5a9p2c6j8gsz7ztyrnpxrruqzu66jx",
+				Amount:            "1000",
+				Asset:             "EGLD",
+				MaxTimeoutSeconds: tt.timeout,
+			}
+			err := tt.scheme.ValidatePaymentRequirements(req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidatePaymentRequirements() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var payErr *x402.PaymentError
+			if err != nil && (!errors.As(err, &payErr) || payErr.Code != multiversx.ErrCodeInvalidRequirements) {
+				t.Errorf("error = %v, want a %s PaymentError", err, multiversx.ErrCodeInvalidRequirements)
+			}
+		})
+	}
+}
+
 func TestEnhancePaymentRequirements(t *testing.T) {
 	scheme := NewExactMultiversXScheme()
 
@@ -186,3 +262,193 @@ func TestEnhancePaymentRequirements(t *testing.T) {
 		}
 	})
 }
+
+func TestParsePrice_FeeInclusive(t *testing.T) {
+	relayerFee := big.NewInt(50_000 * multiversx.GasPriceDefault) // 0.00005 EGLD
+
+	scheme := NewExactMultiversXScheme().WithFeeInclusivePricing(
+		func(asset string, network x402.Network) (*big.Int, error) {
+			return new(big.Int).Set(relayerFee), nil
+		},
+		func(feeEGLD *big.Int, asset string, network x402.Network) (*big.Int, error) {
+			// 1 EGLD (18 decimals) = 30 USDC (6 decimals)
+			converted := new(big.Int).Mul(feeEGLD, big.NewInt(30_000_000))
+			return converted.Div(converted, big.NewInt(1_000_000_000_000_000_000)), nil
+		},
+	)
+
+	t.Run("EGLD Direct Unchanged", func(t *testing.T) {
+		got, err := scheme.ParsePrice("1.5", "multiversx:D")
+		if err != nil {
+			t.Fatalf("ParsePrice error: %v", err)
+		}
+		// Direct EGLD transfers are not charged the relayer fee by the facilitator
+		if got.Amount != "1500000000000000000" {
+			t.Errorf("Expected the EGLD amount without fee 1500000000000000000, got %s", got.Amount)
+		}
+	})
+
+	t.Run("ESDT Converted", func(t *testing.T) {
+		got, err := scheme.ParsePrice(map[string]interface{}{"amount": "1000000", "asset": "USDC-123456"}, "multiversx:D")
+		if err != nil {
+			t.Fatalf("ParsePrice error: %v", err)
+		}
+		// 0.00005 EGLD * 30 USDC = 0.0015 USDC = 1500 base units
+		if got.Amount != "1001500" {
+			t.Errorf("Expected fee-inclusive amount 1001500, got %s", got.Amount)
+		}
+	})
+
+	t.Run("ESDT Without Converter", func(t *testing.T) {
+		noConverter := NewExactMultiversXScheme().WithFeeInclusivePricing(
+			func(asset string, network x402.Network) (*big.Int, error) {
+				return relayerFee, nil
+			}, nil)
+		if _, err := noConverter.ParsePrice(map[string]interface{}{"amount": "1000000", "asset": "USDC-123456"}, "multiversx:D"); err == nil {
+			t.Error("Expected error when no converter is configured for ESDT")
+		}
+	})
+}
+
+func TestParsePrice_AssetDecimals(t *testing.T) {
+	scheme := NewExactMultiversXScheme().WithDefaultAsset("USDC-c76f1f", 6)
+
+	tests := []struct {
+		name    string
+		price   interface{}
+		wantAmt string
+	}{
+		{name: "Dollar string", price: "$1.00", wantAmt: "1000000"},
+		{name: "Fractional float", price: 0.25, wantAmt: "250000"},
+		{name: "Excess precision truncated", price: "0.0000019", wantAmt: "1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := scheme.ParsePrice(tt.price, "multiversx:D")
+			if err != nil {
+				t.Fatalf("ParsePrice() error = %v", err)
+			}
+			if got.Asset != "USDC-c76f1f" {
+				t.Errorf("ParsePrice() asset = %v, want USDC-c76f1f", got.Asset)
+			}
+			if got.Amount != tt.wantAmt {
+				t.Errorf("ParsePrice() amount = %v, want %v", got.Amount, tt.wantAmt)
+			}
+		})
+	}
+}
+
+func TestEnhancePaymentRequirements_AdvertisesVersions(t *testing.T) {
+	scheme := NewExactMultiversXScheme()
+	req := types.PaymentRequirements{
+		PayTo:  "erd1qyu5wthldzr8wx5c9ucg8kjagg0jfs53s8nr3zpz3hypefsdd8ssycr6th",
+		Amount: "1000",
+		Asset:  multiversx.NativeTokenTicker,
+	}
+
+	enhanced, err := scheme.EnhancePaymentRequirements(context.Background(), req, types.SupportedKind{}, nil)
+	if err != nil {
+		t.Fatalf("EnhancePaymentRequirements() error = %v", err)
+	}
+	versions, err := multiversx.ParseX402Versions(enhanced.Extra[multiversx.ExtraX402Versions])
+	if err != nil || !multiversx.ContainsX402Version(versions, x402.ProtocolVersion) {
+		t.Errorf("Expected default versions to be advertised, got %v", enhanced.Extra[multiversx.ExtraX402Versions])
+	}
+
+	// Versions advertised by the facilitator take precedence over the defaults
+	kind := types.SupportedKind{Extra: map[string]interface{}{multiversx.ExtraX402Versions: []interface{}{float64(1), float64(2)}}}
+	enhanced, err = scheme.EnhancePaymentRequirements(context.Background(), req, kind, nil)
+	if err != nil {
+		t.Fatalf("EnhancePaymentRequirements() error = %v", err)
+	}
+	versions, _ = multiversx.ParseX402Versions(enhanced.Extra[multiversx.ExtraX402Versions])
+	if len(versions) != 2 {
+		t.Errorf("Expected facilitator versions [1 2], got %v", versions)
+	}
+}
+
+func TestEnhancePaymentRequirements_CopiesRelayVersion(t *testing.T) {
+	scheme := NewExactMultiversXScheme()
+	req := types.PaymentRequirements{
+		PayTo:  "erd1qyu5wthldzr8wx5c9ucg8kjagg0jfs53s8nr3zpz3hypefsdd8ssycr6th",
+		Amount: "1000",
+		Asset:  "USDC-c76f1f",
+	}
+
+	enhanced, err := scheme.EnhancePaymentRequirements(context.Background(), req, types.SupportedKind{}, nil)
+	if err != nil {
+		t.Fatalf("EnhancePaymentRequirements() error = %v", err)
+	}
+	if _, ok := enhanced.Extra[multiversx.ExtraRelayVersion]; ok {
+		t.Errorf("Expected no relay version without a facilitator asking for one, got %v", enhanced.Extra[multiversx.ExtraRelayVersion])
+	}
+
+	kind := types.SupportedKind{Extra: map[string]interface{}{multiversx.ExtraRelayVersion: float64(multiversx.RelayVersionV1)}}
+	enhanced, err = scheme.EnhancePaymentRequirements(context.Background(), req, kind, nil)
+	if err != nil {
+		t.Fatalf("EnhancePaymentRequirements() error = %v", err)
+	}
+	if version, _ := multiversx.ExtraUint64(enhanced.Extra, multiversx.ExtraRelayVersion); version != multiversx.RelayVersionV1 {
+		t.Errorf("Expected the facilitator relay version %d, got %v", multiversx.RelayVersionV1, enhanced.Extra[multiversx.ExtraRelayVersion])
+	}
+}
+
+func TestEnhancePaymentRequirements_CopiesPublishedRelayer(t *testing.T) {
+	scheme := NewExactMultiversXScheme()
+	relayer := "erd1spyavw0956vq68xj8y4tenjpq2wd5a9p2c6j8gsz7ztyrnpxrruqzu66jx"
+	kind := types.SupportedKind{Extra: map[string]interface{}{"relayer": relayer}}
+	req := types.PaymentRequirements{
+		PayTo:  "erd1qyu5wthldzr8wx5c9ucg8kjagg0jfs53s8nr3zpz3hypefsdd8ssycr6th",
+		Amount: "1000",
+		Asset:  "USDC-c76f1f",
+	}
+
+	enhanced, err := scheme.EnhancePaymentRequirements(context.Background(), req, kind, nil)
+	if err != nil {
+		t.Fatalf("EnhancePaymentRequirements() error = %v", err)
+	}
+	if enhanced.Extra["relayer"] != relayer {
+		t.Errorf("Expected the published relayer %s, got %v", relayer, enhanced.Extra["relayer"])
+	}
+
+	// Direct transfers are not relayed
+	req.Asset = multiversx.NativeTokenTicker
+	enhanced, err = scheme.EnhancePaymentRequirements(context.Background(), req, kind, nil)
+	if err != nil {
+		t.Fatalf("EnhancePaymentRequirements() error = %v", err)
+	}
+	if _, ok := enhanced.Extra["relayer"]; ok {
+		t.Errorf("Expected no relayer for a direct transfer, got %v", enhanced.Extra["relayer"])
+	}
+}
+
+func TestEnhancePaymentRequirements_AssetTransferMethodDefaults(t *testing.T) {
+	scheme := NewExactMultiversXScheme().WithAssetTransferMethod("WEGLD-abcdef", "scCall")
+	payTo := "erd1qyu5wthldzr8wx5c9ucg8kjagg0jfs53s8nr3zpz3hypefsdd8ssycr6th"
+
+	tests := []struct {
+		name  string
+		asset string
+		extra map[string]interface{}
+		want  string
+	}{
+		{"configured asset", "WEGLD-abcdef", nil, "scCall"},
+		{"other token", "USDC-123456", nil, multiversx.TransferMethodESDT},
+		{"native EGLD", multiversx.NativeTokenTicker, nil, multiversx.TransferMethodDirect},
+		{"explicit method wins", "WEGLD-abcdef", map[string]interface{}{"assetTransferMethod": multiversx.TransferMethodESDT}, multiversx.TransferMethodESDT},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := types.PaymentRequirements{PayTo: payTo, Amount: "1000", Asset: tt.asset, Extra: tt.extra}
+			got, err := scheme.EnhancePaymentRequirements(context.Background(), req, types.SupportedKind{}, nil)
+			if err != nil {
+				t.Fatalf("EnhancePaymentRequirements() error = %v", err)
+			}
+			if got.Extra["assetTransferMethod"] != tt.want {
+				t.Errorf("assetTransferMethod = %v, want %s", got.Extra["assetTransferMethod"], tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/mechanisms/multiversx"
+)
+
+// tokenLookupTimeout bounds each token lookup on the MultiversX API
+const tokenLookupTimeout = 10 * time.Second
+
+// tokenAPIClient sends the token lookups to the MultiversX API
+var tokenAPIClient = &http.Client{Timeout: tokenLookupTimeout}
+
+// errTokenNotFound is returned by fetchToken when the API does not know the token
+var errTokenNotFound = errors.New("token not found")
+
+// tokenInfo holds the fields of the API /tokens/{id} response the server uses
+type tokenInfo struct {
+	Identifier string `json:"identifier"`
+	Decimals   int    `json:"decimals"`
+}
+
+// tokenValidator confirms through the MultiversX API that ESDT assets exist
+type tokenValidator struct {
+	cacheTTL time.Duration
+
+	mu sync.Mutex
+	// known maps chainID/token of tokens found on chain to the expiry of that result
+	known map[string]time.Time
+}
+
+// WithTokenValidation makes requirement validation confirm that ESDT assets exist, through the
+// /tokens/{id} endpoint of the MultiversX API of the requirements network. Tokens found are cached
+// for cacheTTL (0 looks them up every time); missing tokens are never cached since they can still
+// be issued. It is off by default to keep validation free of network calls.
+func (s *ExactMultiversXScheme) WithTokenValidation(cacheTTL time.Duration) *ExactMultiversXScheme {
+	s.tokenCheck = &tokenValidator{
+		cacheTTL: cacheTTL,
+		known:    make(map[string]time.Time),
+	}
+	return s
+}
+
+// WithAPIURLOverride points the token lookups (validation and decimals) of chainID at url instead of
+// the public MultiversX API
+func (s *ExactMultiversXScheme) WithAPIURLOverride(chainID string, url string) *ExactMultiversXScheme {
+	if s.apiOverrides == nil {
+		s.apiOverrides = make(map[string]string)
+	}
+	s.apiOverrides[chainID] = url
+	return s
+}
+
+// validate fails when the ESDT asset of the requirements does not exist on their network
+func (v *tokenValidator) validate(ctx context.Context, requirements x402.PaymentRequirements, apiOverrides map[string]string) error {
+	if multiversx.IsNativeAsset(requirements.Asset) {
+		return nil
+	}
+
+	chainID, err := multiversx.GetMultiversXChainId(requirements.Network)
+	if err != nil {
+		return x402.NewPaymentError(x402.ErrCodeInvalidPayment, err.Error(), nil)
+	}
+	key := chainID + "/" + requirements.Asset
+	if v.cached(key) {
+		return nil
+	}
+
+	apiURL, err := multiversx.GetAPIURLStrict(chainID, apiOverrides)
+	if err != nil {
+		return x402.NewPaymentError(x402.ErrCodeInvalidPayment, err.Error(), nil)
+	}
+	if _, err := fetchToken(ctx, apiURL, requirements.Asset); errors.Is(err, errTokenNotFound) {
+		return x402.NewPaymentError(x402.ErrCodeInvalidPayment, fmt.Sprintf("asset TokenID %s does not exist on %s", requirements.Asset, requirements.Network), nil)
+	} else if err != nil {
+		return x402.NewPaymentError(x402.ErrCodeInvalidPayment, fmt.Sprintf("failed to confirm asset %s exists: %v", requirements.Asset, err), nil)
+	}
+
+	if v.cacheTTL > 0 {
+		v.mu.Lock()
+		v.known[key] = time.Now().Add(v.cacheTTL)
+		v.mu.Unlock()
+	}
+	return nil
+}
+
+// cached reports whether the token was recently found on chain
+func (v *tokenValidator) cached(key string) bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	expiry, ok := v.known[key]
+	if ok && time.Now().After(expiry) {
+		delete(v.known, key)
+		return false
+	}
+	return ok
+}
+
+// TokenDecimals returns the number of decimals of tokenID on network
+// EGLD is 18 and registered assets use RegisterAssetDecimals without a lookup; other tokens are read
+// from the /tokens/{id} endpoint of the MultiversX API once and cached, as decimals never change.
+func (s *ExactMultiversXScheme) TokenDecimals(ctx context.Context, network x402.Network, tokenID string) (int, error) {
+	if decimals, ok := s.assetDecimals[tokenID]; ok {
+		return decimals, nil
+	}
+	if multiversx.IsNativeAsset(tokenID) {
+		return multiversx.NativeTokenDecimals, nil
+	}
+
+	chainID, err := multiversx.GetMultiversXChainId(string(network))
+	if err != nil {
+		return 0, err
+	}
+	key := chainID + "/" + tokenID
+	if decimals, ok := s.tokenDecimals.Load(key); ok {
+		return decimals.(int), nil
+	}
+
+	apiURL, err := multiversx.GetAPIURLStrict(chainID, s.apiOverrides)
+	if err != nil {
+		return 0, err
+	}
+	token, err := fetchToken(ctx, apiURL, tokenID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to look up decimals of %s: %w", tokenID, err)
+	}
+	s.tokenDecimals.Store(key, token.Decimals)
+	return token.Decimals, nil
+}
+
+// fetchToken reads the token from the API, returning errTokenNotFound when the API does not know it
+func fetchToken(ctx context.Context, apiURL string, tokenID string) (*tokenInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL+"/tokens/"+url.PathEscape(tokenID), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := tokenAPIClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, errTokenNotFound
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		return nil, fmt.Errorf("API returned status %d", resp.StatusCode)
+	}
+
+	var token tokenInfo
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return nil, fmt.Errorf("invalid token response: %w", err)
+	}
+	return &token, nil
+}
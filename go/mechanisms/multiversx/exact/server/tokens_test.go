@@ -0,0 +1,132 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/coinbase/x402/go/mechanisms/multiversx"
+	"github.com/coinbase/x402/go/types"
+)
+
+func TestValidatePaymentRequirements_TokenValidation(t *testing.T) {
+	var lookups int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&lookups, 1)
+		switch r.URL.Path {
+		case "/tokens/USDC-c76f1f":
+			w.Write([]byte(`{"identifier":"USDC-c76f1f","decimals":6}`))
+		case "/tokens/DOWN-abcdef":
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	requirements := func(asset string) types.PaymentRequirements {
+		return types.PaymentRequirements{
+			PayTo:   "erd1qyu5wthldzr8wx5c9ucg8kjagg0jfs53s8nr3zpz3hypefsdd8ssycr6th",
+			Amount:  "100",
+			Asset:   asset,
+			Network: "multiversx:D",
+		}
+	}
+
+	// Off by default: a well-formed but unknown token passes without any lookup
+	if err := NewExactMultiversXScheme().WithAPIURLOverride(multiversx.ChainIDDevnet, server.URL).ValidatePaymentRequirements(requirements("NOPE-123456")); err != nil {
+		t.Fatalf("ValidatePaymentRequirements() without token validation error = %v", err)
+	}
+	if atomic.LoadInt32(&lookups) != 0 {
+		t.Fatalf("lookups = %d, want none without token validation", lookups)
+	}
+
+	scheme := NewExactMultiversXScheme().
+		WithTokenValidation(time.Minute).
+		WithAPIURLOverride(multiversx.ChainIDDevnet, server.URL)
+
+	tests := []struct {
+		name    string
+		asset   string
+		wantErr string
+	}{
+		{"Existing Token", "USDC-c76f1f", ""},
+		{"Missing Token", "NOPE-123456", "does not exist"},
+		{"API Unavailable", "DOWN-abcdef", "failed to confirm"},
+		{"Native EGLD", multiversx.NativeTokenTicker, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := scheme.ValidatePaymentRequirements(requirements(tt.asset))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidatePaymentRequirements() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ValidatePaymentRequirements() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	// Existing tokens are served from the cache
+	before := atomic.LoadInt32(&lookups)
+	if err := scheme.ValidatePaymentRequirements(requirements("USDC-c76f1f")); err != nil {
+		t.Fatalf("ValidatePaymentRequirements() error = %v", err)
+	}
+	if after := atomic.LoadInt32(&lookups); after != before {
+		t.Errorf("lookups = %d, want the cached result (%d)", after, before)
+	}
+}
+
+func TestParsePrice_LooksUpTokenDecimals(t *testing.T) {
+	var lookups int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&lookups, 1)
+		if r.URL.Path != "/tokens/USDC-c76f1f" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"identifier":"USDC-c76f1f","decimals":6}`))
+	}))
+	defer server.Close()
+
+	scheme := NewExactMultiversXScheme().
+		WithDefaultToken("USDC-c76f1f").
+		WithAPIURLOverride(multiversx.ChainIDDevnet, server.URL)
+
+	for i := 0; i < 2; i++ {
+		amount, err := scheme.ParsePrice("$1", "multiversx:D")
+		if err != nil {
+			t.Fatalf("ParsePrice() error = %v", err)
+		}
+		if amount.Asset != "USDC-c76f1f" || amount.Amount != "1000000" {
+			t.Errorf("ParsePrice() = %+v, want 1000000 USDC-c76f1f", amount)
+		}
+	}
+	if atomic.LoadInt32(&lookups) != 1 {
+		t.Errorf("lookups = %d, want the decimals cached after the first", lookups)
+	}
+
+	// EGLD and registered assets never need a lookup
+	if decimals, err := scheme.TokenDecimals(context.Background(), "multiversx:D", multiversx.NativeTokenTicker); err != nil || decimals != 18 {
+		t.Errorf("TokenDecimals(EGLD) = %d, %v, want 18", decimals, err)
+	}
+	scheme.RegisterAssetDecimals("WEGLD-bd4d79", 18)
+	if decimals, err := scheme.TokenDecimals(context.Background(), "multiversx:D", "WEGLD-bd4d79"); err != nil || decimals != 18 {
+		t.Errorf("TokenDecimals(WEGLD) = %d, %v, want 18", decimals, err)
+	}
+	if atomic.LoadInt32(&lookups) != 1 {
+		t.Errorf("lookups = %d, want no lookup for EGLD or registered assets", lookups)
+	}
+
+	// An unknown token fails instead of assuming 18 decimals
+	if _, err := NewExactMultiversXScheme().WithDefaultToken("NOPE-123456").WithAPIURLOverride(multiversx.ChainIDDevnet, server.URL).ParsePrice("$1", "multiversx:D"); err == nil {
+		t.Error("Expected ParsePrice to fail when the token decimals cannot be read")
+	}
+}
@@ -0,0 +1,102 @@
+package multiversx
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/multiversx/mx-chain-core-go/data/transaction"
+	"github.com/multiversx/mx-sdk-go/data"
+)
+
+// ErrExternalSignerUnsupported is returned by the ExternalSignerAdapter for network operations,
+// which the facilitator performs through its own proxy
+var ErrExternalSignerUnsupported = errors.New("operation not supported by external signer")
+
+// ExternalSigner signs raw bytes with keys that never leave a remote KMS/HSM
+type ExternalSigner interface {
+	// GetAddresses returns the bech32 addresses whose keys the signer holds
+	GetAddresses() []string
+
+	// SignBytes signs message with the Ed25519 key of address and returns the raw 64 byte signature
+	SignBytes(ctx context.Context, address string, message []byte) ([]byte, error)
+}
+
+// ExternalSignerAdapter exposes an ExternalSigner as a FacilitatorMultiversXSigner
+// It builds the canonical transaction bytes itself and only sends those to the external signer.
+type ExternalSignerAdapter struct {
+	signer ExternalSigner
+}
+
+// Ensure ExternalSignerAdapter implements FacilitatorMultiversXSigner interface
+var _ FacilitatorMultiversXSigner = (*ExternalSignerAdapter)(nil)
+
+// NewExternalSignerAdapter wraps an ExternalSigner for use as a facilitator signer
+func NewExternalSignerAdapter(signer ExternalSigner) (*ExternalSignerAdapter, error) {
+	if signer == nil {
+		return nil, errors.New("external signer is required")
+	}
+	return &ExternalSignerAdapter{signer: signer}, nil
+}
+
+// GetAddresses returns the addresses of the external signer
+func (a *ExternalSignerAdapter) GetAddresses() []string {
+	return a.signer.GetAddresses()
+}
+
+// Sign signs the transaction as its relayer and returns the signature as a hex string
+// A transaction without relayer, such as a relayedTxV1 wrapper, is signed as its sender instead.
+// The signing address must be one of the external signer addresses. The returned signature is
+// checked against its key so a misconfigured KMS key is caught before broadcast.
+func (a *ExternalSignerAdapter) Sign(ctx context.Context, tx *transaction.FrontendTransaction) (string, error) {
+	signer := tx.RelayerAddr
+	if signer == "" {
+		signer = tx.Sender
+	}
+	if !a.holds(signer) {
+		return "", fmt.Errorf("external signer does not hold %s", signer)
+	}
+
+	msgBytes, err := transactionSigningBytes(tx)
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize transaction: %w", err)
+	}
+
+	sig, err := a.signer.SignBytes(ctx, signer, msgBytes)
+	if err != nil {
+		return "", fmt.Errorf("external signing failed: %w", err)
+	}
+
+	sigHex := hex.EncodeToString(sig)
+	if err := verifyEd25519Signature(signer, sigHex, msgBytes); err != nil {
+		return "", fmt.Errorf("external signer returned an invalid signature: %w", err)
+	}
+
+	return sigHex, nil
+}
+
+// SendTransaction is not supported: the facilitator broadcasts through its proxy
+func (a *ExternalSignerAdapter) SendTransaction(ctx context.Context, tx *transaction.FrontendTransaction) (string, error) {
+	return "", ErrExternalSignerUnsupported
+}
+
+// GetAccount is not supported: the facilitator queries accounts through its proxy
+func (a *ExternalSignerAdapter) GetAccount(ctx context.Context, address string) (*data.Account, error) {
+	return nil, ErrExternalSignerUnsupported
+}
+
+// GetTransactionStatus is not supported: the facilitator polls statuses through its proxy
+func (a *ExternalSignerAdapter) GetTransactionStatus(ctx context.Context, txHash string) (string, error) {
+	return "", ErrExternalSignerUnsupported
+}
+
+// holds reports whether address is one of the external signer addresses
+func (a *ExternalSignerAdapter) holds(address string) bool {
+	for _, addr := range a.signer.GetAddresses() {
+		if addr == address {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,129 @@
+package multiversx
+
+import (
+	"context"
+	"crypto/ed25519"
+	"fmt"
+	"testing"
+
+	"github.com/multiversx/mx-sdk-go/data"
+)
+
+// mockExternalSigner simulates a KMS holding a single Ed25519 key
+type mockExternalSigner struct {
+	address string
+	privKey ed25519.PrivateKey
+}
+
+func newMockExternalSigner(t *testing.T) *mockExternalSigner {
+	pubKey, privKey, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	address, _ := data.NewAddressFromBytes(pubKey).AddressAsBech32String()
+	return &mockExternalSigner{address: address, privKey: privKey}
+}
+
+func (m *mockExternalSigner) GetAddresses() []string {
+	return []string{m.address}
+}
+
+func (m *mockExternalSigner) SignBytes(ctx context.Context, address string, message []byte) ([]byte, error) {
+	if address != m.address {
+		return nil, fmt.Errorf("unknown key %s", address)
+	}
+	return ed25519.Sign(m.privKey, message), nil
+}
+
+func TestExternalSignerAdapter_SignsAsRelayer(t *testing.T) {
+	kms := newMockExternalSigner(t)
+	adapter, err := NewExternalSignerAdapter(kms)
+	if err != nil {
+		t.Fatalf("NewExternalSignerAdapter failed: %v", err)
+	}
+
+	payload := ExactRelayedPayload{
+		Nonce:     7,
+		Value:     "1000",
+		Receiver:  "erd1spyavw0956vq68xj8y4tenjpq2wd5a9p2c6j8gsz7ztyrnpxrruqzu66jx",
+		Sender:    "erd1qyu5wthldzr8wx5c9ucg8kjagg0jfs53s8nr3zpz3hypefsdd8ssycr6th",
+		GasPrice:  1000000000,
+		GasLimit:  100000,
+		ChainID:   "D",
+		Version:   2,
+		Relayer:   kms.address,
+		Signature: "00",
+	}
+
+	tx := payload.ToTransaction()
+	sig, err := adapter.Sign(context.Background(), &tx)
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+
+	payload.RelayerSignature = sig
+	if ok, err := VerifyRelayerSignature(payload); !ok || err != nil {
+		t.Errorf("Expected relayer signature to verify, got %v", err)
+	}
+}
+
+func TestExternalSignerAdapter_SignsAsSenderWithoutRelayer(t *testing.T) {
+	kms := newMockExternalSigner(t)
+	adapter, _ := NewExternalSignerAdapter(kms)
+
+	payload := ExactRelayedPayload{
+		Nonce:    3,
+		Value:    "0",
+		Receiver: "erd1spyavw0956vq68xj8y4tenjpq2wd5a9p2c6j8gsz7ztyrnpxrruqzu66jx",
+		Sender:   kms.address,
+		GasPrice: 1000000000,
+		GasLimit: 100000,
+		Data:     "relayedTx@00",
+		ChainID:  "D",
+		Version:  1,
+	}
+
+	tx := payload.ToTransaction()
+	sig, err := adapter.Sign(context.Background(), &tx)
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+
+	msg, _ := SigningBytes(payload)
+	if err := verifyEd25519Signature(kms.address, sig, msg); err != nil {
+		t.Errorf("Expected sender signature to verify, got %v", err)
+	}
+}
+
+func TestExternalSignerAdapter_Errors(t *testing.T) {
+	kms := newMockExternalSigner(t)
+	adapter, _ := NewExternalSignerAdapter(kms)
+
+	if _, err := NewExternalSignerAdapter(nil); err == nil {
+		t.Error("Expected error for nil external signer")
+	}
+
+	unrelayed := ExactRelayedPayload{Sender: "erd1qyu5wthldzr8wx5c9ucg8kjagg0jfs53s8nr3zpz3hypefsdd8ssycr6th", Receiver: kms.address, Value: "0", ChainID: "D", Version: 2}
+	tx := unrelayed.ToTransaction()
+	if _, err := adapter.Sign(context.Background(), &tx); err == nil {
+		t.Error("Expected error for sender not held by the external signer")
+	}
+
+	tx.RelayerAddr = "erd1spyavw0956vq68xj8y4tenjpq2wd5a9p2c6j8gsz7ztyrnpxrruqzu66jx"
+	if _, err := adapter.Sign(context.Background(), &tx); err == nil {
+		t.Error("Expected error for relayer not held by the external signer")
+	}
+
+	// A KMS returning a signature from the wrong key is rejected
+	other := newMockExternalSigner(t)
+	other.address = kms.address
+	tx.RelayerAddr = kms.address
+	wrongKey, _ := NewExternalSignerAdapter(other)
+	if _, err := wrongKey.Sign(context.Background(), &tx); err == nil {
+		t.Error("Expected error for signature that does not match the relayer key")
+	}
+
+	if _, err := adapter.SendTransaction(context.Background(), &tx); err != ErrExternalSignerUnsupported {
+		t.Errorf("Expected ErrExternalSignerUnsupported, got %v", err)
+	}
+}
@@ -0,0 +1,88 @@
+package multiversx
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+)
+
+// ExtraUint64 returns the non-negative integer stored under key in an Extra map
+// It accepts Go integer types as well as the float64, json.Number and decimal string
+// forms values take after a JSON round trip. Fractional or negative values are rejected.
+func ExtraUint64(m map[string]interface{}, key string) (uint64, bool) {
+	switch v := m[key].(type) {
+	case uint64:
+		return v, true
+	case uint32:
+		return uint64(v), true
+	case uint:
+		return uint64(v), true
+	case int:
+		return intToUint64(int64(v))
+	case int32:
+		return intToUint64(int64(v))
+	case int64:
+		return intToUint64(v)
+	case float32:
+		return floatToUint64(float64(v))
+	case float64:
+		return floatToUint64(v)
+	case json.Number:
+		return parseUint64(v.String())
+	case string:
+		return parseUint64(v)
+	}
+	return 0, false
+}
+
+// ExtraString returns the non-empty string stored under key in an Extra map
+func ExtraString(m map[string]interface{}, key string) (string, bool) {
+	s, ok := m[key].(string)
+	if !ok || s == "" {
+		return "", false
+	}
+	return s, true
+}
+
+// ExtraStringSlice returns the string list stored under key in an Extra map
+// It accepts both []string and the []interface{} form produced by JSON decoding;
+// a list holding any non-string element is rejected.
+func ExtraStringSlice(m map[string]interface{}, key string) ([]string, bool) {
+	switch v := m[key].(type) {
+	case []string:
+		return v, true
+	case []interface{}:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, false
+			}
+			out = append(out, s)
+		}
+		return out, true
+	}
+	return nil, false
+}
+
+func intToUint64(v int64) (uint64, bool) {
+	if v < 0 {
+		return 0, false
+	}
+	return uint64(v), true
+}
+
+func floatToUint64(v float64) (uint64, bool) {
+	if v < 0 || v != math.Trunc(v) || v >= math.MaxUint64 {
+		return 0, false
+	}
+	return uint64(v), true
+}
+
+func parseUint64(s string) (uint64, bool) {
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
@@ -0,0 +1,97 @@
+package multiversx
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestExtraUint64(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  interface{}
+		want   uint64
+		wantOk bool
+	}{
+		{"uint64", uint64(50000), 50000, true},
+		{"uint32", uint32(7), 7, true},
+		{"int", 42, 42, true},
+		{"int64", int64(42), 42, true},
+		{"float64 From JSON", float64(60000000), 60000000, true},
+		{"float32", float32(3), 3, true},
+		{"json.Number", json.Number("123"), 123, true},
+		{"Decimal String", "50000", 50000, true},
+		{"Negative int", -1, 0, false},
+		{"Negative float64", float64(-1), 0, false},
+		{"Fractional float64", 1.5, 0, false},
+		{"Non Numeric String", "abc", 0, false},
+		{"Bool", true, 0, false},
+		{"Missing", nil, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := map[string]interface{}{}
+			if tt.value != nil {
+				m["key"] = tt.value
+			}
+			got, ok := ExtraUint64(m, "key")
+			if ok != tt.wantOk || got != tt.want {
+				t.Errorf("ExtraUint64() = (%d, %v), want (%d, %v)", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+
+	// Values decoded from JSON use float64
+	var decoded map[string]interface{}
+	if err := json.Unmarshal([]byte(`{"gasLimit": 60000000}`), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := ExtraUint64(decoded, "gasLimit"); !ok || got != 60000000 {
+		t.Errorf("ExtraUint64() after JSON round trip = (%d, %v)", got, ok)
+	}
+}
+
+func TestExtraString(t *testing.T) {
+	m := map[string]interface{}{
+		"relayer": "erd1relayer",
+		"empty":   "",
+		"number":  1,
+	}
+
+	if got, ok := ExtraString(m, "relayer"); !ok || got != "erd1relayer" {
+		t.Errorf("ExtraString(relayer) = (%q, %v)", got, ok)
+	}
+	for _, key := range []string{"empty", "number", "missing"} {
+		if _, ok := ExtraString(m, key); ok {
+			t.Errorf("ExtraString(%s) should not be ok", key)
+		}
+	}
+	if _, ok := ExtraString(nil, "relayer"); ok {
+		t.Error("ExtraString on nil map should not be ok")
+	}
+}
+
+func TestExtraStringSlice(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  interface{}
+		want   []string
+		wantOk bool
+	}{
+		{"[]string", []string{"01", "02"}, []string{"01", "02"}, true},
+		{"[]interface{} From JSON", []interface{}{"01", "02"}, []string{"01", "02"}, true},
+		{"Empty List", []interface{}{}, []string{}, true},
+		{"Mixed List", []interface{}{"01", float64(2)}, nil, false},
+		{"Not A List", "01", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ExtraStringSlice(map[string]interface{}{"arguments": tt.value}, "arguments")
+			if ok != tt.wantOk || (ok && !reflect.DeepEqual(got, tt.want)) {
+				t.Errorf("ExtraStringSlice() = (%v, %v), want (%v, %v)", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
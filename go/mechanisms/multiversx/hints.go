@@ -0,0 +1,100 @@
+package multiversx
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	x402 "github.com/coinbase/x402/go"
+)
+
+// InsufficientBalanceError reports how much EGLD the sender is missing to afford a payment
+type InsufficientBalanceError struct {
+	Balance *big.Int
+	Needed  *big.Int
+}
+
+// Shortfall returns the EGLD (in base units) missing from the balance
+func (e *InsufficientBalanceError) Shortfall() *big.Int {
+	return new(big.Int).Sub(e.Needed, e.Balance)
+}
+
+// Error implements the error interface
+func (e *InsufficientBalanceError) Error() string {
+	return fmt.Sprintf("balance %s is %s short of the %s needed", e.Balance, e.Shortfall(), e.Needed)
+}
+
+// HintedError attaches a remediation hint to a verification failure
+type HintedError struct {
+	Err  error
+	Hint string
+}
+
+// Error implements the error interface
+func (e *HintedError) Error() string {
+	if e.Err == nil {
+		return "hint: " + e.Hint
+	}
+	return fmt.Sprintf("%v (hint: %s)", e.Err, e.Hint)
+}
+
+// Unwrap returns the underlying error
+func (e *HintedError) Unwrap() error {
+	return e.Err
+}
+
+// remediationHints are the fixed hints of failures that need no payload details
+var remediationHints = map[string]string{
+	x402.ErrCodePaymentExpired:    "payment expired, re-sign it with a fresh validBefore",
+	x402.ErrCodeSignatureInvalid:  "sign the exact transaction fields with the sender key and send the signature as hex",
+	ErrCodeInvalidNonce:           "fetch the current sender account nonce and re-sign",
+	ErrCodeGasPriceTooHigh:        "use the network minimum gas price and re-sign",
+	ErrCodeReceiverMismatch:       "re-create the payment from the current payment requirements",
+	ErrCodeAmountMismatch:         "re-create the payment from the current payment requirements",
+	ErrCodeAssetMismatch:          "re-create the payment from the current payment requirements",
+	ErrCodeSCCallMismatch:         "call the scFunction with the arguments of the payment requirements",
+	ErrCodePaymentReplayed:        "this payment was already settled, create a new one with the next nonce",
+	ErrCodeInvalidValueFormat:     "send the value as a plain base-10 integer of atomic units",
+	ErrCodeFeeNotCovered:          "add the relayer fee to the transferred amount",
+	ErrCodeMemoMismatch:           "set the data field to the expected memo and re-sign",
+	x402.ErrCodeInsufficientFunds: "fund the sender account and retry",
+}
+
+// RemediationHint returns actionable guidance for a failed verification of payload
+// It returns an empty string when no hint is known for the failure.
+func RemediationHint(err error, payload ExactRelayedPayload) string {
+	var vErr *x402.VerifyError
+	if !errors.As(err, &vErr) {
+		return ""
+	}
+
+	switch vErr.Reason {
+	case ErrCodeInsufficientBalance:
+		var balanceErr *InsufficientBalanceError
+		if errors.As(err, &balanceErr) {
+			if amount, err := FormatAmount(balanceErr.Shortfall().String(), NativeTokenDecimals); err == nil {
+				return fmt.Sprintf("fund %s with at least %s EGLD", payload.Sender, amount)
+			}
+		}
+		return "fund the sender account with enough EGLD for the value and fee"
+	case ErrCodeGasTooLow:
+		if minimum := minimumGasLimit(payload); minimum > payload.GasLimit {
+			return fmt.Sprintf("increase gasLimit to at least %d and re-sign", minimum)
+		}
+		return fmt.Sprintf("increase gasLimit above %d and re-sign", payload.GasLimit)
+	case ErrCodePaymentNotYetValid:
+		return fmt.Sprintf("retry once validAfter (%d) has passed", payload.ValidAfter)
+	case ErrCodeChainMismatch:
+		return "sign the payment for the chain of the required network"
+	}
+	return remediationHints[vErr.Reason]
+}
+
+// minimumGasLimit estimates the gas the payload needs: the move balance cost for plain
+// transfers and the ESDT transfer cost when the data field is a MultiESDTNFTTransfer
+func minimumGasLimit(payload ExactRelayedPayload) uint64 {
+	if transfer, err := DecodeMultiESDTTransfer(payload.Data); err == nil {
+		return CalculateGasLimit([]byte(payload.Data), len(transfer.Transfers))
+	}
+	return GasLimitStandard + 1500*uint64(len(payload.Data))
+}
@@ -0,0 +1,110 @@
+package multiversx
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	x402 "github.com/coinbase/x402/go"
+)
+
+func TestRemediationHint(t *testing.T) {
+	sender := "erd1qyu5wthldzr8wx5c9ucg8kjagg0jfs53s8nr3zpz3hypefsdd8ssycr6th"
+	payload := ExactRelayedPayload{Sender: sender, GasLimit: 40000, ValidAfter: 1700000000}
+	verifyErr := func(reason string, err error) error {
+		return x402.NewVerifyError(reason, sender, "multiversx", err)
+	}
+
+	tests := []struct {
+		name    string
+		err     error
+		payload ExactRelayedPayload
+		want    string
+	}{
+		{
+			name:    "expired",
+			err:     verifyErr(x402.ErrCodePaymentExpired, errors.New("expired")),
+			payload: payload,
+			want:    "payment expired, re-sign it with a fresh validBefore",
+		},
+		{
+			name:    "gas limit below the move balance cost",
+			err:     verifyErr(ErrCodeGasTooLow, errors.New("insufficient gas limit")),
+			payload: payload,
+			want:    "increase gasLimit to at least 50000 and re-sign",
+		},
+		{
+			name:    "gas limit below the data cost",
+			err:     verifyErr(ErrCodeGasTooLow, errors.New("insufficient gas limit")),
+			payload: ExactRelayedPayload{Sender: sender, GasLimit: 50000, Data: "hello"},
+			want:    "increase gasLimit to at least 57500 and re-sign",
+		},
+		{
+			name:    "gas limit above the estimate",
+			err:     verifyErr(ErrCodeGasTooLow, errors.New("not enough gas")),
+			payload: ExactRelayedPayload{Sender: sender, GasLimit: 100000},
+			want:    "increase gasLimit above 100000 and re-sign",
+		},
+		{
+			name:    "insufficient balance with shortfall",
+			err:     verifyErr(ErrCodeInsufficientBalance, &InsufficientBalanceError{Balance: big.NewInt(500000000000000000), Needed: big.NewInt(1000000000000000000)}),
+			payload: payload,
+			want:    "fund " + sender + " with at least 0.5 EGLD",
+		},
+		{
+			name:    "insufficient balance without shortfall",
+			err:     verifyErr(ErrCodeInsufficientBalance, errors.New("insufficient balance")),
+			payload: payload,
+			want:    "fund the sender account with enough EGLD for the value and fee",
+		},
+		{
+			name:    "nonce",
+			err:     verifyErr(ErrCodeInvalidNonce, errors.New("lowerNonceInTx")),
+			payload: payload,
+			want:    "fetch the current sender account nonce and re-sign",
+		},
+		{
+			name:    "not yet valid",
+			err:     verifyErr(ErrCodePaymentNotYetValid, errors.New("not yet valid")),
+			payload: payload,
+			want:    "retry once validAfter (1700000000) has passed",
+		},
+		{
+			name:    "unknown reason",
+			err:     verifyErr(ErrCodeInvalidAddress, errors.New("bad address")),
+			payload: payload,
+			want:    "",
+		},
+		{
+			name:    "not a verify error",
+			err:     errors.New("boom"),
+			payload: payload,
+			want:    "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RemediationHint(tt.err, tt.payload); got != tt.want {
+				t.Errorf("RemediationHint() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHintedError(t *testing.T) {
+	cause := errors.New("expired")
+	err := x402.NewVerifyError(x402.ErrCodePaymentExpired, "", "multiversx", &HintedError{Err: cause, Hint: "re-sign"})
+
+	if !strings.Contains(err.Error(), "expired (hint: re-sign)") {
+		t.Errorf("Error() = %q, want the cause followed by the hint", err.Error())
+	}
+	if !errors.Is(err, cause) {
+		t.Error("Expected the hinted error to unwrap to its cause")
+	}
+	var hinted *HintedError
+	if !errors.As(err, &hinted) || hinted.Hint != "re-sign" {
+		t.Errorf("errors.As() hint = %v, want re-sign", hinted)
+	}
+}
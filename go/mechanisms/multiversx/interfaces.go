@@ -27,6 +27,7 @@ type FacilitatorMultiversXSigner interface {
 	GetAddresses() []string
 
 	// Sign signs the transaction and returns the signature as a hex string
+	// It signs as the transaction relayer, or as its sender when the transaction has no relayer.
 	Sign(ctx context.Context, tx *transaction.FrontendTransaction) (string, error)
 
 	// SendTransaction sends a transaction to the network
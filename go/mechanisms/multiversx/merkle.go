@@ -0,0 +1,140 @@
+package multiversx
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Leaves and inner nodes are hashed with distinct prefixes so an inner node can never be
+// presented as a payment fingerprint
+const (
+	merkleLeafPrefix = 0x00
+	merkleNodePrefix = 0x01
+)
+
+// MerkleStep is one level of a membership proof: the sibling hash and its side
+type MerkleStep struct {
+	// Hash is the hex encoded sibling hash
+	Hash string `json:"hash"`
+	// Left reports whether the sibling is hashed on the left of the running hash
+	Left bool `json:"left"`
+}
+
+// PaymentFingerprint returns the hex encoded SHA-256 of the payload, identifying it within a batch
+func PaymentFingerprint(payload ExactRelayedPayload) (string, error) {
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(payloadBytes)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// PaymentsMerkleRoot commits to the payloads, in order, under a single hex encoded merkle root
+// An aggregator settling the batch together references the root; each payer can then check the
+// inclusion of their payment with PaymentMerkleProof and VerifyMerkleProof.
+func PaymentsMerkleRoot(payloads []ExactRelayedPayload) (string, error) {
+	levels, err := paymentMerkleLevels(payloads)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(levels[len(levels)-1][0]), nil
+}
+
+// PaymentMerkleProof returns the membership proof of the payload at index within the batch
+func PaymentMerkleProof(payloads []ExactRelayedPayload, index int) ([]MerkleStep, error) {
+	if index < 0 || index >= len(payloads) {
+		return nil, fmt.Errorf("index %d out of range for %d payments", index, len(payloads))
+	}
+	levels, err := paymentMerkleLevels(payloads)
+	if err != nil {
+		return nil, err
+	}
+
+	var proof []MerkleStep
+	for _, level := range levels[:len(levels)-1] {
+		sibling := index ^ 1
+		// The last node of an odd level has no sibling and is promoted as is
+		if sibling < len(level) {
+			proof = append(proof, MerkleStep{Hash: hex.EncodeToString(level[sibling]), Left: sibling < index})
+		}
+		index /= 2
+	}
+	return proof, nil
+}
+
+// VerifyMerkleProof reports whether the payment fingerprint is committed under root by proof
+func VerifyMerkleProof(root string, fingerprint string, proof []MerkleStep) bool {
+	rootBytes, err := hex.DecodeString(root)
+	if err != nil {
+		return false
+	}
+	fingerprintBytes, err := hex.DecodeString(fingerprint)
+	if err != nil {
+		return false
+	}
+
+	current := merkleLeaf(fingerprintBytes)
+	for _, step := range proof {
+		sibling, err := hex.DecodeString(step.Hash)
+		if err != nil || len(sibling) != sha256.Size {
+			return false
+		}
+		if step.Left {
+			current = merkleNode(sibling, current)
+		} else {
+			current = merkleNode(current, sibling)
+		}
+	}
+	return bytes.Equal(current, rootBytes)
+}
+
+// paymentMerkleLevels hashes the payloads into every level of the tree, from the leaves to the root
+func paymentMerkleLevels(payloads []ExactRelayedPayload) ([][][]byte, error) {
+	if len(payloads) == 0 {
+		return nil, errors.New("no payments to commit")
+	}
+
+	level := make([][]byte, len(payloads))
+	for i, payload := range payloads {
+		fingerprint, err := PaymentFingerprint(payload)
+		if err != nil {
+			return nil, fmt.Errorf("payment %d: %w", i, err)
+		}
+		fingerprintBytes, _ := hex.DecodeString(fingerprint)
+		level[i] = merkleLeaf(fingerprintBytes)
+	}
+
+	levels := [][][]byte{level}
+	for len(level) > 1 {
+		next := make([][]byte, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			if i+1 == len(level) {
+				next = append(next, level[i])
+				continue
+			}
+			next = append(next, merkleNode(level[i], level[i+1]))
+		}
+		levels = append(levels, next)
+		level = next
+	}
+	return levels, nil
+}
+
+func merkleLeaf(fingerprint []byte) []byte {
+	sum := sha256.Sum256(append([]byte{merkleLeafPrefix}, fingerprint...))
+	return sum[:]
+}
+
+func merkleNode(left, right []byte) []byte {
+	buf := make([]byte, 0, 1+len(left)+len(right))
+	buf = append(buf, merkleNodePrefix)
+	buf = append(buf, left...)
+	buf = append(buf, right...)
+	sum := sha256.Sum256(buf)
+	return sum[:]
+}
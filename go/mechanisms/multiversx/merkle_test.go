@@ -0,0 +1,88 @@
+package multiversx
+
+import "testing"
+
+func merklePayloads(n int) []ExactRelayedPayload {
+	payloads := make([]ExactRelayedPayload, n)
+	for i := range payloads {
+		payloads[i] = ExactRelayedPayload{Sender: "erd1sender", Nonce: uint64(i), Value: "100", ChainID: "D", Version: 2}
+	}
+	return payloads
+}
+
+func TestPaymentsMerkleRoot(t *testing.T) {
+	payloads := merklePayloads(4)
+
+	root, err := PaymentsMerkleRoot(payloads)
+	if err != nil {
+		t.Fatalf("PaymentsMerkleRoot() error = %v", err)
+	}
+	again, _ := PaymentsMerkleRoot(merklePayloads(4))
+	if root != again {
+		t.Error("Expected the root to be deterministic")
+	}
+
+	// A single payload is its own leaf
+	single, _ := PaymentsMerkleRoot(payloads[:1])
+	fingerprint, _ := PaymentFingerprint(payloads[0])
+	if !VerifyMerkleProof(single, fingerprint, nil) {
+		t.Error("Expected a one-payment batch to verify with an empty proof")
+	}
+
+	// Changing or reordering any payment changes the root
+	changed := merklePayloads(4)
+	changed[2].Value = "99"
+	if other, _ := PaymentsMerkleRoot(changed); other == root {
+		t.Error("Expected a modified payment to change the root")
+	}
+	reordered := []ExactRelayedPayload{payloads[1], payloads[0], payloads[2], payloads[3]}
+	if other, _ := PaymentsMerkleRoot(reordered); other == root {
+		t.Error("Expected reordered payments to change the root")
+	}
+
+	if _, err := PaymentsMerkleRoot(nil); err == nil {
+		t.Error("Expected error for an empty batch")
+	}
+}
+
+func TestPaymentMerkleProof(t *testing.T) {
+	for _, n := range []int{1, 2, 3, 5, 8} {
+		payloads := merklePayloads(n)
+		root, _ := PaymentsMerkleRoot(payloads)
+
+		for i, payload := range payloads {
+			proof, err := PaymentMerkleProof(payloads, i)
+			if err != nil {
+				t.Fatalf("PaymentMerkleProof(%d of %d) error = %v", i, n, err)
+			}
+			fingerprint, _ := PaymentFingerprint(payload)
+			if !VerifyMerkleProof(root, fingerprint, proof) {
+				t.Errorf("payment %d of %d: proof does not verify", i, n)
+			}
+		}
+	}
+
+	payloads := merklePayloads(5)
+	root, _ := PaymentsMerkleRoot(payloads)
+	proof, _ := PaymentMerkleProof(payloads, 1)
+
+	outsider, _ := PaymentFingerprint(ExactRelayedPayload{Sender: "erd1other", Nonce: 1})
+	if VerifyMerkleProof(root, outsider, proof) {
+		t.Error("Expected a payment outside the batch to be rejected")
+	}
+	other, _ := PaymentFingerprint(payloads[2])
+	if VerifyMerkleProof(root, other, proof) {
+		t.Error("Expected the proof of another payment to be rejected")
+	}
+
+	tampered := append([]MerkleStep(nil), proof...)
+	tampered[0].Left = !tampered[0].Left
+	fingerprint, _ := PaymentFingerprint(payloads[1])
+	if VerifyMerkleProof(root, fingerprint, tampered) {
+		t.Error("Expected a tampered proof to be rejected")
+	}
+
+	if _, err := PaymentMerkleProof(payloads, 5); err == nil {
+		t.Error("Expected error for an out of range index")
+	}
+}
@@ -0,0 +1,142 @@
+package multiversx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+
+	x402 "github.com/coinbase/x402/go"
+)
+
+// FeeConverter converts an EGLD fee (in base units) into base units of the payment asset
+type FeeConverter func(feeEGLD *big.Int, asset string, network x402.Network) (*big.Int, error)
+
+// MaxTransactionFee returns the highest fee the payload can cost its fee payer: gasLimit * gasPrice
+// The actual fee is usually lower since unused gas is refunded and data gas is discounted.
+func MaxTransactionFee(payload ExactRelayedPayload) *big.Int {
+	return new(big.Int).Mul(new(big.Int).SetUint64(payload.GasLimit), new(big.Int).SetUint64(payload.GasPrice))
+}
+
+const (
+	// gasPerDataByte is the move-balance gas charged for each byte of the data field
+	gasPerDataByte = 1_500
+	// gasPriceModifierDivisor discounts the gas used beyond the move-balance cost: it costs 1/100 of the gas price
+	gasPriceModifierDivisor = 100
+)
+
+// EstimateRelayerFee returns the fee charged for the payload at gasPrice (payload.GasPrice when 0), which the
+// relayer pays for relayed payments. As on the node, the move-balance gas (50,000, plus 1,500 per data byte and
+// 50,000 more for a relayed V3 transaction) costs the full gas price while the rest of the gas limit costs 1/100
+// of it. This is the fee charged when the transaction executes; a smart contract call may get part of it refunded.
+func EstimateRelayerFee(payload ExactRelayedPayload, gasPrice uint64) (*big.Int, error) {
+	if gasPrice == 0 {
+		gasPrice = payload.GasPrice
+	}
+
+	moveBalanceGas := uint64(GasLimitStandard) + gasPerDataByte*uint64(len(payload.Data))
+	if payload.Relayer != "" {
+		moveBalanceGas += GasLimitStandard
+	}
+	if payload.GasLimit < moveBalanceGas {
+		return nil, fmt.Errorf("gas limit %d is below the move-balance cost of %d", payload.GasLimit, moveBalanceGas)
+	}
+
+	price := new(big.Int).SetUint64(gasPrice)
+	fee := new(big.Int).Mul(new(big.Int).SetUint64(moveBalanceGas), price)
+	processing := new(big.Int).Mul(new(big.Int).SetUint64(payload.GasLimit-moveBalanceGas), price)
+	return fee.Add(fee, processing.Div(processing, big.NewInt(gasPriceModifierDivisor))), nil
+}
+
+// PriceOracle provides USD exchange rates for MultiversX assets
+type PriceOracle interface {
+	// GetUSDPrice returns the USD price of one whole unit of the asset (e.g. 1 EGLD)
+	GetUSDPrice(ctx context.Context, asset string) (float64, error)
+}
+
+// EstimateUSDValue converts an atomic on-chain amount of asset into its USD value
+// It is the inverse of ParsePrice and is meant for revenue reporting, not settlement.
+func EstimateUSDValue(ctx context.Context, oracle PriceOracle, asset string, amount string, decimals int) (float64, error) {
+	if oracle == nil {
+		return 0, errors.New("price oracle is required")
+	}
+	if decimals < 0 {
+		return 0, fmt.Errorf("invalid decimals: %d", decimals)
+	}
+
+	atomic, err := CheckAmount(amount)
+	if err != nil {
+		return 0, err
+	}
+
+	rate, err := oracle.GetUSDPrice(ctx, asset)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get USD price for %s: %w", asset, err)
+	}
+	if rate < 0 {
+		return 0, fmt.Errorf("invalid USD price for %s: %f", asset, rate)
+	}
+
+	divisor := new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil))
+	units := new(big.Float).Quo(new(big.Float).SetInt(atomic), divisor)
+
+	value, _ := new(big.Float).Mul(units, big.NewFloat(rate)).Float64()
+	return value, nil
+}
+
+// RateProvider returns the current USD price of one EGLD
+type RateProvider func(ctx context.Context) (float64, error)
+
+// NewRateMoneyParser returns a MoneyParser that converts USD prices into atomic EGLD
+// using the live rate from rateProvider. Fractional wei are rounded half up.
+func NewRateMoneyParser(rateProvider RateProvider) x402.MoneyParser {
+	return func(amount float64, network x402.Network) (*x402.AssetAmount, error) {
+		if rateProvider == nil {
+			return nil, errors.New("rate provider is required")
+		}
+
+		rate, err := rateProvider(context.Background())
+		if err != nil {
+			return nil, fmt.Errorf("failed to get EGLD rate: %w", err)
+		}
+		if rate <= 0 {
+			return nil, fmt.Errorf("invalid EGLD rate: %f", rate)
+		}
+
+		wei, err := usdToAtomic(amount, rate, NativeTokenDecimals)
+		if err != nil {
+			return nil, err
+		}
+
+		return &x402.AssetAmount{
+			Asset:  NativeTokenTicker,
+			Amount: wei.String(),
+		}, nil
+	}
+}
+
+// usdToAtomic converts a USD amount into atomic units of a token priced at rate USD, rounding half up
+// Both floats are taken at their shortest decimal representation so that "0.1" stays exactly 0.1.
+func usdToAtomic(usd float64, rate float64, decimals int) (*big.Int, error) {
+	if usd < 0 {
+		return nil, fmt.Errorf("invalid amount: %f", usd)
+	}
+
+	usdRat, ok := new(big.Rat).SetString(strconv.FormatFloat(usd, 'f', -1, 64))
+	if !ok {
+		return nil, fmt.Errorf("invalid amount: %f", usd)
+	}
+	rateRat, ok := new(big.Rat).SetString(strconv.FormatFloat(rate, 'f', -1, 64))
+	if !ok {
+		return nil, fmt.Errorf("invalid rate: %f", rate)
+	}
+
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	atomic := new(big.Rat).Quo(usdRat, rateRat)
+	atomic.Mul(atomic, new(big.Rat).SetInt(scale))
+
+	// round half up: floor(atomic + 1/2)
+	atomic.Add(atomic, big.NewRat(1, 2))
+	return new(big.Int).Quo(atomic.Num(), atomic.Denom()), nil
+}
@@ -0,0 +1,150 @@
+package multiversx
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"testing"
+)
+
+type mockPriceOracle map[string]float64
+
+func (o mockPriceOracle) GetUSDPrice(ctx context.Context, asset string) (float64, error) {
+	rate, ok := o[asset]
+	if !ok {
+		return 0, fmt.Errorf("no rate for %s", asset)
+	}
+	return rate, nil
+}
+
+func TestEstimateUSDValue(t *testing.T) {
+	oracle := mockPriceOracle{
+		NativeTokenTicker: 30.5,
+		"USDC-c76f1f":     1.0,
+	}
+
+	tests := []struct {
+		name     string
+		asset    string
+		amount   string
+		decimals int
+		want     float64
+		wantErr  bool
+	}{
+		{"EGLD", NativeTokenTicker, "1500000000000000000", 18, 45.75, false},
+		{"USDC", "USDC-c76f1f", "2500000", 6, 2.5, false},
+		{"Unknown Asset", "WEGLD-bd4d79", "1", 18, 0, true},
+		{"Invalid Amount", NativeTokenTicker, "1.5", 18, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EstimateUSDValue(context.Background(), oracle, tt.asset, tt.amount, tt.decimals)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("EstimateUSDValue() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("EstimateUSDValue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewRateMoneyParser(t *testing.T) {
+	tests := []struct {
+		name    string
+		rate    float64
+		usd     float64
+		want    string
+		wantErr bool
+	}{
+		{name: "Whole EGLD", rate: 25, usd: 50, want: "2000000000000000000"},
+		{name: "Decimal USD", rate: 1, usd: 0.1, want: "100000000000000000"},
+		{name: "Fractional wei rounds down", rate: 3, usd: 1, want: "333333333333333333"},
+		{name: "Fractional wei rounds up", rate: 3, usd: 2, want: "666666666666666667"},
+		{name: "Exact half wei rounds up", rate: 4, usd: 0.000000000000000002, want: "1"},
+		{name: "Zero rate", rate: 0, usd: 1, wantErr: true},
+		{name: "Negative amount", rate: 25, usd: -1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate := tt.rate
+			parser := NewRateMoneyParser(func(ctx context.Context) (float64, error) {
+				return rate, nil
+			})
+
+			got, err := parser(tt.usd, "multiversx:D")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parser() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.Asset != NativeTokenTicker {
+				t.Errorf("parser() asset = %s, want %s", got.Asset, NativeTokenTicker)
+			}
+			if got.Amount != tt.want {
+				t.Errorf("parser() amount = %s, want %s", got.Amount, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewRateMoneyParser_ProviderError(t *testing.T) {
+	parser := NewRateMoneyParser(func(ctx context.Context) (float64, error) {
+		return 0, fmt.Errorf("rate feed down")
+	})
+
+	if _, err := parser(1, "multiversx:D"); err == nil {
+		t.Fatal("expected error when the rate provider fails")
+	}
+}
+
+func TestEstimateRelayerFee(t *testing.T) {
+	esdtData := "MultiESDTNFTTransfer@8049d639e5a6980d1cd2392abcce41029cda74a1563523a202f09641cc2618f8@01@555344432d633736663166@@0f4240"
+	relayer := "erd1qyu5wthldzr8wx5c9ucg8kjagg0jfs53s8nr3zpz3hypefsdd8ssycr6th"
+
+	tests := []struct {
+		name     string
+		payload  ExactRelayedPayload
+		gasPrice uint64
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:    "move-balance EGLD transfer",
+			payload: ExactRelayedPayload{GasLimit: 50_000, GasPrice: GasPriceDefault},
+			want:    "50000000000000",
+		},
+		{
+			name:     "relayed EGLD transfer with an explicit gas price",
+			payload:  ExactRelayedPayload{GasLimit: 100_000, GasPrice: GasPriceDefault, Relayer: relayer},
+			gasPrice: 2 * GasPriceDefault,
+			want:     "200000000000000",
+		},
+		{
+			// 119 data bytes: 278,500 move-balance gas at full price, the other 221,500 at 1/100
+			name:    "relayed ESDT transfer",
+			payload: ExactRelayedPayload{GasLimit: 500_000, GasPrice: GasPriceDefault, Relayer: relayer, Data: esdtData},
+			want:    "280715000000000",
+		},
+		{
+			name:    "gas limit below the move-balance cost",
+			payload: ExactRelayedPayload{GasLimit: 60_000, GasPrice: GasPriceDefault, Relayer: relayer},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EstimateRelayerFee(tt.payload, tt.gasPrice)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("EstimateRelayerFee() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got.String() != tt.want {
+				t.Errorf("EstimateRelayerFee() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
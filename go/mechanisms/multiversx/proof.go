@@ -0,0 +1,127 @@
+package multiversx
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/multiversx/mx-sdk-go/data"
+
+	x402 "github.com/coinbase/x402/go"
+)
+
+// SettlementProofExtraKey is the SettleResponse Extra key holding the settlement proof
+const SettlementProofExtraKey = "settlementProof"
+
+// settlementProofDomain prefixes the signed message so a proof signature cannot be
+// mistaken for a transaction or message signature of the same key
+const settlementProofDomain = "x402-multiversx-settlement:"
+
+// SettlementProof is a facilitator attestation that a payment was settled on-chain
+// It travels in the SettleResponse Extra, and thus in the X-PAYMENT-RESPONSE header, and can be
+// checked by anyone knowing the facilitator address with VerifySettlementProof.
+type SettlementProof struct {
+	Transaction string `json:"transaction"`
+	Network     string `json:"network"`
+	Payer       string `json:"payer"`
+	PayTo       string `json:"payTo"`
+	Asset       string `json:"asset"`
+	Amount      string `json:"amount"`
+	SettledAt   int64  `json:"settledAt"`
+	Facilitator string `json:"facilitator"`
+	// Signature is the hex encoded Ed25519 signature of the facilitator over Message()
+	Signature string `json:"signature,omitempty"`
+}
+
+// Message returns the bytes signed by the facilitator: the proof without its signature
+func (p SettlementProof) Message() ([]byte, error) {
+	p.Signature = ""
+	body, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(settlementProofDomain), body...), nil
+}
+
+// SignSettlementProof signs the proof with the key of proof.Facilitator held by signer
+func SignSettlementProof(ctx context.Context, signer ExternalSigner, proof SettlementProof) (SettlementProof, error) {
+	msg, err := proof.Message()
+	if err != nil {
+		return proof, err
+	}
+	sig, err := signer.SignBytes(ctx, proof.Facilitator, msg)
+	if err != nil {
+		return proof, fmt.Errorf("failed to sign settlement proof: %w", err)
+	}
+	proof.Signature = hex.EncodeToString(sig)
+	return proof, nil
+}
+
+// VerifySettlementProof checks that the proof was signed by the facilitator address
+func VerifySettlementProof(proof SettlementProof, facilitator string) error {
+	if proof.Facilitator != facilitator {
+		return fmt.Errorf("proof signed by %s, expected %s", proof.Facilitator, facilitator)
+	}
+	if proof.Transaction == "" {
+		return errors.New("proof has no transaction hash")
+	}
+
+	addr, err := data.NewAddressFromBech32String(facilitator)
+	if err != nil {
+		return fmt.Errorf("invalid facilitator address: %w", err)
+	}
+	sig, err := hex.DecodeString(proof.Signature)
+	if err != nil || len(sig) != ed25519.SignatureSize {
+		return errors.New("invalid proof signature encoding")
+	}
+	msg, err := proof.Message()
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(addr.AddressBytes(), msg, sig) {
+		return errors.New("invalid proof signature")
+	}
+	return nil
+}
+
+// SettlementProofFromResponse extracts the settlement proof from a settle response
+// The response may come straight from the facilitator or be decoded from JSON.
+func SettlementProofFromResponse(resp x402.SettleResponse) (SettlementProof, error) {
+	var proof SettlementProof
+
+	raw, ok := resp.Extra[SettlementProofExtraKey]
+	if !ok {
+		return proof, errors.New("settle response has no settlement proof")
+	}
+	if typed, ok := raw.(SettlementProof); ok {
+		return typed, nil
+	}
+
+	encoded, err := json.Marshal(raw)
+	if err != nil {
+		return proof, err
+	}
+	if err := json.Unmarshal(encoded, &proof); err != nil {
+		return proof, fmt.Errorf("invalid settlement proof: %w", err)
+	}
+	return proof, nil
+}
+
+// SettlementProofFromHeader extracts the settlement proof from a base64 encoded
+// PAYMENT-RESPONSE / X-PAYMENT-RESPONSE header value
+func SettlementProofFromHeader(header string) (SettlementProof, error) {
+	body, err := base64.StdEncoding.DecodeString(header)
+	if err != nil {
+		return SettlementProof{}, fmt.Errorf("invalid base64 encoding: %w", err)
+	}
+
+	var resp x402.SettleResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return SettlementProof{}, fmt.Errorf("invalid settle response JSON: %w", err)
+	}
+	return SettlementProofFromResponse(resp)
+}
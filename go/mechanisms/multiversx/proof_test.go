@@ -0,0 +1,74 @@
+package multiversx
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	x402 "github.com/coinbase/x402/go"
+)
+
+func TestSettlementProof_SignAndVerify(t *testing.T) {
+	kms := newMockExternalSigner(t)
+	proof, err := SignSettlementProof(context.Background(), kms, SettlementProof{
+		Transaction: "tx_hash",
+		Network:     "multiversx:D",
+		Payer:       "erd1payer",
+		PayTo:       "erd1merchant",
+		Asset:       NativeTokenTicker,
+		Amount:      "1000",
+		SettledAt:   1700000000,
+		Facilitator: kms.address,
+	})
+	if err != nil {
+		t.Fatalf("SignSettlementProof() error = %v", err)
+	}
+	if err := VerifySettlementProof(proof, kms.address); err != nil {
+		t.Fatalf("VerifySettlementProof() error = %v", err)
+	}
+
+	tampered := proof
+	tampered.Amount = "1"
+	if err := VerifySettlementProof(tampered, kms.address); err == nil {
+		t.Error("Expected tampered proof to be rejected")
+	}
+
+	other := newMockExternalSigner(t)
+	if err := VerifySettlementProof(proof, other.address); err == nil {
+		t.Error("Expected proof to be rejected for another facilitator")
+	}
+
+	forged := proof
+	forged.Facilitator = other.address
+	if err := VerifySettlementProof(forged, other.address); err == nil {
+		t.Error("Expected proof with a swapped facilitator to be rejected")
+	}
+}
+
+func TestSettlementProofFromHeader(t *testing.T) {
+	kms := newMockExternalSigner(t)
+	proof, _ := SignSettlementProof(context.Background(), kms, SettlementProof{Transaction: "tx_hash", Facilitator: kms.address})
+
+	resp := x402.SettleResponse{
+		Success:     true,
+		Transaction: "tx_hash",
+		Extra:       map[string]interface{}{SettlementProofExtraKey: proof},
+	}
+	body, _ := json.Marshal(resp)
+
+	decoded, err := SettlementProofFromHeader(base64.StdEncoding.EncodeToString(body))
+	if err != nil {
+		t.Fatalf("SettlementProofFromHeader() error = %v", err)
+	}
+	if decoded != proof {
+		t.Errorf("decoded = %+v, want %+v", decoded, proof)
+	}
+	if err := VerifySettlementProof(decoded, kms.address); err != nil {
+		t.Errorf("VerifySettlementProof() error = %v", err)
+	}
+
+	if _, err := SettlementProofFromResponse(x402.SettleResponse{}); err == nil {
+		t.Error("Expected error for a response without proof")
+	}
+}
@@ -0,0 +1,151 @@
+package multiversx
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/coinbase/x402/go/types"
+)
+
+// RequirementBuilder builds MultiversX payment requirements with the Extra keys the client and
+// facilitator expect, validating the inputs in Build
+//
+//	requirements, err := multiversx.NewRequirementBuilder().
+//		Network("multiversx:D").
+//		PayTo(merchant).
+//		ESDT("USDC-c76f1f", "1000000").
+//		Relayer(relayer).
+//		Build()
+type RequirementBuilder struct {
+	network    string
+	payTo      string
+	asset      string
+	amount     string
+	relayer    string
+	scFunction string
+	arguments  []string
+	memo       string
+	gasLimit   uint64
+	maxTimeout int
+}
+
+// NewRequirementBuilder creates a builder for an exact payment
+func NewRequirementBuilder() *RequirementBuilder {
+	return &RequirementBuilder{}
+}
+
+// Network sets the CAIP-2 network of the payment, e.g. "multiversx:1"
+func (b *RequirementBuilder) Network(network string) *RequirementBuilder {
+	b.network = network
+	return b
+}
+
+// PayTo sets the bech32 address receiving the payment
+func (b *RequirementBuilder) PayTo(address string) *RequirementBuilder {
+	b.payTo = address
+	return b
+}
+
+// EGLD requires amount atomic units of native EGLD, sent through the transaction value
+func (b *RequirementBuilder) EGLD(amount string) *RequirementBuilder {
+	b.asset = NativeTokenTicker
+	b.amount = amount
+	return b
+}
+
+// ESDT requires amount atomic units of the token, sent through a MultiESDTNFTTransfer
+func (b *RequirementBuilder) ESDT(tokenID string, amount string) *RequirementBuilder {
+	b.asset = tokenID
+	b.amount = amount
+	return b
+}
+
+// SCCall makes the payment call function on PayTo with the hex encoded arguments
+func (b *RequirementBuilder) SCCall(function string, arguments ...string) *RequirementBuilder {
+	b.scFunction = function
+	b.arguments = arguments
+	return b
+}
+
+// Relayer sets the facilitator address paying the gas of a relayed payment
+// Without a relayer EGLD payments are direct transactions paid by the sender.
+func (b *RequirementBuilder) Relayer(address string) *RequirementBuilder {
+	b.relayer = address
+	return b
+}
+
+// Memo sets the memo a plain EGLD payment carries in its data field
+func (b *RequirementBuilder) Memo(memo string) *RequirementBuilder {
+	b.memo = memo
+	return b
+}
+
+// GasLimit sets an explicit gas limit; by default the client estimates it from the transaction
+func (b *RequirementBuilder) GasLimit(gasLimit uint64) *RequirementBuilder {
+	b.gasLimit = gasLimit
+	return b
+}
+
+// MaxTimeout sets how many seconds the payment stays valid
+func (b *RequirementBuilder) MaxTimeout(seconds int) *RequirementBuilder {
+	b.maxTimeout = seconds
+	return b
+}
+
+// Build validates the inputs and returns the requirements
+func (b *RequirementBuilder) Build() (types.PaymentRequirements, error) {
+	if _, err := GetMultiversXChainId(b.network); err != nil {
+		return types.PaymentRequirements{}, err
+	}
+	if !IsValidAddress(b.payTo) {
+		return types.PaymentRequirements{}, fmt.Errorf("invalid PayTo address: %q", b.payTo)
+	}
+	if b.asset == "" {
+		return types.PaymentRequirements{}, errors.New("an EGLD or ESDT amount is required")
+	}
+	if b.asset != NativeTokenTicker && !IsValidTokenID(b.asset) {
+		return types.PaymentRequirements{}, fmt.Errorf("invalid asset TokenID: %s", b.asset)
+	}
+	if _, err := CheckAmount(b.amount); err != nil {
+		return types.PaymentRequirements{}, err
+	}
+	if b.relayer != "" && !IsValidAddress(b.relayer) {
+		return types.PaymentRequirements{}, fmt.Errorf("invalid relayer address: %q", b.relayer)
+	}
+	if b.memo != "" && (b.asset != NativeTokenTicker || b.scFunction != "") {
+		return types.PaymentRequirements{}, errors.New("a memo is only carried by plain EGLD payments")
+	}
+
+	extra := map[string]interface{}{}
+	switch {
+	case b.asset != NativeTokenTicker:
+		extra["assetTransferMethod"] = TransferMethodESDT
+	case b.relayer == "":
+		extra["assetTransferMethod"] = TransferMethodDirect
+	}
+	if b.relayer != "" {
+		extra["relayer"] = b.relayer
+	}
+	if b.scFunction != "" {
+		extra["scFunction"] = b.scFunction
+		if len(b.arguments) > 0 {
+			extra["arguments"] = b.arguments
+		}
+	}
+	if b.memo != "" {
+		extra["memo"] = b.memo
+	}
+	if b.gasLimit > 0 {
+		extra["gasLimit"] = b.gasLimit
+	}
+
+	return types.PaymentRequirements{
+		Scheme:            SchemeExact,
+		Network:           b.network,
+		Asset:             b.asset,
+		Amount:            b.amount,
+		PayTo:             b.payTo,
+		MaxTimeoutSeconds: b.maxTimeout,
+		Extra:             extra,
+	}, nil
+}
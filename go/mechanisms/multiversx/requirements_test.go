@@ -0,0 +1,111 @@
+package multiversx
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/coinbase/x402/go/types"
+)
+
+func TestRequirementBuilder(t *testing.T) {
+	payTo := "erd1spyavw0956vq68xj8y4tenjpq2wd5a9p2c6j8gsz7ztyrnpxrruqzu66jx"
+	relayer := "erd1qyu5wthldzr8wx5c9ucg8kjagg0jfs53s8nr3zpz3hypefsdd8ssycr6th"
+	base := func() *RequirementBuilder {
+		return NewRequirementBuilder().Network("multiversx:D").PayTo(payTo)
+	}
+
+	tests := []struct {
+		name    string
+		builder *RequirementBuilder
+		want    types.PaymentRequirements
+	}{
+		{
+			name:    "Direct EGLD",
+			builder: base().EGLD("1000").Memo("order-7"),
+			want: types.PaymentRequirements{
+				Scheme: SchemeExact, Network: "multiversx:D", Asset: NativeTokenTicker, Amount: "1000", PayTo: payTo,
+				Extra: map[string]interface{}{"assetTransferMethod": TransferMethodDirect, "memo": "order-7"},
+			},
+		},
+		{
+			name:    "Relayed EGLD",
+			builder: base().EGLD("1000").Relayer(relayer).MaxTimeout(60),
+			want: types.PaymentRequirements{
+				Scheme: SchemeExact, Network: "multiversx:D", Asset: NativeTokenTicker, Amount: "1000", PayTo: payTo, MaxTimeoutSeconds: 60,
+				Extra: map[string]interface{}{"relayer": relayer},
+			},
+		},
+		{
+			name:    "ESDT",
+			builder: base().ESDT("USDC-c76f1f", "500").Relayer(relayer),
+			want: types.PaymentRequirements{
+				Scheme: SchemeExact, Network: "multiversx:D", Asset: "USDC-c76f1f", Amount: "500", PayTo: payTo,
+				Extra: map[string]interface{}{"assetTransferMethod": TransferMethodESDT, "relayer": relayer},
+			},
+		},
+		{
+			name:    "ESDT SC Call",
+			builder: base().ESDT("USDC-c76f1f", "500").SCCall("buy", "01", "0a").Relayer(relayer).GasLimit(5_000_000),
+			want: types.PaymentRequirements{
+				Scheme: SchemeExact, Network: "multiversx:D", Asset: "USDC-c76f1f", Amount: "500", PayTo: payTo,
+				Extra: map[string]interface{}{
+					"assetTransferMethod": TransferMethodESDT, "relayer": relayer,
+					"scFunction": "buy", "arguments": []string{"01", "0a"}, "gasLimit": uint64(5_000_000),
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.builder.Build()
+			if err != nil {
+				t.Fatalf("Build() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Build() = %+v, want %+v", got, tt.want)
+			}
+
+			// The requirements encode and verify through their resolved transfer method
+			handler, err := ResolveTransferMethodHandler(got)
+			if err != nil {
+				t.Fatalf("ResolveTransferMethodHandler() error = %v", err)
+			}
+			fields, err := handler.Encode(got, payTo)
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			payload := ExactRelayedPayload{Sender: payTo, Receiver: fields.Receiver, Value: fields.Value, Data: fields.Data}
+			if err := handler.Verify(payload, got); err != nil {
+				t.Errorf("Verify() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestRequirementBuilder_Invalid(t *testing.T) {
+	payTo := "erd1spyavw0956vq68xj8y4tenjpq2wd5a9p2c6j8gsz7ztyrnpxrruqzu66jx"
+	base := func() *RequirementBuilder {
+		return NewRequirementBuilder().Network("multiversx:D").PayTo(payTo)
+	}
+
+	tests := []struct {
+		name    string
+		builder *RequirementBuilder
+	}{
+		{"Unknown Network", NewRequirementBuilder().Network("ethereum:1").PayTo(payTo).EGLD("1")},
+		{"Invalid PayTo", base().PayTo("erd1invalid").EGLD("1")},
+		{"Missing Amount", base()},
+		{"Decimal Amount", base().EGLD("1.5")},
+		{"Invalid Token", base().ESDT("usdc", "1")},
+		{"Invalid Relayer", base().EGLD("1").Relayer("erd1invalid")},
+		{"Memo On Token", base().ESDT("USDC-c76f1f", "1").Memo("order-7")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.builder.Build(); err == nil {
+				t.Error("Expected Build() to fail")
+			}
+		})
+	}
+}
@@ -0,0 +1,570 @@
+package multiversx
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/multiversx/mx-sdk-go/data"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/types"
+)
+
+// TransferFields holds the transaction fields produced by a transfer method
+type TransferFields struct {
+	Receiver string
+	Value    string
+	Data     string
+}
+
+// TransferMethodHandler encodes and verifies a single asset transfer method.
+// The client uses Encode to build the transaction, the facilitator uses Verify
+// to check that a signed payload pays what the requirements ask for.
+type TransferMethodHandler interface {
+	// Encode returns the receiver, value and data fields for a payment made by sender
+	Encode(requirements types.PaymentRequirements, sender string) (TransferFields, error)
+
+	// Verify checks that the payload satisfies the requirements
+	// Returning an *x402.VerifyError lets the facilitator report a specific reason code.
+	Verify(payload ExactRelayedPayload, requirements types.PaymentRequirements) error
+}
+
+var (
+	transferMethodsMu sync.RWMutex
+	transferMethods   = map[string]TransferMethodHandler{
+		TransferMethodDirect: &directTransferHandler{},
+		TransferMethodESDT:   &esdtTransferHandler{},
+	}
+)
+
+// ErrBuiltinTransferMethod is returned when registering or unregistering the built-in direct and esdt methods
+// Their handlers carry the facilitator receiver and amount checks and cannot be swapped out.
+var ErrBuiltinTransferMethod = errors.New("built-in transfer method cannot be replaced")
+
+// RegisterTransferMethod registers a handler for the given transfer method name.
+// Registering an existing custom name replaces the previous handler; the built-in direct and esdt
+// methods cannot be replaced and fail with ErrBuiltinTransferMethod.
+func RegisterTransferMethod(name string, handler TransferMethodHandler) error {
+	if name == "" {
+		return errors.New("transfer method name is required")
+	}
+	if handler == nil {
+		return fmt.Errorf("handler for transfer method %s is nil", name)
+	}
+	if isBuiltinTransferMethod(name) {
+		return fmt.Errorf("%w: %s", ErrBuiltinTransferMethod, name)
+	}
+
+	transferMethodsMu.Lock()
+	defer transferMethodsMu.Unlock()
+	transferMethods[name] = handler
+	return nil
+}
+
+// UnregisterTransferMethod removes the handler registered for a custom transfer method name
+// Unknown names are ignored; the built-in methods fail with ErrBuiltinTransferMethod.
+func UnregisterTransferMethod(name string) error {
+	if isBuiltinTransferMethod(name) {
+		return fmt.Errorf("%w: %s", ErrBuiltinTransferMethod, name)
+	}
+
+	transferMethodsMu.Lock()
+	defer transferMethodsMu.Unlock()
+	delete(transferMethods, name)
+	return nil
+}
+
+// isBuiltinTransferMethod reports whether name is one of the transfer methods shipped with the scheme
+func isBuiltinTransferMethod(name string) bool {
+	return name == TransferMethodDirect || name == TransferMethodESDT
+}
+
+// GetTransferMethodHandler returns the handler registered for the given transfer method name
+func GetTransferMethodHandler(name string) (TransferMethodHandler, bool) {
+	transferMethodsMu.RLock()
+	defer transferMethodsMu.RUnlock()
+	handler, ok := transferMethods[name]
+	return handler, ok
+}
+
+// TransferMethods returns the names of the registered transfer methods in sorted order
+func TransferMethods() []string {
+	transferMethodsMu.RLock()
+	defer transferMethodsMu.RUnlock()
+
+	names := make([]string, 0, len(transferMethods))
+	for name := range transferMethods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ResolveTransferMethod returns the name of the transfer method used to encode the payment.
+// A registered custom "assetTransferMethod" is used as is; otherwise native EGLD resolves to
+// direct (unless ESDT is explicitly requested) and every other asset resolves to ESDT.
+func ResolveTransferMethod(requirements types.PaymentRequirements) string {
+	method, _ := ExtraString(requirements.Extra, "assetTransferMethod")
+
+	if method != "" && method != TransferMethodDirect && method != TransferMethodESDT {
+		return method
+	}
+
+	if requirements.Asset == NativeTokenTicker && method != TransferMethodESDT && !hasTransferList(requirements) {
+		return TransferMethodDirect
+	}
+	return TransferMethodESDT
+}
+
+// ResolveTransferMethodHandler returns the handler for the transfer method resolved from the requirements
+func ResolveTransferMethodHandler(requirements types.PaymentRequirements) (TransferMethodHandler, error) {
+	method := ResolveTransferMethod(requirements)
+	handler, ok := GetTransferMethodHandler(method)
+	if !ok {
+		return nil, fmt.Errorf("unsupported transfer method: %s", method)
+	}
+	return handler, nil
+}
+
+// scCallArguments extracts the optional SC function and arguments from the requirements
+func scCallArguments(requirements types.PaymentRequirements) (string, []string) {
+	scFunction, _ := ExtraString(requirements.Extra, "scFunction")
+	arguments, _ := ExtraStringSlice(requirements.Extra, "arguments")
+	return scFunction, arguments
+}
+
+// transferMemos extracts the optional memo of a plain EGLD transfer and the memo the facilitator must find
+func transferMemos(requirements types.PaymentRequirements) (memo string, expected string, hasExpected bool) {
+	memo, _ = ExtraString(requirements.Extra, "memo")
+	expected, hasExpected = ExtraString(requirements.Extra, "expectedMemo")
+	return memo, expected, hasExpected
+}
+
+// requiredTokenNonce extracts the optional SFT/NFT token nonce from the requirements
+func requiredTokenNonce(requirements types.PaymentRequirements) (uint64, bool) {
+	return ExtraUint64(requirements.Extra, "tokenNonce")
+}
+
+// TokenTransfer is a single token payment within a MultiESDTNFTTransfer
+type TokenTransfer struct {
+	Asset  string
+	Amount string
+	Nonce  uint64
+	// HasNonce reports whether the token nonce was specified and must be verified
+	HasNonce bool
+}
+
+// hasTransferList reports whether the requirements list several transfers in Extra["transfers"]
+func hasTransferList(requirements types.PaymentRequirements) bool {
+	_, ok := requirements.Extra["transfers"]
+	return ok
+}
+
+// RequiredTransfers returns the token transfers a payment must contain.
+// Extra["transfers"] lists several {"asset", "amount", "tokenNonce"} entries to pay a bundle of
+// tokens at once; without it the requirement Asset and Amount form a single transfer.
+// Extra["egldValue"] adds native EGLD to a token payment, sent as an EGLD-000000 transfer.
+// Native EGLD is always listed as EGLD-000000, whether the asset is given as EGLD or EGLD-000000.
+func RequiredTransfers(requirements types.PaymentRequirements) ([]TokenTransfer, error) {
+	transfers, err := requiredTokenTransfers(requirements)
+	if err != nil {
+		return nil, err
+	}
+
+	egldValue, ok := ExtraString(requirements.Extra, "egldValue")
+	if !ok || IsNativeAsset(requirements.Asset) {
+		return transfers, nil
+	}
+	if _, err := CheckAmount(egldValue); err != nil {
+		return nil, fmt.Errorf("invalid egldValue: %w", err)
+	}
+	return append(transfers, TokenTransfer{Asset: NativeTokenMultiTransferID, Amount: egldValue}), nil
+}
+
+// requiredTokenTransfers returns the transfers listed in Extra["transfers"], or the single Asset and Amount transfer
+func requiredTokenTransfers(requirements types.PaymentRequirements) ([]TokenTransfer, error) {
+	raw, ok := requirements.Extra["transfers"]
+	if !ok {
+		nonce, hasNonce := requiredTokenNonce(requirements)
+		return []TokenTransfer{{
+			Asset:    MultiTransferAssetID(requirements.Asset),
+			Amount:   requirements.Amount,
+			Nonce:    nonce,
+			HasNonce: hasNonce,
+		}}, nil
+	}
+
+	var entries []map[string]interface{}
+	switch v := raw.(type) {
+	case []map[string]interface{}:
+		entries = v
+	case []interface{}:
+		for i, item := range v {
+			entry, ok := item.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("invalid transfer at index %d", i)
+			}
+			entries = append(entries, entry)
+		}
+	default:
+		return nil, errors.New("transfers must be a list of asset/amount pairs")
+	}
+
+	if len(entries) == 0 {
+		return nil, errors.New("transfers list is empty")
+	}
+	if len(entries) > 255 {
+		return nil, fmt.Errorf("too many transfers: %d", len(entries))
+	}
+
+	transfers := make([]TokenTransfer, 0, len(entries))
+	for i, entry := range entries {
+		asset, hasAsset := ExtraString(entry, "asset")
+		amount, hasAmount := ExtraString(entry, "amount")
+		if !hasAsset || !hasAmount {
+			return nil, fmt.Errorf("transfer at index %d requires asset and amount", i)
+		}
+		nonce, hasNonce := ExtraUint64(entry, "tokenNonce")
+		transfers = append(transfers, TokenTransfer{
+			Asset:    MultiTransferAssetID(asset),
+			Amount:   amount,
+			Nonce:    nonce,
+			HasNonce: hasNonce,
+		})
+	}
+
+	return transfers, nil
+}
+
+// encodeTokenNonce hex encodes a token nonce the way MultiESDTNFTTransfer arguments expect
+func encodeTokenNonce(nonce uint64) string {
+	if nonce == 0 {
+		return "00"
+	}
+	return hex.EncodeToString(new(big.Int).SetUint64(nonce).Bytes())
+}
+
+// directTransferHandler sends native EGLD through the transaction value
+type directTransferHandler struct{}
+
+func (h *directTransferHandler) Encode(requirements types.PaymentRequirements, sender string) (TransferFields, error) {
+	scFunction, arguments := scCallArguments(requirements)
+
+	// A plain transfer carries the memo as-is; it is never interpreted as a call
+	if scFunction == "" {
+		memo, _, _ := transferMemos(requirements)
+		return TransferFields{
+			Receiver: requirements.PayTo,
+			Value:    requirements.Amount,
+			Data:     memo,
+		}, nil
+	}
+
+	parts := append([]string{scFunction}, arguments...)
+	return TransferFields{
+		Receiver: requirements.PayTo,
+		Value:    requirements.Amount,
+		Data:     strings.Join(parts, "@"),
+	}, nil
+}
+
+func (h *directTransferHandler) Verify(payload ExactRelayedPayload, requirements types.PaymentRequirements) error {
+	if payload.Receiver != requirements.PayTo {
+		return mismatch(ErrCodeReceiverMismatch, payload, "expected %s, got %s", requirements.PayTo, payload.Receiver)
+	}
+	if !CheckBigInt(payload.Value, requirements.Amount) {
+		return mismatch(ErrCodeAmountMismatch, payload, "expected %s, got %s", requirements.Amount, payload.Value)
+	}
+	return verifyDirectData(payload, requirements)
+}
+
+// verifyDirectData checks the data field of a direct EGLD payment against the required SC call
+// A plain transfer carries no data or the Extra["memo"]; an SC call carries "function@arg1@arg2...".
+// When Extra["expectedMemo"] is set, a plain transfer must carry exactly that memo.
+func verifyDirectData(payload ExactRelayedPayload, requirements types.PaymentRequirements) error {
+	scFunction, _ := scCallArguments(requirements)
+	if scFunction == "" {
+		memo, expected, hasExpected := transferMemos(requirements)
+		switch {
+		case hasExpected && payload.Data != expected:
+			return mismatch(ErrCodeMemoMismatch, payload, "expected memo %q, got %q", expected, payload.Data)
+		case !hasExpected && payload.Data != "" && payload.Data != memo:
+			return mismatch(ErrCodeInvalidTransferData, payload, "plain EGLD transfer data must be empty or the memo")
+		}
+		return nil
+	}
+	if payload.Data == "" {
+		return mismatch(ErrCodeInvalidTransferData, payload, "SC call %q requires a data field", scFunction)
+	}
+
+	parts := strings.Split(payload.Data, "@")
+	call := MultiESDTTransfer{Function: parts[0]}
+	for _, arg := range parts[1:] {
+		call.Arguments = append(call.Arguments, strings.ToLower(arg))
+	}
+	return matchSCCall(payload, call, requirements)
+}
+
+// esdtTransferHandler sends a single token through a MultiESDTNFTTransfer self-transfer
+type esdtTransferHandler struct{}
+
+func (h *esdtTransferHandler) Encode(requirements types.PaymentRequirements, sender string) (TransferFields, error) {
+	scFunction, arguments := scCallArguments(requirements)
+
+	payToAddr, err := data.NewAddressFromBech32String(requirements.PayTo)
+	if err != nil {
+		return TransferFields{}, fmt.Errorf("invalid PayTo address: %w", err)
+	}
+	destHex := hex.EncodeToString(payToAddr.AddressBytes())
+
+	transfers, err := RequiredTransfers(requirements)
+	if err != nil {
+		return TransferFields{}, err
+	}
+
+	parts := []string{
+		"MultiESDTNFTTransfer",
+		destHex,
+		hex.EncodeToString([]byte{byte(len(transfers))}),
+	}
+
+	for _, transfer := range transfers {
+		amtBig, ok := new(big.Int).SetString(transfer.Amount, 10)
+		if !ok {
+			return TransferFields{}, fmt.Errorf("invalid amount: %s", transfer.Amount)
+		}
+
+		parts = append(parts,
+			hex.EncodeToString([]byte(transfer.Asset)),
+			encodeTokenNonce(transfer.Nonce),
+			hex.EncodeToString(amtBig.Bytes()),
+		)
+	}
+
+	if scFunction != "" {
+		parts = append(parts, hex.EncodeToString([]byte(scFunction)))
+		if len(arguments) > 0 {
+			parts = append(parts, arguments...)
+		}
+	}
+
+	return TransferFields{
+		Receiver: sender,
+		Value:    "0",
+		Data:     strings.Join(parts, "@"),
+	}, nil
+}
+
+func (h *esdtTransferHandler) Verify(payload ExactRelayedPayload, requirements types.PaymentRequirements) error {
+	transfer, err := DecodeMultiESDTTransfer(payload.Data)
+	if err != nil {
+		return mismatch(ErrCodeInvalidTransferData, payload, "%v", err)
+	}
+
+	expectedAddr, err := data.NewAddressFromBech32String(requirements.PayTo)
+	if err != nil {
+		return mismatch(ErrCodeInvalidRequirements, payload, "invalid expected receiver format: %v", err)
+	}
+	if !bytes.Equal(transfer.Destination, expectedAddr.AddressBytes()) {
+		return mismatch(ErrCodeReceiverMismatch, payload, "encoded destination %s does not match requirement %s", hex.EncodeToString(transfer.Destination), requirements.PayTo)
+	}
+
+	expected, err := RequiredTransfers(requirements)
+	if err != nil {
+		return mismatch(ErrCodeInvalidRequirements, payload, "%v", err)
+	}
+
+	// Every expected transfer must be covered by a distinct encoded transfer
+	used := make([]bool, len(transfer.Transfers))
+	for _, want := range expected {
+		if err := matchTokenTransfer(payload, want, transfer.Transfers, used); err != nil {
+			return err
+		}
+	}
+
+	return matchSCCall(payload, transfer, requirements)
+}
+
+// MultiESDTTransfer is the decoded data field of a MultiESDTNFTTransfer transaction
+type MultiESDTTransfer struct {
+	// Destination is the raw public key receiving the tokens
+	Destination []byte
+	Transfers   []TokenTransfer
+	// Function is the smart contract endpoint called on Destination, empty for a plain transfer
+	Function string
+	// Arguments are the hex encoded arguments of Function, in lowercase
+	Arguments []string
+}
+
+// DecodeMultiESDTTransfer parses a MultiESDTNFTTransfer data field into its destination,
+// token transfers and the optional smart contract call that follows them.
+// Hex arguments are accepted in either case.
+func DecodeMultiESDTTransfer(dataField string) (MultiESDTTransfer, error) {
+	parts := strings.Split(dataField, "@")
+	if len(parts) < 6 || parts[0] != "MultiESDTNFTTransfer" {
+		return MultiESDTTransfer{}, errors.New("invalid ESDT transfer data format (expected MultiESDTNFTTransfer)")
+	}
+	// Other implementations may emit uppercase hex, so decode in lowercase
+	for i := 1; i < len(parts); i++ {
+		parts[i] = strings.ToLower(parts[i])
+	}
+
+	destination, err := hex.DecodeString(parts[1])
+	if err != nil {
+		return MultiESDTTransfer{}, errors.New("invalid receiver hex")
+	}
+
+	countBytes, err := hex.DecodeString(parts[2])
+	if err != nil || len(countBytes) == 0 {
+		return MultiESDTTransfer{}, errors.New("invalid transfer count hex")
+	}
+	count := new(big.Int).SetBytes(countBytes)
+	if !count.IsInt64() || count.Int64() == 0 || int64(len(parts)) < 3+3*count.Int64() {
+		return MultiESDTTransfer{}, fmt.Errorf("invalid transfer count: %s", count.String())
+	}
+	n := int(count.Int64())
+
+	transfers := make([]TokenTransfer, 0, n)
+	for i := 0; i < n; i++ {
+		transfer, err := decodeTokenTransfer(parts[3+3*i : 6+3*i])
+		if err != nil {
+			return MultiESDTTransfer{}, err
+		}
+		transfers = append(transfers, transfer)
+	}
+
+	decoded := MultiESDTTransfer{
+		Destination: destination,
+		Transfers:   transfers,
+	}
+
+	rest := parts[3+3*n:]
+	if len(rest) > 0 {
+		function, err := hex.DecodeString(rest[0])
+		if err != nil || len(function) == 0 {
+			return MultiESDTTransfer{}, errors.New("invalid function name hex")
+		}
+		for _, arg := range rest[1:] {
+			if !IsValidHex(arg) {
+				return MultiESDTTransfer{}, fmt.Errorf("invalid argument hex: %s", arg)
+			}
+		}
+		decoded.Function = string(function)
+		decoded.Arguments = rest[1:]
+	}
+
+	return decoded, nil
+}
+
+// DecodeSCCall parses the data field of a native smart contract call, "function@arg1@arg2...",
+// into the function name and its hex decoded arguments, e.g. for logging or auditing a payment.
+// Empty arguments ("buy@@01") decode to empty byte slices.
+func DecodeSCCall(data string) (function string, args [][]byte, err error) {
+	parts := strings.Split(data, "@")
+	if parts[0] == "" {
+		return "", nil, errors.New("invalid SC call data: missing function name")
+	}
+	if strings.ContainsAny(parts[0], " \t\n") {
+		return "", nil, fmt.Errorf("invalid SC call function name %q", parts[0])
+	}
+
+	args = make([][]byte, 0, len(parts)-1)
+	for i, arg := range parts[1:] {
+		decoded, err := hex.DecodeString(arg)
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid SC call argument %d hex: %s", i, arg)
+		}
+		args = append(args, decoded)
+	}
+	return parts[0], args, nil
+}
+
+// matchSCCall checks the decoded smart contract call against Extra["scFunction"] and Extra["arguments"].
+// A payload without a required function must not call one either.
+func matchSCCall(payload ExactRelayedPayload, transfer MultiESDTTransfer, requirements types.PaymentRequirements) error {
+	scFunction, arguments := scCallArguments(requirements)
+
+	if transfer.Function != scFunction {
+		return mismatch(ErrCodeSCCallMismatch, payload, "expected function %q, got %q", scFunction, transfer.Function)
+	}
+	if len(transfer.Arguments) != len(arguments) {
+		return mismatch(ErrCodeSCCallMismatch, payload, "expected %d arguments, got %d", len(arguments), len(transfer.Arguments))
+	}
+	for i, arg := range arguments {
+		if transfer.Arguments[i] != strings.ToLower(arg) {
+			return mismatch(ErrCodeSCCallMismatch, payload, "argument %d: expected %s, got %s", i, arg, transfer.Arguments[i])
+		}
+	}
+	return nil
+}
+
+// decodeTokenTransfer decodes the token, nonce and amount arguments of a single transfer
+func decodeTokenTransfer(args []string) (TokenTransfer, error) {
+	tokenBytes, err := hex.DecodeString(args[0])
+	if err != nil {
+		return TokenTransfer{}, fmt.Errorf("invalid token hex")
+	}
+
+	nonceBytes, err := hex.DecodeString(args[1])
+	if err != nil {
+		return TokenTransfer{}, fmt.Errorf("invalid token nonce hex")
+	}
+	nonce := new(big.Int).SetBytes(nonceBytes)
+	if !nonce.IsUint64() {
+		return TokenTransfer{}, fmt.Errorf("invalid token nonce: %s", nonce.String())
+	}
+
+	amountBytes, err := hex.DecodeString(args[2])
+	if err != nil {
+		return TokenTransfer{}, fmt.Errorf("invalid amount hex")
+	}
+
+	return TokenTransfer{
+		Asset:    string(tokenBytes),
+		Amount:   new(big.Int).SetBytes(amountBytes).String(),
+		Nonce:    nonce.Uint64(),
+		HasNonce: true,
+	}, nil
+}
+
+// matchTokenTransfer marks the first unused actual transfer that satisfies want
+func matchTokenTransfer(payload ExactRelayedPayload, want TokenTransfer, actual []TokenTransfer, used []bool) error {
+	expectedBig, ok := new(big.Int).SetString(want.Amount, 10)
+	if !ok {
+		return mismatch(ErrCodeInvalidRequirements, payload, "invalid expected amount: %s", want.Amount)
+	}
+
+	var closest error
+	for i, got := range actual {
+		if used[i] || got.Asset != want.Asset {
+			continue
+		}
+		if want.HasNonce && got.Nonce != want.Nonce {
+			closest = mismatch(ErrCodeTokenNonceMismatch, payload, "expected %d, got %d", want.Nonce, got.Nonce)
+			continue
+		}
+		amountBig, _ := new(big.Int).SetString(got.Amount, 10)
+		if amountBig.Cmp(expectedBig) < 0 {
+			closest = mismatch(ErrCodeAmountMismatch, payload, "expected at least %s %s, got %s", want.Amount, want.Asset, got.Amount)
+			continue
+		}
+		used[i] = true
+		return nil
+	}
+
+	if closest != nil {
+		return closest
+	}
+	return mismatch(ErrCodeAssetMismatch, payload, "no transfer of %s found", want.Asset)
+}
+
+// mismatch builds the typed verify error returned by the built-in transfer handlers
+func mismatch(reason string, payload ExactRelayedPayload, format string, args ...interface{}) error {
+	return x402.NewVerifyError(reason, payload.Sender, "multiversx", fmt.Errorf(format, args...))
+}
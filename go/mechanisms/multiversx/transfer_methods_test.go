@@ -0,0 +1,658 @@
+package multiversx
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	x402 "github.com/coinbase/x402/go"
+	"github.com/coinbase/x402/go/types"
+)
+
+func TestESDTTransferHandler_TokenNonce(t *testing.T) {
+	payTo := "erd1spyavw0956vq68xj8y4tenjpq2wd5a9p2c6j8gsz7ztyrnpxrruqzu66jx"
+	handler, ok := GetTransferMethodHandler(TransferMethodESDT)
+	if !ok {
+		t.Fatal("ESDT handler not registered")
+	}
+
+	req := types.PaymentRequirements{
+		PayTo:  payTo,
+		Amount: "1",
+		Asset:  "TICKET-abcdef",
+		Extra: map[string]interface{}{
+			"tokenNonce": uint64(42),
+		},
+	}
+
+	fields, err := handler.Encode(req, payTo)
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	payload := ExactRelayedPayload{Sender: payTo, Receiver: fields.Receiver, Value: fields.Value, Data: fields.Data}
+
+	if err := handler.Verify(payload, req); err != nil {
+		t.Errorf("Expected matching token nonce to verify, got %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		tokenNonce interface{}
+		wantErr    bool
+	}{
+		{"Matching Float Nonce", float64(42), false},
+		{"Mismatched Nonce", uint64(41), true},
+		{"Fungible Nonce Expected", uint64(0), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mismatched := req
+			mismatched.Extra = map[string]interface{}{"tokenNonce": tt.tokenNonce}
+			err := handler.Verify(payload, mismatched)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// Without a required nonce the token nonce is not checked
+	unconstrained := req
+	unconstrained.Extra = nil
+	if err := handler.Verify(payload, unconstrained); err != nil {
+		t.Errorf("Expected verification without tokenNonce requirement to pass, got %v", err)
+	}
+}
+
+func TestESDTTransferHandler_FungibleNonceEncoding(t *testing.T) {
+	payTo := "erd1spyavw0956vq68xj8y4tenjpq2wd5a9p2c6j8gsz7ztyrnpxrruqzu66jx"
+	handler, _ := GetTransferMethodHandler(TransferMethodESDT)
+
+	fields, err := handler.Encode(types.PaymentRequirements{PayTo: payTo, Amount: "100", Asset: "USDC-123456"}, payTo)
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+
+	expectedSuffix := fmt.Sprintf("@01@%s@00@64", hex.EncodeToString([]byte("USDC-123456")))
+	if !strings.HasSuffix(fields.Data, expectedSuffix) {
+		t.Errorf("Expected fungible transfer to encode nonce 00, got %s", fields.Data)
+	}
+}
+
+func TestESDTTransferHandler_UppercaseHex(t *testing.T) {
+	payTo := "erd1spyavw0956vq68xj8y4tenjpq2wd5a9p2c6j8gsz7ztyrnpxrruqzu66jx"
+	handler, _ := GetTransferMethodHandler(TransferMethodESDT)
+
+	req := types.PaymentRequirements{PayTo: payTo, Amount: "1000", Asset: "USDC-c76f1f"}
+	fields, err := handler.Encode(req, payTo)
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+
+	// Uppercase every argument, keeping the function name intact
+	parts := strings.Split(fields.Data, "@")
+	for i := 1; i < len(parts); i++ {
+		parts[i] = strings.ToUpper(parts[i])
+	}
+	upper := strings.Join(parts, "@")
+	if upper == fields.Data {
+		t.Fatal("Expected the uppercased data to differ from the encoded data")
+	}
+
+	payload := ExactRelayedPayload{Sender: payTo, Receiver: payTo, Value: "0", Data: upper}
+	if err := handler.Verify(payload, req); err != nil {
+		t.Errorf("Expected uppercase hex payload to verify, got %v", err)
+	}
+}
+
+func TestESDTTransferHandler_MultiTokenBundle(t *testing.T) {
+	payTo := "erd1spyavw0956vq68xj8y4tenjpq2wd5a9p2c6j8gsz7ztyrnpxrruqzu66jx"
+	handler, _ := GetTransferMethodHandler(TransferMethodESDT)
+
+	// Transfers as they arrive after a JSON round trip
+	req := types.PaymentRequirements{
+		PayTo:  payTo,
+		Amount: "100",
+		Asset:  "USDC-c76f1f",
+		Extra: map[string]interface{}{
+			"transfers": []interface{}{
+				map[string]interface{}{"asset": "USDC-c76f1f", "amount": "100"},
+				map[string]interface{}{"asset": "TICKET-abcdef", "amount": "1", "tokenNonce": float64(7)},
+			},
+		},
+	}
+
+	fields, err := handler.Encode(req, payTo)
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+
+	expectedData := strings.Join([]string{
+		"MultiESDTNFTTransfer",
+		"8049d639e5a6980d1cd2392abcce41029cda74a1563523a202f09641cc2618f8",
+		"02",
+		hex.EncodeToString([]byte("USDC-c76f1f")), "00", "64",
+		hex.EncodeToString([]byte("TICKET-abcdef")), "07", "01",
+	}, "@")
+	if fields.Data != expectedData {
+		t.Errorf("Encode() data = %s, want %s", fields.Data, expectedData)
+	}
+
+	payload := ExactRelayedPayload{Sender: payTo, Receiver: fields.Receiver, Value: fields.Value, Data: fields.Data}
+	if err := handler.Verify(payload, req); err != nil {
+		t.Fatalf("Expected bundle to verify, got %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		transfers []map[string]interface{}
+		wantErr   bool
+	}{
+		{
+			name: "Order Independent",
+			transfers: []map[string]interface{}{
+				{"asset": "TICKET-abcdef", "amount": "1", "tokenNonce": uint64(7)},
+				{"asset": "USDC-c76f1f", "amount": "100"},
+			},
+		},
+		{
+			name: "Subset Of Bundle",
+			transfers: []map[string]interface{}{
+				{"asset": "USDC-c76f1f", "amount": "50"},
+			},
+		},
+		{
+			name: "Amount Too Low",
+			transfers: []map[string]interface{}{
+				{"asset": "USDC-c76f1f", "amount": "101"},
+				{"asset": "TICKET-abcdef", "amount": "1"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Missing Token",
+			transfers: []map[string]interface{}{
+				{"asset": "USDC-c76f1f", "amount": "100"},
+				{"asset": "WEGLD-bd4d79", "amount": "1"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Wrong Token Nonce",
+			transfers: []map[string]interface{}{
+				{"asset": "TICKET-abcdef", "amount": "1", "tokenNonce": uint64(8)},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Same Transfer Counted Twice",
+			transfers: []map[string]interface{}{
+				{"asset": "USDC-c76f1f", "amount": "100"},
+				{"asset": "USDC-c76f1f", "amount": "100"},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected := req
+			expected.Extra = map[string]interface{}{"transfers": tt.transfers}
+			err := handler.Verify(payload, expected)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// A single-transfer payload does not satisfy a bundle
+	single, _ := handler.Encode(types.PaymentRequirements{PayTo: payTo, Amount: "100", Asset: "USDC-c76f1f"}, payTo)
+	singlePayload := ExactRelayedPayload{Sender: payTo, Receiver: single.Receiver, Value: single.Value, Data: single.Data}
+	if err := handler.Verify(singlePayload, req); err == nil {
+		t.Error("Expected single transfer to fail bundle verification")
+	}
+}
+
+func TestRequiredTransfers_Invalid(t *testing.T) {
+	tests := []struct {
+		name      string
+		transfers interface{}
+	}{
+		{"Not A List", "USDC-c76f1f"},
+		{"Empty List", []interface{}{}},
+		{"Missing Amount", []interface{}{map[string]interface{}{"asset": "USDC-c76f1f"}}},
+		{"Invalid Entry", []interface{}{"USDC-c76f1f"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := types.PaymentRequirements{Extra: map[string]interface{}{"transfers": tt.transfers}}
+			if _, err := RequiredTransfers(req); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
+
+func TestTransferHandlers_MismatchReasons(t *testing.T) {
+	payTo := "erd1spyavw0956vq68xj8y4tenjpq2wd5a9p2c6j8gsz7ztyrnpxrruqzu66jx"
+	other := "erd1qyu5wthldzr8wx5c9ucg8kjagg0jfs53s8nr3zpz3hypefsdd8ssycr6th"
+	direct, _ := GetTransferMethodHandler(TransferMethodDirect)
+	esdt, _ := GetTransferMethodHandler(TransferMethodESDT)
+
+	esdtReq := types.PaymentRequirements{PayTo: payTo, Amount: "100", Asset: "USDC-c76f1f"}
+	fields, err := esdt.Encode(esdtReq, payTo)
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	esdtPayload := ExactRelayedPayload{Sender: payTo, Receiver: fields.Receiver, Value: fields.Value, Data: fields.Data}
+	egldPayload := ExactRelayedPayload{Sender: payTo, Receiver: payTo, Value: "100"}
+
+	tests := []struct {
+		name       string
+		handler    TransferMethodHandler
+		payload    ExactRelayedPayload
+		req        types.PaymentRequirements
+		wantReason string
+	}{
+		{"Direct Receiver", direct, egldPayload, types.PaymentRequirements{PayTo: other, Amount: "100", Asset: NativeTokenTicker}, ErrCodeReceiverMismatch},
+		{"Direct Amount", direct, egldPayload, types.PaymentRequirements{PayTo: payTo, Amount: "101", Asset: NativeTokenTicker}, ErrCodeAmountMismatch},
+		{"ESDT Receiver", esdt, esdtPayload, types.PaymentRequirements{PayTo: other, Amount: "100", Asset: "USDC-c76f1f"}, ErrCodeReceiverMismatch},
+		{"ESDT Amount", esdt, esdtPayload, types.PaymentRequirements{PayTo: payTo, Amount: "101", Asset: "USDC-c76f1f"}, ErrCodeAmountMismatch},
+		{"ESDT Asset", esdt, esdtPayload, types.PaymentRequirements{PayTo: payTo, Amount: "100", Asset: "WEGLD-bd4d79"}, ErrCodeAssetMismatch},
+		{"ESDT Token Nonce", esdt, esdtPayload, types.PaymentRequirements{PayTo: payTo, Amount: "100", Asset: "USDC-c76f1f", Extra: map[string]interface{}{"tokenNonce": uint64(3)}}, ErrCodeTokenNonceMismatch},
+		{"ESDT Malformed Data", esdt, ExactRelayedPayload{Sender: payTo, Receiver: payTo, Data: "ESDTTransfer@00"}, esdtReq, ErrCodeInvalidTransferData},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.handler.Verify(tt.payload, tt.req)
+			var verifyErr *x402.VerifyError
+			if !errors.As(err, &verifyErr) {
+				t.Fatalf("expected VerifyError, got %v", err)
+			}
+			if verifyErr.Reason != tt.wantReason {
+				t.Errorf("reason = %s, want %s", verifyErr.Reason, tt.wantReason)
+			}
+			if verifyErr.Payer != payTo {
+				t.Errorf("payer = %s, want %s", verifyErr.Payer, payTo)
+			}
+		})
+	}
+}
+
+func TestESDTTransferHandler_SCCall(t *testing.T) {
+	payTo := "erd1spyavw0956vq68xj8y4tenjpq2wd5a9p2c6j8gsz7ztyrnpxrruqzu66jx"
+	handler, _ := GetTransferMethodHandler(TransferMethodESDT)
+
+	req := types.PaymentRequirements{
+		PayTo:  payTo,
+		Amount: "100",
+		Asset:  "USDC-c76f1f",
+		Extra: map[string]interface{}{
+			"scFunction": "buyTicket",
+			"arguments":  []interface{}{"2a", "ABCDEF"},
+		},
+	}
+	fields, err := handler.Encode(req, payTo)
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+
+	decoded, err := DecodeMultiESDTTransfer(fields.Data)
+	if err != nil {
+		t.Fatalf("DecodeMultiESDTTransfer failed: %v", err)
+	}
+	if decoded.Function != "buyTicket" {
+		t.Errorf("Function = %q, want buyTicket", decoded.Function)
+	}
+	if strings.Join(decoded.Arguments, ",") != "2a,abcdef" {
+		t.Errorf("Arguments = %v, want [2a abcdef]", decoded.Arguments)
+	}
+	if len(decoded.Transfers) != 1 || decoded.Transfers[0].Asset != "USDC-c76f1f" || decoded.Transfers[0].Amount != "100" {
+		t.Errorf("Transfers = %+v", decoded.Transfers)
+	}
+
+	payload := ExactRelayedPayload{Sender: payTo, Receiver: fields.Receiver, Value: fields.Value, Data: fields.Data}
+	if err := handler.Verify(payload, req); err != nil {
+		t.Fatalf("Expected SC call payload to verify, got %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		extra map[string]interface{}
+	}{
+		{"Wrong Function", map[string]interface{}{"scFunction": "refund", "arguments": []interface{}{"2a", "abcdef"}}},
+		{"Wrong Argument", map[string]interface{}{"scFunction": "buyTicket", "arguments": []interface{}{"2b", "abcdef"}}},
+		{"Missing Argument", map[string]interface{}{"scFunction": "buyTicket", "arguments": []interface{}{"2a"}}},
+		{"Unexpected Call", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected := req
+			expected.Extra = tt.extra
+			err := handler.Verify(payload, expected)
+			var verifyErr *x402.VerifyError
+			if !errors.As(err, &verifyErr) || verifyErr.Reason != ErrCodeSCCallMismatch {
+				t.Errorf("Verify() error = %v, want %s", err, ErrCodeSCCallMismatch)
+			}
+		})
+	}
+}
+
+func TestDecodeMultiESDTTransfer(t *testing.T) {
+	destHex := "8049d639e5a6980d1cd2392abcce41029cda74a1563523a202f09641cc2618f8"
+	usdc := hex.EncodeToString([]byte("USDC-c76f1f"))
+	sft := hex.EncodeToString([]byte("TICKET-a1b2c3"))
+
+	tests := []struct {
+		name      string
+		data      string
+		transfers []TokenTransfer
+		function  string
+		arguments []string
+	}{
+		{
+			name:      "Single Transfer",
+			data:      "MultiESDTNFTTransfer@" + destHex + "@01@" + usdc + "@@64",
+			transfers: []TokenTransfer{{Asset: "USDC-c76f1f", Amount: "100", HasNonce: true}},
+		},
+		{
+			name: "Multiple Transfers",
+			data: "MultiESDTNFTTransfer@" + destHex + "@02@" + usdc + "@00@64@" + sft + "@05@01",
+			transfers: []TokenTransfer{
+				{Asset: "USDC-c76f1f", Amount: "100", HasNonce: true},
+				{Asset: "TICKET-a1b2c3", Amount: "1", Nonce: 5, HasNonce: true},
+			},
+		},
+		{
+			name:      "Trailing Call",
+			data:      "MultiESDTNFTTransfer@" + destHex + "@01@" + usdc + "@00@64@" + hex.EncodeToString([]byte("buy")) + "@2A",
+			transfers: []TokenTransfer{{Asset: "USDC-c76f1f", Amount: "100", HasNonce: true}},
+			function:  "buy",
+			arguments: []string{"2a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoded, err := DecodeMultiESDTTransfer(tt.data)
+			if err != nil {
+				t.Fatalf("DecodeMultiESDTTransfer() error = %v", err)
+			}
+			if hex.EncodeToString(decoded.Destination) != destHex {
+				t.Errorf("Destination = %x, want %s", decoded.Destination, destHex)
+			}
+			if !reflect.DeepEqual(decoded.Transfers, tt.transfers) {
+				t.Errorf("Transfers = %+v, want %+v", decoded.Transfers, tt.transfers)
+			}
+			if decoded.Function != tt.function || !reflect.DeepEqual(decoded.Arguments, tt.arguments) {
+				t.Errorf("call = %q %v, want %q %v", decoded.Function, decoded.Arguments, tt.function, tt.arguments)
+			}
+		})
+	}
+}
+
+func TestDecodeMultiESDTTransfer_Invalid(t *testing.T) {
+	destHex := "8049d639e5a6980d1cd2392abcce41029cda74a1563523a202f09641cc2618f8"
+	token := hex.EncodeToString([]byte("USDC-c76f1f"))
+
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"Wrong Function", "ESDTTransfer@" + token + "@64"},
+		{"Too Few Parts", "MultiESDTNFTTransfer@" + destHex + "@01@" + token},
+		{"Count Exceeds Transfers", "MultiESDTNFTTransfer@" + destHex + "@02@" + token + "@00@64"},
+		{"Zero Count", "MultiESDTNFTTransfer@" + destHex + "@00@" + token + "@00@64"},
+		{"Invalid Destination Hex", "MultiESDTNFTTransfer@zz@01@" + token + "@00@64"},
+		{"Invalid Count Hex", "MultiESDTNFTTransfer@" + destHex + "@zz@" + token + "@00@64"},
+		{"Invalid Token Hex", "MultiESDTNFTTransfer@" + destHex + "@01@zz@00@64"},
+		{"Invalid Nonce Hex", "MultiESDTNFTTransfer@" + destHex + "@01@" + token + "@zz@64"},
+		{"Invalid Amount Hex", "MultiESDTNFTTransfer@" + destHex + "@01@" + token + "@00@zz"},
+		{"Invalid Function Hex", "MultiESDTNFTTransfer@" + destHex + "@01@" + token + "@00@64@zz"},
+		{"Invalid Argument Hex", "MultiESDTNFTTransfer@" + destHex + "@01@" + token + "@00@64@" + hex.EncodeToString([]byte("buy")) + "@xyz"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodeMultiESDTTransfer(tt.data); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
+
+func TestDecodeSCCall(t *testing.T) {
+	function, args, err := DecodeSCCall("buy@01@02")
+	if err != nil {
+		t.Fatalf("DecodeSCCall() error = %v", err)
+	}
+	if function != "buy" || !reflect.DeepEqual(args, [][]byte{{0x01}, {0x02}}) {
+		t.Errorf("DecodeSCCall() = %q, %v; want buy, [[1] [2]]", function, args)
+	}
+
+	function, args, err = DecodeSCCall("claim")
+	if err != nil || function != "claim" || len(args) != 0 {
+		t.Errorf("DecodeSCCall(claim) = %q, %v, %v; want claim without arguments", function, args, err)
+	}
+
+	for _, data := range []string{"", "@01", "buy@zz", "buy@0", "buy now@01"} {
+		if _, _, err := DecodeSCCall(data); err == nil {
+			t.Errorf("DecodeSCCall(%q) succeeded, want an error", data)
+		}
+	}
+}
+
+func TestTransferHandlers_DataFieldPresence(t *testing.T) {
+	payTo := "erd1spyavw0956vq68xj8y4tenjpq2wd5a9p2c6j8gsz7ztyrnpxrruqzu66jx"
+	esdtData := "MultiESDTNFTTransfer@8049d639e5a6980d1cd2392abcce41029cda74a1563523a202f09641cc2618f8@01@" +
+		hex.EncodeToString([]byte("USDC-c76f1f")) + "@00@64"
+
+	plainEGLD := types.PaymentRequirements{PayTo: payTo, Amount: "100", Asset: NativeTokenTicker}
+	scCallEGLD := types.PaymentRequirements{PayTo: payTo, Amount: "100", Asset: NativeTokenTicker,
+		Extra: map[string]interface{}{"scFunction": "buyTicket", "arguments": []interface{}{"2A"}}}
+	memoEGLD := types.PaymentRequirements{PayTo: payTo, Amount: "100", Asset: NativeTokenTicker,
+		Extra: map[string]interface{}{"memo": "order-42"}}
+	expectedMemoEGLD := types.PaymentRequirements{PayTo: payTo, Amount: "100", Asset: NativeTokenTicker,
+		Extra: map[string]interface{}{"expectedMemo": "order-42"}}
+	token := types.PaymentRequirements{PayTo: payTo, Amount: "100", Asset: "USDC-c76f1f"}
+
+	tests := []struct {
+		name   string
+		method string
+		req    types.PaymentRequirements
+		data   string
+		want   string
+	}{
+		{"Direct Plain Empty", TransferMethodDirect, plainEGLD, "", ""},
+		{"Direct Plain With Data", TransferMethodDirect, plainEGLD, "buyTicket", ErrCodeInvalidTransferData},
+		{"Direct Plain Memo", TransferMethodDirect, memoEGLD, "order-42", ""},
+		{"Direct Plain Other Memo", TransferMethodDirect, memoEGLD, "order-43", ErrCodeInvalidTransferData},
+		{"Direct Expected Memo", TransferMethodDirect, expectedMemoEGLD, "order-42", ""},
+		{"Direct Expected Memo Missing", TransferMethodDirect, expectedMemoEGLD, "", ErrCodeMemoMismatch},
+		{"Direct Expected Memo Mismatch", TransferMethodDirect, expectedMemoEGLD, "order-43", ErrCodeMemoMismatch},
+		{"Direct SC Call", TransferMethodDirect, scCallEGLD, "buyTicket@2a", ""},
+		{"Direct SC Call Empty", TransferMethodDirect, scCallEGLD, "", ErrCodeInvalidTransferData},
+		{"Direct SC Call Wrong Function", TransferMethodDirect, scCallEGLD, "refund@2a", ErrCodeSCCallMismatch},
+		{"ESDT With Data", TransferMethodESDT, token, esdtData, ""},
+		{"ESDT Empty", TransferMethodESDT, token, "", ErrCodeInvalidTransferData},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, _ := GetTransferMethodHandler(tt.method)
+			payload := ExactRelayedPayload{Sender: payTo, Receiver: payTo, Value: "100", Data: tt.data}
+			if tt.method == TransferMethodESDT {
+				payload.Value = "0"
+			}
+
+			err := handler.Verify(payload, tt.req)
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("Verify() error = %v", err)
+				}
+				return
+			}
+			var verifyErr *x402.VerifyError
+			if !errors.As(err, &verifyErr) || verifyErr.Reason != tt.want {
+				t.Errorf("Verify() error = %v, want %s", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDirectTransfer_MemoRoundTrip(t *testing.T) {
+	payTo := "erd1spyavw0956vq68xj8y4tenjpq2wd5a9p2c6j8gsz7ztyrnpxrruqzu66jx"
+	memo := "order 42: 2x coffee @ counter"
+	req := types.PaymentRequirements{PayTo: payTo, Amount: "100", Asset: NativeTokenTicker,
+		Extra: map[string]interface{}{"memo": memo, "expectedMemo": memo}}
+
+	handler, _ := GetTransferMethodHandler(TransferMethodDirect)
+	fields, err := handler.Encode(req, payTo)
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if fields.Data != memo {
+		t.Fatalf("Encode() data = %q, want the memo as-is", fields.Data)
+	}
+
+	payload := ExactRelayedPayload{Sender: payTo, Receiver: fields.Receiver, Value: fields.Value, Data: fields.Data}
+	if err := handler.Verify(payload, req); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+
+	req.Extra["expectedMemo"] = "order 43"
+	var vErr *x402.VerifyError
+	if err := handler.Verify(payload, req); !errors.As(err, &vErr) || vErr.Reason != ErrCodeMemoMismatch {
+		t.Errorf("Verify() error = %v, want %s", err, ErrCodeMemoMismatch)
+	}
+}
+
+func TestESDTTransferHandler_EGLDValue(t *testing.T) {
+	payTo := "erd1spyavw0956vq68xj8y4tenjpq2wd5a9p2c6j8gsz7ztyrnpxrruqzu66jx"
+	esdt, _ := GetTransferMethodHandler(TransferMethodESDT)
+	withEGLD := func(egldValue string) types.PaymentRequirements {
+		req := types.PaymentRequirements{PayTo: payTo, Amount: "100", Asset: "USDC-c76f1f",
+			Extra: map[string]interface{}{"scFunction": "buy"}}
+		if egldValue != "" {
+			req.Extra["egldValue"] = egldValue
+		}
+		return req
+	}
+	encode := func(req types.PaymentRequirements) ExactRelayedPayload {
+		fields, err := esdt.Encode(req, payTo)
+		if err != nil {
+			t.Fatalf("Encode() error = %v", err)
+		}
+		return ExactRelayedPayload{Sender: payTo, Receiver: fields.Receiver, Value: fields.Value, Data: fields.Data}
+	}
+
+	combined := encode(withEGLD("5000"))
+	if combined.Value != "0" {
+		t.Errorf("Value = %s, want 0: native EGLD travels in the transfer list", combined.Value)
+	}
+	transfer, err := DecodeMultiESDTTransfer(combined.Data)
+	if err != nil {
+		t.Fatalf("DecodeMultiESDTTransfer() error = %v", err)
+	}
+	if len(transfer.Transfers) != 2 || transfer.Function != "buy" {
+		t.Fatalf("decoded transfer = %+v, want two transfers calling buy", transfer)
+	}
+	if egld := transfer.Transfers[1]; egld.Asset != NativeTokenMultiTransferID || egld.Amount != "5000" {
+		t.Errorf("EGLD transfer = %+v, want %s 5000", egld, NativeTokenMultiTransferID)
+	}
+	if err := esdt.Verify(combined, withEGLD("5000")); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		payload ExactRelayedPayload
+		want    string
+	}{
+		{"Missing EGLD", encode(withEGLD("")), ErrCodeAssetMismatch},
+		{"EGLD Underpaid", encode(withEGLD("4999")), ErrCodeAmountMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var vErr *x402.VerifyError
+			if err := esdt.Verify(tt.payload, withEGLD("5000")); !errors.As(err, &vErr) || vErr.Reason != tt.want {
+				t.Errorf("Verify() error = %v, want %s", err, tt.want)
+			}
+		})
+	}
+
+	if _, err := RequiredTransfers(withEGLD("1.5")); err == nil {
+		t.Error("Expected an invalid egldValue to be rejected")
+	}
+}
+
+func TestESDTTransferHandler_NativeEGLD(t *testing.T) {
+	payTo := "erd1spyavw0956vq68xj8y4tenjpq2wd5a9p2c6j8gsz7ztyrnpxrruqzu66jx"
+	esdt, _ := GetTransferMethodHandler(TransferMethodESDT)
+	egldHex := hex.EncodeToString([]byte(NativeTokenMultiTransferID))
+
+	// EGLD sent through the ESDT method and EGLD-000000 share one encoding, so each verifies against the other
+	assets := []string{NativeTokenTicker, NativeTokenMultiTransferID}
+	for _, encoded := range assets {
+		req := types.PaymentRequirements{PayTo: payTo, Amount: "100", Asset: encoded, Extra: map[string]interface{}{"assetTransferMethod": TransferMethodESDT}}
+		if got := ResolveTransferMethod(req); got != TransferMethodESDT {
+			t.Fatalf("ResolveTransferMethod(%s) = %s, want %s", encoded, got, TransferMethodESDT)
+		}
+		fields, err := esdt.Encode(req, payTo)
+		if err != nil {
+			t.Fatalf("Encode(%s) error = %v", encoded, err)
+		}
+		if fields.Value != "0" || !strings.Contains(fields.Data, "@"+egldHex+"@") {
+			t.Errorf("Encode(%s) = %+v, want value 0 and an %s transfer", encoded, fields, NativeTokenMultiTransferID)
+		}
+
+		payload := ExactRelayedPayload{Sender: payTo, Receiver: fields.Receiver, Value: fields.Value, Data: fields.Data}
+		for _, verified := range assets {
+			verifyReq := req
+			verifyReq.Asset = verified
+			if err := esdt.Verify(payload, verifyReq); err != nil {
+				t.Errorf("Verify(encoded %s, required %s) error = %v", encoded, verified, err)
+			}
+		}
+	}
+
+	// EGLD-000000 is never converted into a value transfer
+	valuePayload := ExactRelayedPayload{Sender: payTo, Receiver: payTo, Value: "100"}
+	req := types.PaymentRequirements{PayTo: payTo, Amount: "100", Asset: NativeTokenMultiTransferID}
+	handler, _ := ResolveTransferMethodHandler(req)
+	var vErr *x402.VerifyError
+	if err := handler.Verify(valuePayload, req); !errors.As(err, &vErr) || vErr.Reason != ErrCodeInvalidTransferData {
+		t.Errorf("Verify() of a value transfer error = %v, want %s", err, ErrCodeInvalidTransferData)
+	}
+}
+
+func TestRegisterTransferMethod_Builtins(t *testing.T) {
+	custom, _ := GetTransferMethodHandler(TransferMethodESDT)
+	for _, name := range []string{TransferMethodDirect, TransferMethodESDT} {
+		if err := RegisterTransferMethod(name, custom); !errors.Is(err, ErrBuiltinTransferMethod) {
+			t.Errorf("RegisterTransferMethod(%q) error = %v, want ErrBuiltinTransferMethod", name, err)
+		}
+		if err := UnregisterTransferMethod(name); !errors.Is(err, ErrBuiltinTransferMethod) {
+			t.Errorf("UnregisterTransferMethod(%q) error = %v, want ErrBuiltinTransferMethod", name, err)
+		}
+	}
+	if handler, _ := GetTransferMethodHandler(TransferMethodDirect); handler == custom {
+		t.Error("built-in direct handler was replaced")
+	}
+
+	if err := RegisterTransferMethod("custom", custom); err != nil {
+		t.Fatalf("RegisterTransferMethod() error = %v", err)
+	}
+	if err := UnregisterTransferMethod("custom"); err != nil {
+		t.Fatalf("UnregisterTransferMethod() error = %v", err)
+	}
+	if _, ok := GetTransferMethodHandler("custom"); ok {
+		t.Error("custom handler still registered after UnregisterTransferMethod")
+	}
+}
@@ -0,0 +1,52 @@
+package multiversx
+
+import "strings"
+
+// TxOutcome is the normalized outcome of a transaction status reported by the proxy or the API
+type TxOutcome int
+
+const (
+	// TxOutcomeUnknown means the status is not one the gateway or the API is known to report
+	TxOutcomeUnknown TxOutcome = iota
+	// TxOutcomePending means the transaction is not final yet
+	TxOutcomePending
+	// TxOutcomeSuccess means the transaction was executed successfully
+	TxOutcomeSuccess
+	// TxOutcomeFailed means the transaction failed, was invalid or was reverted
+	TxOutcomeFailed
+)
+
+// String implements fmt.Stringer
+func (o TxOutcome) String() string {
+	switch o {
+	case TxOutcomePending:
+		return "pending"
+	case TxOutcomeSuccess:
+		return "success"
+	case TxOutcomeFailed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
+
+// txStatusOutcomes maps the statuses of the proxy (node) and of the API to their outcome
+var txStatusOutcomes = map[string]TxOutcome{
+	"received":           TxOutcomePending,
+	"pending":            TxOutcomePending,
+	"partially-executed": TxOutcomePending,
+	"success":            TxOutcomeSuccess,
+	"successful":         TxOutcomeSuccess,
+	"executed":           TxOutcomeSuccess,
+	"fail":               TxOutcomeFailed,
+	"failed":             TxOutcomeFailed,
+	"invalid":            TxOutcomeFailed,
+	"reward-reverted":    TxOutcomeFailed,
+}
+
+// NormalizeTxStatus classifies a raw transaction status, ignoring case and any appended error details
+// (e.g. "fail (error: ...)").
+func NormalizeTxStatus(raw string) TxOutcome {
+	base, _, _ := strings.Cut(strings.TrimSpace(raw), " ")
+	return txStatusOutcomes[strings.ToLower(base)]
+}
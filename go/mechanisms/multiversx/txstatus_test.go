@@ -0,0 +1,42 @@
+package multiversx
+
+import (
+	"testing"
+
+	"github.com/multiversx/mx-chain-core-go/data/transaction"
+)
+
+func TestNormalizeTxStatus(t *testing.T) {
+	tests := []struct {
+		status string
+		want   TxOutcome
+	}{
+		// Proxy (node) statuses
+		{string(transaction.TxStatusPending), TxOutcomePending},
+		{string(transaction.TxStatusSuccess), TxOutcomeSuccess},
+		{string(transaction.TxStatusFail), TxOutcomeFailed},
+		{string(transaction.TxStatusInvalid), TxOutcomeFailed},
+		{string(transaction.TxStatusRewardReverted), TxOutcomeFailed},
+		{"executed", TxOutcomeSuccess},
+		// API statuses
+		{"received", TxOutcomePending},
+		{"partially-executed", TxOutcomePending},
+		{"successful", TxOutcomeSuccess},
+		{"failed", TxOutcomeFailed},
+		// Details, case and whitespace
+		{"fail (error: out of funds)", TxOutcomeFailed},
+		{"Success", TxOutcomeSuccess},
+		{" pending ", TxOutcomePending},
+		// Unknown
+		{"", TxOutcomeUnknown},
+		{"dropped", TxOutcomeUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			if got := NormalizeTxStatus(tt.status); got != tt.want {
+				t.Errorf("NormalizeTxStatus(%q) = %s, want %s", tt.status, got, tt.want)
+			}
+		})
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"math/big"
 
 	"github.com/multiversx/mx-chain-core-go/data/transaction"
+
+	x402 "github.com/coinbase/x402/go"
 )
 
 // SchemeExact is the identifier for the exact payment scheme
@@ -33,6 +35,10 @@ const (
 
 	// NativeTokenTicker is the ticker for the native EGLD token
 	NativeTokenTicker = "EGLD"
+	// NativeTokenDecimals is the number of decimals of the native EGLD token
+	NativeTokenDecimals = 18
+	// NativeTokenMultiTransferID identifies native EGLD within a MultiESDTNFTTransfer, where the transaction value must stay 0
+	NativeTokenMultiTransferID = "EGLD-000000"
 
 	// Transfer Methods
 
@@ -40,8 +46,36 @@ const (
 	TransferMethodESDT = "esdt"
 	// TransferMethodDirect indicates a direct EGLD transfer
 	TransferMethodDirect = "direct"
+
+	// Transaction Options
+
+	// TxOptionGuarded is the options bit marking a transaction co-signed by the sender's guardian
+	TxOptionGuarded = 0x2
+	// TxOptionHashSign is the options bit marking a signature over the transaction hash instead of its bytes
+	TxOptionHashSign = 0x1
+	// TxVersionGuarded is the minimum transaction version supporting guardian fields
+	TxVersionGuarded = 2
+	// TxVersionRelayed is the transaction version of relayed (V3) transactions
+	TxVersionRelayed = 2
+	// TxVersionOptions is the minimum transaction version that may set options bits
+	TxVersionOptions = 2
+	// TxVersionRelayedV1Inner is the transaction version of the inner transactions wrapped in a relayedTxV1
+	TxVersionRelayedV1Inner = 1
+
+	// ExtraRelayVersion is the requirement/supported-kind Extra key naming the relayed transaction version
+	// the facilitator settles with; RelayVersionV1 asks for plain transactions it wraps in a relayedTxV1
+	ExtraRelayVersion = "relayVersion"
+	// RelayVersionV1 is the ExtraRelayVersion value of facilitators relaying with relayedTxV1
+	RelayVersionV1 = 1
 )
 
+// Networks lists the CAIP-2 identifiers of the MultiversX mainnet, devnet and testnet
+var Networks = []x402.Network{
+	"multiversx:" + ChainIDMainnet,
+	"multiversx:" + ChainIDDevnet,
+	"multiversx:" + ChainIDTestnet,
+}
+
 // NetworkConfig holds network-specific configuration
 type NetworkConfig struct {
 	ChainID     string
@@ -61,41 +95,50 @@ type PaymentPayload struct {
 
 // ExactRelayedPayload defines the structure for a transaction that might be relayed
 type ExactRelayedPayload struct {
-	Nonce            uint64 `json:"nonce"`
-	Value            string `json:"value"`
-	Receiver         string `json:"receiver"`
-	Sender           string `json:"sender"`
-	GasPrice         uint64 `json:"gasPrice"`
-	GasLimit         uint64 `json:"gasLimit"`
-	Data             string `json:"data,omitempty"`
-	ChainID          string `json:"chainID"`
-	Version          uint32 `json:"version"`
-	Options          uint32 `json:"options,omitempty"`
-	Signature        string `json:"signature,omitempty"`
-	Relayer          string `json:"relayer,omitempty"`
-	RelayerSignature string `json:"relayerSignature,omitempty"`
-	ValidAfter       uint64 `json:"validAfter,omitempty"`
-	ValidBefore      uint64 `json:"validBefore,omitempty"`
+	Nonce             uint64 `json:"nonce"`
+	Value             string `json:"value"`
+	Receiver          string `json:"receiver"`
+	Sender            string `json:"sender"`
+	GasPrice          uint64 `json:"gasPrice"`
+	GasLimit          uint64 `json:"gasLimit"`
+	Data              string `json:"data,omitempty"`
+	ChainID           string `json:"chainID"`
+	Version           uint32 `json:"version"`
+	Options           uint32 `json:"options,omitempty"`
+	Signature         string `json:"signature,omitempty"`
+	Guardian          string `json:"guardian,omitempty"`
+	GuardianSignature string `json:"guardianSignature,omitempty"`
+	Relayer           string `json:"relayer,omitempty"`
+	RelayerSignature  string `json:"relayerSignature,omitempty"`
+	ValidAfter        uint64 `json:"validAfter,omitempty"`
+	ValidBefore       uint64 `json:"validBefore,omitempty"`
+}
+
+// IsGuarded returns true if the guarded bit is set in the transaction options
+func (p *ExactRelayedPayload) IsGuarded() bool {
+	return p.Options&TxOptionGuarded != 0
 }
 
 // ToMap converts the payload to a map for JSON marshaling
 func (p *ExactRelayedPayload) ToMap() map[string]interface{} {
 	return map[string]interface{}{
-		"nonce":            p.Nonce,
-		"value":            p.Value,
-		"receiver":         p.Receiver,
-		"sender":           p.Sender,
-		"gasPrice":         p.GasPrice,
-		"gasLimit":         p.GasLimit,
-		"data":             p.Data,
-		"chainID":          p.ChainID,
-		"version":          p.Version,
-		"options":          p.Options,
-		"signature":        p.Signature,
-		"relayer":          p.Relayer,
-		"relayerSignature": p.RelayerSignature,
-		"validAfter":       p.ValidAfter,
-		"validBefore":      p.ValidBefore,
+		"nonce":             p.Nonce,
+		"value":             p.Value,
+		"receiver":          p.Receiver,
+		"sender":            p.Sender,
+		"gasPrice":          p.GasPrice,
+		"gasLimit":          p.GasLimit,
+		"data":              p.Data,
+		"chainID":           p.ChainID,
+		"version":           p.Version,
+		"options":           p.Options,
+		"signature":         p.Signature,
+		"guardian":          p.Guardian,
+		"guardianSignature": p.GuardianSignature,
+		"relayer":           p.Relayer,
+		"relayerSignature":  p.RelayerSignature,
+		"validAfter":        p.ValidAfter,
+		"validBefore":       p.ValidBefore,
 	}
 }
 
@@ -157,6 +200,14 @@ func PayloadFromMap(data map[string]interface{}) (*ExactRelayedPayload, error) {
 		p.Signature = val
 	}
 
+	if val, ok := data["guardian"].(string); ok {
+		p.Guardian = val
+	}
+
+	if val, ok := data["guardianSignature"].(string); ok {
+		p.GuardianSignature = val
+	}
+
 	if val, ok := data["relayer"].(string); ok {
 		p.Relayer = val
 	}
@@ -183,19 +234,21 @@ func PayloadFromMap(data map[string]interface{}) (*ExactRelayedPayload, error) {
 // ToTransaction converts the payload to an SDK Transaction struct
 func (p *ExactRelayedPayload) ToTransaction() transaction.FrontendTransaction {
 	return transaction.FrontendTransaction{
-		Nonce:            p.Nonce,
-		Value:            p.Value,
-		Receiver:         p.Receiver,
-		Sender:           p.Sender,
-		GasPrice:         p.GasPrice,
-		GasLimit:         p.GasLimit,
-		Data:             []byte(p.Data),
-		ChainID:          p.ChainID,
-		Version:          p.Version,
-		Options:          p.Options,
-		Signature:        p.Signature,
-		RelayerAddr:      p.Relayer,
-		RelayerSignature: p.RelayerSignature,
+		Nonce:             p.Nonce,
+		Value:             p.Value,
+		Receiver:          p.Receiver,
+		Sender:            p.Sender,
+		GasPrice:          p.GasPrice,
+		GasLimit:          p.GasLimit,
+		Data:              []byte(p.Data),
+		ChainID:           p.ChainID,
+		Version:           p.Version,
+		Options:           p.Options,
+		Signature:         p.Signature,
+		GuardianAddr:      p.Guardian,
+		GuardianSignature: p.GuardianSignature,
+		RelayerAddr:       p.Relayer,
+		RelayerSignature:  p.RelayerSignature,
 	}
 }
 
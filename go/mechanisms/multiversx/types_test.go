@@ -0,0 +1,63 @@
+package multiversx
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestPayloadFromMap_RoundTripsGuardedRelayedPayload(t *testing.T) {
+	payload := ExactRelayedPayload{
+		Nonce:             42,
+		Value:             "1000000000000000000",
+		Receiver:          "erd1qyu5wthldzr8wx5c9ucg8kjagg0jfs53s8nr3zpz3hypefsdd8ssycr6th",
+		Sender:            "erd1spyavw0956vq68xj8y4tenjpq2wd5a9p2c6j8gsz7ztyrnpxrruqzu66jx",
+		GasPrice:          1000000000,
+		GasLimit:          150000,
+		Data:              "pay@01",
+		ChainID:           "D",
+		Version:           2,
+		Options:           TxOptionGuarded,
+		Signature:         "aa",
+		Guardian:          "erd1k2s324ww2g0yj38qn2ch2jwctdy8mnfxep94q9arncc6xecg3xaq6mjse8",
+		GuardianSignature: "bb",
+		Relayer:           "erd1qqqqqqqqqqqqqpgqak8zt22wl2ph4tswtyc39namqx6ysa2sd8ss4xmlj3",
+		RelayerSignature:  "cc",
+		ValidAfter:        1700000000,
+		ValidBefore:       1700000600,
+	}
+
+	t.Run("in memory", func(t *testing.T) {
+		got, err := PayloadFromMap(payload.ToMap())
+		if err != nil {
+			t.Fatalf("PayloadFromMap() error = %v", err)
+		}
+		if *got != payload {
+			t.Errorf("PayloadFromMap(ToMap()) = %+v, want %+v", *got, payload)
+		}
+	})
+
+	// Crossing the HTTP boundary turns every number into a float64
+	t.Run("through JSON", func(t *testing.T) {
+		raw, err := json.Marshal(payload.ToMap())
+		if err != nil {
+			t.Fatalf("json.Marshal() error = %v", err)
+		}
+		var decoded map[string]interface{}
+		if err := json.Unmarshal(raw, &decoded); err != nil {
+			t.Fatalf("json.Unmarshal() error = %v", err)
+		}
+		got, err := PayloadFromMap(decoded)
+		if err != nil {
+			t.Fatalf("PayloadFromMap() error = %v", err)
+		}
+		if *got != payload {
+			t.Errorf("PayloadFromMap() = %+v, want %+v", *got, payload)
+		}
+
+		tx := got.ToTransaction()
+		if tx.GuardianAddr != payload.Guardian || tx.GuardianSignature != payload.GuardianSignature ||
+			tx.RelayerAddr != payload.Relayer || tx.RelayerSignature != payload.RelayerSignature {
+			t.Errorf("ToTransaction() dropped guardian or relayer fields: %+v", tx)
+		}
+	})
+}
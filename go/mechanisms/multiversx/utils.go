@@ -3,22 +3,40 @@ package multiversx
 import (
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
 	"regexp"
 	"strings"
 
 	"github.com/multiversx/mx-chain-core-go/data/transaction"
+	"github.com/multiversx/mx-chain-core-go/hashing/keccak"
 	"github.com/multiversx/mx-sdk-go/data"
 )
 
 var tokenIDRegex = regexp.MustCompile(`^[A-Z0-9]{3,8}-[0-9a-fA-F]{6}$`)
 
+var herotagRegex = regexp.MustCompile(`^(@[a-z0-9]{3,25}(\.elrond)?|[a-z0-9]{3,25}\.elrond)$`)
+
 // IsValidTokenID checks if the token ID follows the MultiversX ESDT format (Ticker-Nonce)
 func IsValidTokenID(tokenID string) bool {
 	return tokenIDRegex.MatchString(tokenID)
 }
 
+// IsNativeAsset reports whether asset is native EGLD, by its ticker or its EGLD-000000 multi-transfer form
+func IsNativeAsset(asset string) bool {
+	return asset == NativeTokenTicker || asset == NativeTokenMultiTransferID
+}
+
+// MultiTransferAssetID returns the identifier of asset within a MultiESDTNFTTransfer
+// Native EGLD can only be sent there as EGLD-000000; ESDT identifiers are returned unchanged.
+func MultiTransferAssetID(asset string) string {
+	if IsNativeAsset(asset) {
+		return NativeTokenMultiTransferID
+	}
+	return asset
+}
+
 // GetMultiversXChainId returns the chain ID for a given network string
 // Supports "multiversx:1", "multiversx:D", "multiversx:T", or legacy short names
 func GetMultiversXChainId(network string) (string, error) {
@@ -46,19 +64,53 @@ func GetMultiversXChainId(network string) (string, error) {
 }
 
 // GetAPIURL returns the MultiversX API URL for a given Chain ID
-func GetAPIURL(chainID string) string {
+// URLs in the optional overrides, keyed by chain ID, take precedence over the public endpoints.
+// Unknown chains fall back to mainnet; use GetAPIURLStrict to reject them instead.
+func GetAPIURL(chainID string, overrides ...map[string]string) string {
+	url, err := GetAPIURLStrict(chainID, overrides...)
+	if err != nil {
+		return "https://api.multiversx.com"
+	}
+	return url
+}
+
+// GetAPIURLStrict returns the MultiversX API URL for a given Chain ID, or an error for an unknown chain
+// so that a mistyped network can never route a payment to mainnet.
+func GetAPIURLStrict(chainID string, overrides ...map[string]string) (string, error) {
+	for _, override := range overrides {
+		if url, ok := override[chainID]; ok && url != "" {
+			return url, nil
+		}
+	}
 	switch chainID {
 	case ChainIDDevnet:
-		return "https://devnet-api.multiversx.com"
+		return "https://devnet-api.multiversx.com", nil
 	case ChainIDTestnet:
-		return "https://testnet-api.multiversx.com"
+		return "https://testnet-api.multiversx.com", nil
 	case ChainIDMainnet:
-		return "https://api.multiversx.com"
+		return "https://api.multiversx.com", nil
 	default:
-		return "https://api.multiversx.com"
+		return "", fmt.Errorf("unknown chain ID %q: no API URL configured", chainID)
 	}
 }
 
+// GetExplorerURL returns the explorer URL for a given chain ID
+func GetExplorerURL(chainID string) string {
+	switch chainID {
+	case ChainIDDevnet:
+		return "https://devnet-explorer.multiversx.com"
+	case ChainIDTestnet:
+		return "https://testnet-explorer.multiversx.com"
+	default:
+		return "https://explorer.multiversx.com"
+	}
+}
+
+// ExplorerTransactionURL returns the explorer page of the transaction
+func ExplorerTransactionURL(explorerURL string, txHash string) string {
+	return strings.TrimRight(explorerURL, "/") + "/transactions/" + txHash
+}
+
 // IsValidAddress checks if the address is a valid MultiversX Bech32 address
 func IsValidAddress(address string) bool {
 	if len(address) != 62 {
@@ -69,6 +121,20 @@ func IsValidAddress(address string) bool {
 	return err == nil
 }
 
+// IsHerotag checks if the string is a MultiversX herotag ("@alice" or "alice.elrond") rather than an address
+func IsHerotag(s string) bool {
+	return herotagRegex.MatchString(s)
+}
+
+// HerotagUsername returns the on-chain username of a herotag, e.g. "alice.elrond" for "@alice"
+func HerotagUsername(herotag string) string {
+	name := strings.TrimPrefix(herotag, "@")
+	if !strings.HasSuffix(name, ".elrond") {
+		name += ".elrond"
+	}
+	return name
+}
+
 // IsValidHex checks if string is valid hex
 func IsValidHex(s string) bool {
 	_, err := hex.DecodeString(s)
@@ -81,7 +147,12 @@ func BytesToHex(b []byte) string {
 }
 
 // CheckAmount verifies decimal amount string
+// The amount must be a non-negative integer of atomic units written with digits only.
 func CheckAmount(amount string) (*big.Int, error) {
+	if err := validateAtomicAmount(amount); err != nil {
+		return nil, err
+	}
+
 	i, ok := new(big.Int).SetString(amount, 10)
 	if !ok {
 		return nil, fmt.Errorf("invalid amount: %s", amount)
@@ -90,6 +161,113 @@ func CheckAmount(amount string) (*big.Int, error) {
 	return i, nil
 }
 
+// validateAtomicAmount rejects amounts that are not plain digit strings, explaining why
+func validateAtomicAmount(amount string) error {
+	switch {
+	case amount == "":
+		return errors.New("invalid amount: empty")
+	case strings.HasPrefix(amount, "-") || strings.HasPrefix(amount, "+"):
+		return fmt.Errorf("invalid amount %q: must be an unsigned integer", amount)
+	case strings.Contains(amount, "."):
+		return fmt.Errorf("invalid amount %q: must be an integer number of atomic units, not a decimal", amount)
+	case strings.ContainsAny(amount, "eE"):
+		return fmt.Errorf("invalid amount %q: scientific notation is not allowed", amount)
+	}
+	for _, c := range amount {
+		if c < '0' || c > '9' {
+			return fmt.Errorf("invalid amount %q: unexpected character %q", amount, c)
+		}
+	}
+	return nil
+}
+
+// ParseDecimalAmount converts a decimal amount string (e.g. "1.50") into atomic units for the given decimals
+// Digits beyond the token precision are truncated.
+func ParseDecimalAmount(amount string, decimals int) (*big.Int, error) {
+	if decimals < 0 {
+		return nil, fmt.Errorf("invalid decimals: %d", decimals)
+	}
+
+	intStr, decStr, hasDot := strings.Cut(strings.TrimSpace(amount), ".")
+	if intStr == "" && hasDot {
+		intStr = "0"
+	}
+
+	intPart, ok := new(big.Int).SetString(intStr, 10)
+	if !ok || intPart.Sign() < 0 {
+		return nil, fmt.Errorf("invalid amount: %s", amount)
+	}
+
+	if len(decStr) > decimals {
+		decStr = decStr[:decimals]
+	} else {
+		decStr += strings.Repeat("0", decimals-len(decStr))
+	}
+
+	decPart := new(big.Int)
+	if decStr != "" {
+		if _, ok := decPart.SetString(decStr, 10); !ok || decPart.Sign() < 0 {
+			return nil, fmt.Errorf("invalid amount: %s", amount)
+		}
+	}
+
+	multiplier := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	return intPart.Mul(intPart, multiplier).Add(intPart, decPart), nil
+}
+
+// FormatAmount converts an atomic amount (e.g. "1500000000000000000") into its decimal display form ("1.5")
+// Trailing fractional zeros are trimmed, so whole amounts have no decimal point.
+func FormatAmount(atomic string, decimals int) (string, error) {
+	if decimals < 0 {
+		return "", fmt.Errorf("invalid decimals: %d", decimals)
+	}
+	value, err := CheckAmount(atomic)
+	if err != nil {
+		return "", err
+	}
+
+	digits := value.String()
+	if len(digits) <= decimals {
+		digits = strings.Repeat("0", decimals-len(digits)+1) + digits
+	}
+	intPart, fracPart := digits[:len(digits)-decimals], strings.TrimRight(digits[len(digits)-decimals:], "0")
+	if fracPart == "" {
+		return intPart, nil
+	}
+	return intPart + "." + fracPart, nil
+}
+
+// ParseAmount converts a decimal display amount (e.g. "1.5") into its atomic string for the given decimals
+// Unlike ParseDecimalAmount it rejects amounts more precise than the token instead of truncating them.
+func ParseAmount(human string, decimals int) (string, error) {
+	if decimals < 0 {
+		return "", fmt.Errorf("invalid decimals: %d", decimals)
+	}
+
+	intStr, fracStr, _ := strings.Cut(human, ".")
+	if intStr == "" && fracStr == "" {
+		return "", fmt.Errorf("invalid amount %q: no digits", human)
+	}
+	for _, part := range []string{intStr, fracStr} {
+		for _, c := range part {
+			if c < '0' || c > '9' {
+				return "", fmt.Errorf("invalid amount %q: unexpected character %q", human, c)
+			}
+		}
+	}
+
+	fracStr = strings.TrimRight(fracStr, "0")
+	if len(fracStr) > decimals {
+		return "", fmt.Errorf("invalid amount %q: more than %d decimals", human, decimals)
+	}
+
+	value, ok := new(big.Int).SetString("0"+intStr+fracStr+strings.Repeat("0", decimals-len(fracStr)), 10)
+	if !ok {
+		return "", fmt.Errorf("invalid amount: %s", human)
+	}
+	return value.String(), nil
+}
+
 // CalculateGasLimit estimates the gas limit for a transaction
 func CalculateGasLimit(data []byte, numTransfers int) uint64 {
 	const BaseCost = 50000
@@ -103,7 +281,41 @@ func CalculateGasLimit(data []byte, numTransfers int) uint64 {
 		RelayedCost
 }
 
+// SigningBytes returns the canonical bytes the sender, guardian and relayer of the payload sign
+// Signing and verification both go through it; external signers can use it to sign payloads themselves.
+func SigningBytes(payload ExactRelayedPayload) ([]byte, error) {
+	tx := payload.ToTransaction()
+	return transactionSigningBytes(&tx)
+}
+
+// transactionSigningBytes returns the canonical JSON of the transaction, or its keccak-256 hash when a
+// version 2+ transaction sets the hash signing option, as the node's GetDataForSigning does
+func transactionSigningBytes(tx *transaction.FrontendTransaction) ([]byte, error) {
+	msgBytes, err := SerializeTransaction(tx)
+	if err != nil {
+		return nil, err
+	}
+	if tx.Version >= TxVersionOptions && tx.Options&TxOptionHashSign != 0 {
+		return keccak.NewKeccak().Compute(string(msgBytes)), nil
+	}
+	return msgBytes, nil
+}
+
 // SerializeTransaction serializes a transaction to its canonical JSON format for signing
+// It matches the node's GetDataForSigning: the data field is emitted as base64 of the raw
+// bytes, signatures are never part of the signed message and the guardian address is only
+// included when the guarded bit is set in the transaction options. Fields follow the node
+// order (nonce, value, receiver, sender, gasPrice, gasLimit, data, chainID, version, options,
+// guardian, relayer) since the node's own FrontendTransaction struct is marshalled, not a map.
 func SerializeTransaction(tx *transaction.FrontendTransaction) ([]byte, error) {
-	return json.Marshal(tx)
+	unsignedTx := *tx
+	unsignedTx.Signature = ""
+	unsignedTx.GuardianSignature = ""
+	unsignedTx.RelayerSignature = ""
+
+	if unsignedTx.Options&TxOptionGuarded == 0 {
+		unsignedTx.GuardianAddr = ""
+	}
+
+	return json.Marshal(&unsignedTx)
 }
@@ -1,7 +1,13 @@
 package multiversx
 
 import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"strings"
 	"testing"
+
+	"github.com/multiversx/mx-chain-core-go/data/transaction"
+	"github.com/multiversx/mx-sdk-go/data"
 )
 
 func TestGetMultiversXChainId(t *testing.T) {
@@ -36,6 +42,45 @@ func TestGetMultiversXChainId(t *testing.T) {
 	}
 }
 
+func TestGetAPIURLStrict(t *testing.T) {
+	overrides := map[string]string{"D": "http://localhost:7950", "localnet": "http://localhost:7951"}
+	tests := []struct {
+		chainID   string
+		overrides map[string]string
+		expected  string
+		hasError  bool
+	}{
+		{"1", nil, "https://api.multiversx.com", false},
+		{"D", nil, "https://devnet-api.multiversx.com", false},
+		{"T", nil, "https://testnet-api.multiversx.com", false},
+		{"D", overrides, "http://localhost:7950", false},
+		{"localnet", overrides, "http://localhost:7951", false},
+		{"d", nil, "", true},
+		{"", nil, "", true},
+		{"localnet", nil, "", true},
+	}
+
+	for _, tc := range tests {
+		res, err := GetAPIURLStrict(tc.chainID, tc.overrides)
+		if tc.hasError {
+			if err == nil {
+				t.Errorf("Expected error for %q, got %s", tc.chainID, res)
+			}
+			// The lenient variant keeps falling back to mainnet
+			if lenient := GetAPIURL(tc.chainID, tc.overrides); lenient != "https://api.multiversx.com" {
+				t.Errorf("GetAPIURL(%q) = %s, want the mainnet fallback", tc.chainID, lenient)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Unexpected error for %q: %v", tc.chainID, err)
+		}
+		if res != tc.expected {
+			t.Errorf("Expected %s, got %s", tc.expected, res)
+		}
+	}
+}
+
 func TestIsValidAddress(t *testing.T) {
 	tests := []struct {
 		addr  string
@@ -74,6 +119,28 @@ func TestCheckAmount(t *testing.T) {
 	if err == nil {
 		t.Errorf("Invalid string passed")
 	}
+
+	tests := []struct {
+		amount  string
+		wantErr string
+	}{
+		{"1.5", "not a decimal"},
+		{"-100", "unsigned integer"},
+		{"+100", "unsigned integer"},
+		{"1e3", "scientific notation"},
+		{"", "empty"},
+		{"1 000", "unexpected character"},
+	}
+	for _, tt := range tests {
+		if _, err := CheckAmount(tt.amount); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("CheckAmount(%q) error = %v, want %q", tt.amount, err, tt.wantErr)
+		}
+	}
+
+	amount, err := CheckAmount("1000000000000000000")
+	if err != nil || amount.String() != "1000000000000000000" {
+		t.Errorf("CheckAmount(1 EGLD) = %v, %v", amount, err)
+	}
 }
 
 func TestIsValidTokenID(t *testing.T) {
@@ -149,3 +216,199 @@ func TestCalculateGasLimit(t *testing.T) {
 		})
 	}
 }
+
+func TestSerializeTransaction_MatchesNodeFormat(t *testing.T) {
+	// Known-good signed transaction from the MultiversX signing specification
+	tx := transaction.FrontendTransaction{
+		Nonce:     0,
+		Value:     "0",
+		Receiver:  "erd1cux02zersde0l7hhklzhywcxk4u9n4py5tdxyx7vrvhnza2r4gmq4vw35r",
+		Sender:    "erd1l453hd0gt5gzdp7czpuall8ggt2dcv5zwmfdf3sd3lguxseux2fsmsgldz",
+		GasPrice:  1000000000,
+		GasLimit:  50000,
+		Data:      []byte("foo"),
+		ChainID:   "1",
+		Version:   1,
+		Signature: "b5fddb8c16fa7f6123cb32edc854f1e760a3eb62c6dc420b5a4c0473c58befd45b621b31a448c5b59e21428f2bc128c80d0ee1caa4f2bf05a12be857ad451b00",
+	}
+
+	expected := `{"nonce":0,"value":"0","receiver":"erd1cux02zersde0l7hhklzhywcxk4u9n4py5tdxyx7vrvhnza2r4gmq4vw35r","sender":"erd1l453hd0gt5gzdp7czpuall8ggt2dcv5zwmfdf3sd3lguxseux2fsmsgldz","gasPrice":1000000000,"gasLimit":50000,"data":"Zm9v","chainID":"1","version":1}`
+
+	msg, err := SerializeTransaction(&tx)
+	if err != nil {
+		t.Fatalf("SerializeTransaction failed: %v", err)
+	}
+	if string(msg) != expected {
+		t.Fatalf("Canonical serialization mismatch:\n got: %s\nwant: %s", msg, expected)
+	}
+
+	addr, err := data.NewAddressFromBech32String(tx.Sender)
+	if err != nil {
+		t.Fatalf("Failed to decode sender: %v", err)
+	}
+	sig, _ := hex.DecodeString(tx.Signature)
+	if !ed25519.Verify(addr.AddressBytes(), msg, sig) {
+		t.Fatal("Known-good signature does not verify against the canonical serialization")
+	}
+}
+
+func TestSerializeTransaction_FieldOrder(t *testing.T) {
+	// The node signs fields in declaration order, never alphabetically: pin the order of the optional fields too
+	tx := transaction.FrontendTransaction{
+		Nonce:             7,
+		Value:             "1000",
+		Receiver:          "erd1cux02zersde0l7hhklzhywcxk4u9n4py5tdxyx7vrvhnza2r4gmq4vw35r",
+		Sender:            "erd1l453hd0gt5gzdp7czpuall8ggt2dcv5zwmfdf3sd3lguxseux2fsmsgldz",
+		GasPrice:          1000000000,
+		GasLimit:          100000,
+		Data:              []byte("buy@01"),
+		ChainID:           "D",
+		Version:           2,
+		Options:           TxOptionGuarded,
+		GuardianAddr:      "erd1qyu5wthldzr8wx5c9ucg8kjagg0jfs53s8nr3zpz3hypefsdd8ssycr6th",
+		GuardianSignature: "aa",
+		RelayerAddr:       "erd1spyavw0956vq68xj8y4tenjpq2wd5a9p2c6j8gsz7ztyrnpxrruqzu66jx",
+		RelayerSignature:  "bb",
+		Signature:         "cc",
+	}
+
+	expected := `{"nonce":7,"value":"1000","receiver":"erd1cux02zersde0l7hhklzhywcxk4u9n4py5tdxyx7vrvhnza2r4gmq4vw35r",` +
+		`"sender":"erd1l453hd0gt5gzdp7czpuall8ggt2dcv5zwmfdf3sd3lguxseux2fsmsgldz","gasPrice":1000000000,"gasLimit":100000,` +
+		`"data":"YnV5QDAx","chainID":"D","version":2,"options":2,` +
+		`"guardian":"erd1qyu5wthldzr8wx5c9ucg8kjagg0jfs53s8nr3zpz3hypefsdd8ssycr6th",` +
+		`"relayer":"erd1spyavw0956vq68xj8y4tenjpq2wd5a9p2c6j8gsz7ztyrnpxrruqzu66jx"}`
+
+	msg, err := SerializeTransaction(&tx)
+	if err != nil {
+		t.Fatalf("SerializeTransaction failed: %v", err)
+	}
+	if string(msg) != expected {
+		t.Fatalf("Canonical serialization mismatch:\n got: %s\nwant: %s", msg, expected)
+	}
+}
+
+func TestParseDecimalAmount(t *testing.T) {
+	tests := []struct {
+		amount   string
+		decimals int
+		want     string
+		wantErr  bool
+	}{
+		{"1", 6, "1000000", false},
+		{"1.5", 18, "1500000000000000000", false},
+		{".5", 2, "50", false},
+		{"0.1234567", 6, "123456", false},
+		{"12", 0, "12", false},
+		{"abc", 6, "", true},
+		{"-1", 6, "", true},
+		{"1", -1, "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseDecimalAmount(tt.amount, tt.decimals)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseDecimalAmount(%q, %d) error = %v, wantErr %v", tt.amount, tt.decimals, err, tt.wantErr)
+			continue
+		}
+		if err == nil && got.String() != tt.want {
+			t.Errorf("ParseDecimalAmount(%q, %d) = %s, want %s", tt.amount, tt.decimals, got, tt.want)
+		}
+	}
+}
+
+func TestFormatAmount(t *testing.T) {
+	tests := []struct {
+		atomic   string
+		decimals int
+		want     string
+		wantErr  bool
+	}{
+		// EGLD, 18 decimals
+		{"1500000000000000000", 18, "1.5", false},
+		{"1000000000000000000", 18, "1", false},
+		{"1", 18, "0.000000000000000001", false},
+		{"0", 18, "0", false},
+		{"123456789012345678901234567890", 18, "123456789012.34567890123456789", false},
+		// USDC, 6 decimals
+		{"1500000", 6, "1.5", false},
+		{"10000", 6, "0.01", false},
+		{"25000000", 6, "25", false},
+		{"12", 0, "12", false},
+		{"1.5", 6, "", true},
+		{"-1", 6, "", true},
+		{"1", -1, "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := FormatAmount(tt.atomic, tt.decimals)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("FormatAmount(%q, %d) error = %v, wantErr %v", tt.atomic, tt.decimals, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("FormatAmount(%q, %d) = %q, want %q", tt.atomic, tt.decimals, got, tt.want)
+		}
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		human    string
+		decimals int
+		want     string
+		wantErr  bool
+	}{
+		// EGLD, 18 decimals
+		{"1.5", 18, "1500000000000000000", false},
+		{"0.000000000000000001", 18, "1", false},
+		{"1.500000000000000000000", 18, "1500000000000000000", false},
+		{"123456789012.34567890123456789", 18, "123456789012345678901234567890", false},
+		// USDC, 6 decimals
+		{"1.5", 6, "1500000", false},
+		{".01", 6, "10000", false},
+		{"25.", 6, "25000000", false},
+		{"0", 6, "0", false},
+		{"0.0000001", 6, "", true},
+		{"1e3", 6, "", true},
+		{"-1", 6, "", true},
+		{"1,5", 6, "", true},
+		{".", 6, "", true},
+		{"", 6, "", true},
+		{"1", -1, "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseAmount(tt.human, tt.decimals)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseAmount(%q, %d) error = %v, wantErr %v", tt.human, tt.decimals, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseAmount(%q, %d) = %q, want %q", tt.human, tt.decimals, got, tt.want)
+		}
+	}
+}
+
+func TestIsHerotag(t *testing.T) {
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{"@alice", true},
+		{"alice.elrond", true},
+		{"@alice.elrond", true},
+		{"alice", false},
+		{"@al", false},
+		{"@Alice", false},
+		{"erd1spyavw0956vq68xj8y4tenjpq2wd5a9p2c6j8gsz7ztyrnpxrruqzu66jx", false},
+	}
+	for _, tt := range tests {
+		if got := IsHerotag(tt.value); got != tt.want {
+			t.Errorf("IsHerotag(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+
+	if got := HerotagUsername("@alice"); got != "alice.elrond" {
+		t.Errorf("HerotagUsername(@alice) = %s", got)
+	}
+}
@@ -4,8 +4,8 @@ import (
 	"context"
 	"crypto/ed25519"
 	"encoding/hex"
+	"errors"
 	"fmt"
-	"strings"
 
 	"github.com/multiversx/mx-sdk-go/data"
 
@@ -15,22 +15,55 @@ import (
 
 // VerifyPayment performs strict verification of the payment payload against requirements
 // It checks signature validity, expiration, and payload content matching
-func VerifyPayment(ctx context.Context, payload ExactRelayedPayload, requirements types.PaymentRequirements, simulator func(ExactRelayedPayload) (string, error)) (bool, error) {
+func VerifyPayment(ctx context.Context, payload ExactRelayedPayload, requirements types.PaymentRequirements, simulator func(context.Context, ExactRelayedPayload) (string, error)) (bool, error) {
+	// 1. Offline Checks (static checks and local signature verification)
+	if valid, err := VerifyPaymentOffline(ctx, payload, requirements); !valid || err != nil {
+		return valid, err
+	}
+
+	// 2. Verification via Simulation
+	// We simulation ALL transactions to ensure validity (Smart Contract Wallets, balances, nonces)
+	hash, err := simulator(ctx, payload)
+	if err != nil {
+		// If simulation fails, it's definitely invalid
+		// Simulators may already return typed errors, otherwise map known node messages
+		var vErr *x402.VerifyError
+		if errors.As(err, &vErr) {
+			return false, vErr
+		}
+		if reason, ok := MapNodeError(err.Error(), DefaultNodeErrorMappings); ok {
+			return false, x402.NewVerifyError(reason, payload.Sender, "multiversx", err)
+		}
+		return false, x402.NewVerifyError("simulation_failed", payload.Sender, "multiversx", err)
+	}
+
+	if hash == "" {
+		return false, x402.NewVerifyError("simulation_returned_empty_hash", payload.Sender, "multiversx", nil)
+	}
+
+	return true, nil
+}
+
+// VerifyPaymentOffline performs the static checks and strict local Ed25519 verification of the payload,
+// then checks its transfer against the requirements receiver, asset and amount with VerifyTransfer.
+// It never calls out to the network, so it can be used in air-gapped environments and in tests.
+func VerifyPaymentOffline(ctx context.Context, payload ExactRelayedPayload, requirements types.PaymentRequirements) (bool, error) {
 	// 1. Static Checks
+	if err := ValidatePayloadConsistency(payload, requirements); err != nil {
+		return false, err
+	}
 
-	// 2. Signature Presence
-	if payload.Signature == "" {
-		return false, x402.NewVerifyError(x402.ErrCodeSignatureInvalid, payload.Sender, "multiversx", fmt.Errorf("missing signature"))
+	if err := ValidateValueFormat(payload); err != nil {
+		return false, err
 	}
 
-	// 3. Local Ed25519 Verification
-	tx := payload.ToTransaction()
-	// Clear signatures for verification as they were not part of the signed message
-	tx.Signature = ""
-	tx.RelayerSignature = ""
+	// 2. Signature Presence and Format
+	if err := ValidateSignatureFormat(payload); err != nil {
+		return false, err
+	}
 
-	// Serialize as canonical JSON for verification
-	msgBytes, err := SerializeTransaction(&tx)
+	// 3. Local Ed25519 Verification
+	msgBytes, err := SigningBytes(payload)
 	if err != nil {
 		return false, x402.NewVerifyError("serialization_failed", payload.Sender, "multiversx", err)
 	}
@@ -39,18 +72,11 @@ func VerifyPayment(ctx context.Context, payload ExactRelayedPayload, requirement
 	// Decode Sender Bech32 -> PubKey
 	addr, err := data.NewAddressFromBech32String(payload.Sender)
 	if err != nil {
-		return false, x402.NewVerifyError("invalid_sender_address", payload.Sender, "multiversx", err)
+		return false, x402.NewVerifyError(ErrCodeInvalidSender, payload.Sender, "multiversx", err)
 	}
 	pubKeyBytes := addr.AddressBytes()
 
-	sigBytes, err := hex.DecodeString(payload.Signature)
-	if err != nil {
-		return false, x402.NewVerifyError("invalid_signature_hex", payload.Sender, "multiversx", err)
-	}
-
-	if len(sigBytes) != 64 {
-		return false, x402.NewVerifyError("invalid_signature_length", payload.Sender, "multiversx", fmt.Errorf("expected 64 bytes, got %d", len(sigBytes)))
-	}
+	sigBytes, _ := hex.DecodeString(payload.Signature)
 
 	if len(pubKeyBytes) != 32 {
 		return false, x402.NewVerifyError("invalid_public_key_length", payload.Sender, "multiversx", fmt.Errorf("expected 32 bytes, got %d", len(pubKeyBytes)))
@@ -60,20 +86,165 @@ func VerifyPayment(ctx context.Context, payload ExactRelayedPayload, requirement
 		return false, x402.NewVerifyError(x402.ErrCodeSignatureInvalid, payload.Sender, "multiversx", nil)
 	}
 
-	// 4. Verification via Simulation
-	// We simulation ALL transactions to ensure validity (Smart Contract Wallets, balances, nonces)
-	hash, err := simulator(payload)
+	// C. Verify Guardian Co-Signature
+	if payload.IsGuarded() {
+		if payload.Version < TxVersionGuarded {
+			return false, x402.NewVerifyError("invalid_guarded_version", payload.Sender, "multiversx", fmt.Errorf("guarded transactions require version %d or higher, got %d", TxVersionGuarded, payload.Version))
+		}
+		if payload.Guardian == "" || payload.GuardianSignature == "" {
+			return false, x402.NewVerifyError(ErrCodeMissingGuardianSignature, payload.Sender, "multiversx", fmt.Errorf("guarded transaction requires guardian and guardianSignature"))
+		}
+		if err := verifyEd25519Signature(payload.Guardian, payload.GuardianSignature, msgBytes); err != nil {
+			return false, x402.NewVerifyError(ErrCodeInvalidGuardianSignature, payload.Sender, "multiversx", fmt.Errorf("guardian signature: %w", err))
+		}
+	}
+
+	// 4. Transfer: a well signed payload must still pay what the requirements ask for
+	if err := VerifyTransfer(payload, requirements); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// VerifyTransfer checks the payload transfer against the requirements with the resolved transfer method
+// Custom handlers may return plain errors; those are reported as invalid payments.
+func VerifyTransfer(payload ExactRelayedPayload, requirements types.PaymentRequirements) error {
+	handler, err := ResolveTransferMethodHandler(requirements)
 	if err != nil {
-		// If simulation fails, it's definitely invalid
-		// Check error string for signature?
-		if strings.Contains(err.Error(), "invalid signature") || strings.Contains(err.Error(), "verification failed") {
-			return false, x402.NewVerifyError(x402.ErrCodeSignatureInvalid, payload.Sender, "multiversx", err)
+		return x402.NewVerifyError(ErrCodeInvalidRequirements, payload.Sender, "multiversx", err)
+	}
+
+	if err := handler.Verify(payload, requirements); err != nil {
+		var verifyErr *x402.VerifyError
+		if errors.As(err, &verifyErr) {
+			return err
 		}
-		return false, x402.NewVerifyError("simulation_failed", payload.Sender, "multiversx", err)
+		return x402.NewVerifyError(x402.ErrCodeInvalidPayment, payload.Sender, "multiversx", err)
 	}
+	return nil
+}
 
-	if hash == "" {
-		return false, x402.NewVerifyError("simulation_returned_empty_hash", payload.Sender, "multiversx", nil)
+// ValidateSignatureFormat checks that the sender signature is present and is 64 bytes of hex
+// It is cheap enough to run before any network call.
+func ValidateSignatureFormat(payload ExactRelayedPayload) error {
+	if payload.Signature == "" {
+		return x402.NewVerifyError(x402.ErrCodeSignatureInvalid, payload.Sender, "multiversx", fmt.Errorf("missing signature"))
+	}
+
+	sigBytes, err := hex.DecodeString(payload.Signature)
+	if err != nil {
+		return x402.NewVerifyError("invalid_signature_hex", payload.Sender, "multiversx", err)
+	}
+	if len(sigBytes) != ed25519.SignatureSize {
+		return x402.NewVerifyError("invalid_signature_length", payload.Sender, "multiversx", fmt.Errorf("expected %d bytes, got %d", ed25519.SignatureSize, len(sigBytes)))
+	}
+	return nil
+}
+
+// ValidateValueFormat checks that the transaction value is a canonical base-10 integer
+// The node only accepts plain decimal digits; hex prefixes, signs and leading zeros are
+// rejected rather than being parsed differently by the facilitator and the node.
+func ValidateValueFormat(payload ExactRelayedPayload) error {
+	value := payload.Value
+	if value == "" {
+		return x402.NewVerifyError(ErrCodeInvalidValueFormat, payload.Sender, "multiversx", errors.New("missing value"))
+	}
+	for _, c := range value {
+		if c < '0' || c > '9' {
+			return x402.NewVerifyError(ErrCodeInvalidValueFormat, payload.Sender, "multiversx", fmt.Errorf("value %q is not a base-10 integer", value))
+		}
+	}
+	if len(value) > 1 && value[0] == '0' {
+		return x402.NewVerifyError(ErrCodeInvalidValueFormat, payload.Sender, "multiversx", fmt.Errorf("value %q has leading zeros", value))
+	}
+	return nil
+}
+
+// ValidatePayloadConsistency checks that the transaction version and options fit the transfer
+// A transfer is relayed when the payload or the requirements name a relayer: it then needs the
+// relayer field and version 2, and must not be a direct transfer. Options bits need version 2,
+// the guarded bit and the guardian field go together and unknown options bits are rejected.
+func ValidatePayloadConsistency(payload ExactRelayedPayload, requirements types.PaymentRequirements) error {
+	inconsistent := func(format string, args ...interface{}) error {
+		return x402.NewVerifyError(ErrCodeInconsistentTransaction, payload.Sender, "multiversx", fmt.Errorf(format, args...))
+	}
+
+	if payload.Version == 0 {
+		return inconsistent("transaction version is required")
+	}
+
+	transferMethod, _ := ExtraString(requirements.Extra, "assetTransferMethod")
+	requiredRelayer, _ := ExtraString(requirements.Extra, "relayer")
+	relayed := payload.Relayer != "" || (requiredRelayer != "" && transferMethod != TransferMethodDirect)
+	if relayed {
+		if transferMethod == TransferMethodDirect {
+			return inconsistent("direct transfers must not set a relayer, got %s", payload.Relayer)
+		}
+		if payload.Relayer == "" {
+			return inconsistent("relayed transfers require the relayer %s", requiredRelayer)
+		}
+		if payload.Version != TxVersionRelayed {
+			return inconsistent("relayed transfers require version %d, got %d", TxVersionRelayed, payload.Version)
+		}
+	}
+
+	if unknown := payload.Options &^ (TxOptionHashSign | TxOptionGuarded); unknown != 0 {
+		return inconsistent("unknown options bits %#x", unknown)
+	}
+	if payload.Options != 0 && payload.Version < TxVersionOptions {
+		return inconsistent("options %#x require version %d or higher, got %d", payload.Options, TxVersionOptions, payload.Version)
+	}
+
+	if payload.IsGuarded() && payload.Guardian == "" {
+		return inconsistent("guarded option set without a guardian")
+	}
+	if !payload.IsGuarded() && (payload.Guardian != "" || payload.GuardianSignature != "") {
+		return inconsistent("guardian set without the guarded option")
+	}
+
+	return nil
+}
+
+// verifyEd25519Signature checks a hex encoded signature of msg against the public key of a Bech32 address
+func verifyEd25519Signature(address string, signatureHex string, msg []byte) error {
+	addr, err := data.NewAddressFromBech32String(address)
+	if err != nil {
+		return fmt.Errorf("invalid address %s: %w", address, err)
+	}
+
+	sigBytes, err := hex.DecodeString(signatureHex)
+	if err != nil {
+		return fmt.Errorf("invalid signature hex: %w", err)
+	}
+	if len(sigBytes) != ed25519.SignatureSize {
+		return fmt.Errorf("expected %d bytes signature, got %d", ed25519.SignatureSize, len(sigBytes))
+	}
+
+	if !ed25519.Verify(addr.AddressBytes(), msg, sigBytes) {
+		return fmt.Errorf("signature does not match %s", address)
+	}
+	return nil
+}
+
+// VerifyRelayerSignature verifies the relayer signature of a relayed (V3) payload locally
+// The relayer signs the same canonical serialization as the sender, so a relay prepared
+// by another facilitator can be checked without re-signing it.
+func VerifyRelayerSignature(payload ExactRelayedPayload) (bool, error) {
+	if payload.Relayer == "" {
+		return false, x402.NewVerifyError("missing_relayer", payload.Sender, "multiversx", fmt.Errorf("payload has no relayer"))
+	}
+	if payload.RelayerSignature == "" {
+		return false, x402.NewVerifyError("missing_relayer_signature", payload.Sender, "multiversx", fmt.Errorf("payload has no relayer signature"))
+	}
+
+	msgBytes, err := SigningBytes(payload)
+	if err != nil {
+		return false, x402.NewVerifyError("serialization_failed", payload.Sender, "multiversx", err)
+	}
+
+	if err := verifyEd25519Signature(payload.Relayer, payload.RelayerSignature, msgBytes); err != nil {
+		return false, x402.NewVerifyError(x402.ErrCodeSignatureInvalid, payload.Sender, "multiversx", fmt.Errorf("relayer signature: %w", err))
 	}
 
 	return true, nil
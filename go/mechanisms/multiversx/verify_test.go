@@ -51,12 +51,10 @@ func TestVerifyPayment(t *testing.T) {
 	sig := ed25519.Sign(privKey, txBytes)
 	payload.Signature = hex.EncodeToString(sig)
 
-	req := types.PaymentRequirements{
-		PayTo: "erd1spyavw0956vq68xj8y4tenjpq2wd5a9p2c6j8gsz7ztyrnpxrruqzu66jx",
-	}
+	req := egldRequirements(payload)
 
 	// Test success case
-	successSim := func(p ExactRelayedPayload) (string, error) {
+	successSim := func(ctx context.Context, p ExactRelayedPayload) (string, error) {
 		return "sim_hash", nil
 	}
 
@@ -70,7 +68,7 @@ func TestVerifyPayment(t *testing.T) {
 
 	// Test Bad Sig
 	payload.Signature = hex.EncodeToString(make([]byte, 64)) // invalid
-	valid, err = VerifyPayment(context.Background(), payload, req, func(p ExactRelayedPayload) (string, error) {
+	valid, err = VerifyPayment(context.Background(), payload, req, func(ctx context.Context, p ExactRelayedPayload) (string, error) {
 		return "", errors.New("sim fail")
 	})
 
@@ -99,3 +97,518 @@ func TestVerifyPayment(t *testing.T) {
 		t.Errorf("Expected *x402.VerifyError, got %T: %v", err, err)
 	}
 }
+
+func TestVerifyPayment_Guarded(t *testing.T) {
+	senderHolder, err := NewSimpleCryptoHolderFromBytes(make([]byte, 32))
+	if err != nil {
+		t.Fatalf("Failed to create sender holder: %v", err)
+	}
+	guardianSeed := make([]byte, 32)
+	guardianSeed[0] = 1
+	guardianHolder, err := NewSimpleCryptoHolderFromBytes(guardianSeed)
+	if err != nil {
+		t.Fatalf("Failed to create guardian holder: %v", err)
+	}
+
+	payload := ExactRelayedPayload{
+		Nonce:    7,
+		Value:    "1000",
+		Receiver: "erd1spyavw0956vq68xj8y4tenjpq2wd5a9p2c6j8gsz7ztyrnpxrruqzu66jx",
+		Sender:   senderHolder.GetBech32(),
+		GasPrice: GasPriceDefault,
+		GasLimit: GasLimitStandard,
+		ChainID:  ChainIDDevnet,
+		Version:  TxVersionGuarded,
+		Options:  TxOptionGuarded,
+		Guardian: guardianHolder.GetBech32(),
+	}
+
+	tx := payload.ToTransaction()
+	if err := SignTransactionWithBuilder(senderHolder, &tx, false); err != nil {
+		t.Fatalf("Failed to apply user signature: %v", err)
+	}
+	if err := SignTransactionAsGuardian(guardianHolder, &tx); err != nil {
+		t.Fatalf("Failed to apply guardian signature: %v", err)
+	}
+	payload.Signature = tx.Signature
+	payload.GuardianSignature = tx.GuardianSignature
+
+	// Round trip through the map representation used on the wire
+	roundTripped, err := PayloadFromMap(payload.ToMap())
+	if err != nil {
+		t.Fatalf("PayloadFromMap failed: %v", err)
+	}
+	if roundTripped.Guardian != payload.Guardian || roundTripped.GuardianSignature != payload.GuardianSignature {
+		t.Fatalf("Guardian fields lost in round trip: %+v", roundTripped)
+	}
+
+	successSim := func(ctx context.Context, p ExactRelayedPayload) (string, error) {
+		return "sim_hash", nil
+	}
+
+	valid, err := VerifyPayment(context.Background(), *roundTripped, egldRequirements(payload), successSim)
+	if err != nil {
+		t.Fatalf("VerifyPayment failed for guarded tx: %v", err)
+	}
+	if !valid {
+		t.Fatal("VerifyPayment returned false for guarded tx")
+	}
+
+	// Guardian signature from another key must be rejected
+	var guardErr *x402.VerifyError
+	tampered := *roundTripped
+	tampered.GuardianSignature = tampered.Signature
+	_, err = VerifyPayment(context.Background(), tampered, egldRequirements(payload), successSim)
+	if !errors.As(err, &guardErr) || guardErr.Reason != ErrCodeInvalidGuardianSignature {
+		t.Errorf("invalid guardian signature: error = %v, want %s", err, ErrCodeInvalidGuardianSignature)
+	}
+
+	// Missing guardian signature must be rejected
+	tampered = *roundTripped
+	tampered.GuardianSignature = ""
+	_, err = VerifyPayment(context.Background(), tampered, egldRequirements(payload), successSim)
+	if !errors.As(err, &guardErr) || guardErr.Reason != ErrCodeMissingGuardianSignature {
+		t.Errorf("missing guardian signature: error = %v, want %s", err, ErrCodeMissingGuardianSignature)
+	}
+
+	// A broken user signature is still reported as such on a guarded payload
+	tampered = *roundTripped
+	tampered.Signature = tampered.GuardianSignature
+	_, err = VerifyPayment(context.Background(), tampered, egldRequirements(payload), successSim)
+	if !errors.As(err, &guardErr) || guardErr.Reason != x402.ErrCodeSignatureInvalid {
+		t.Errorf("invalid user signature: error = %v, want %s", err, x402.ErrCodeSignatureInvalid)
+	}
+}
+
+func TestSignTransactionAsGuardian_RequiresGuardedOption(t *testing.T) {
+	holder, err := NewSimpleCryptoHolderFromBytes(make([]byte, 32))
+	if err != nil {
+		t.Fatalf("Failed to create holder: %v", err)
+	}
+
+	payload := ExactRelayedPayload{
+		Sender:   holder.GetBech32(),
+		Receiver: holder.GetBech32(),
+		Value:    "0",
+		ChainID:  ChainIDDevnet,
+		Version:  TxVersionGuarded,
+		Guardian: holder.GetBech32(),
+	}
+	tx := payload.ToTransaction()
+
+	if err := SignTransactionAsGuardian(holder, &tx); err == nil {
+		t.Error("Expected error when the guarded option is not set")
+	}
+}
+
+func TestVerifyRelayerSignature(t *testing.T) {
+	senderHolder, err := NewSimpleCryptoHolderFromBytes(make([]byte, 32))
+	if err != nil {
+		t.Fatalf("Failed to create sender holder: %v", err)
+	}
+	relayerSeed := make([]byte, 32)
+	relayerSeed[0] = 2
+	relayerHolder, err := NewSimpleCryptoHolderFromBytes(relayerSeed)
+	if err != nil {
+		t.Fatalf("Failed to create relayer holder: %v", err)
+	}
+
+	payload := ExactRelayedPayload{
+		Nonce:    3,
+		Value:    "1000",
+		Receiver: "erd1spyavw0956vq68xj8y4tenjpq2wd5a9p2c6j8gsz7ztyrnpxrruqzu66jx",
+		Sender:   senderHolder.GetBech32(),
+		GasPrice: GasPriceDefault,
+		GasLimit: GasLimitStandard + GasLimitRelayedV3Extra,
+		ChainID:  ChainIDDevnet,
+		Version:  2,
+		Relayer:  relayerHolder.GetBech32(),
+	}
+
+	tx := payload.ToTransaction()
+	if err := SignTransactionWithBuilder(senderHolder, &tx, false); err != nil {
+		t.Fatalf("Failed to apply user signature: %v", err)
+	}
+	if err := SignTransactionWithBuilder(relayerHolder, &tx, true); err != nil {
+		t.Fatalf("Failed to apply relayer signature: %v", err)
+	}
+	payload.Signature = tx.Signature
+	payload.RelayerSignature = tx.RelayerSignature
+
+	valid, err := VerifyRelayerSignature(payload)
+	if err != nil {
+		t.Fatalf("VerifyRelayerSignature failed: %v", err)
+	}
+	if !valid {
+		t.Fatal("VerifyRelayerSignature returned false for a valid relayer signature")
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(p *ExactRelayedPayload)
+	}{
+		{"Tampered Value", func(p *ExactRelayedPayload) { p.Value = "2000" }},
+		{"Signed By Sender", func(p *ExactRelayedPayload) { p.RelayerSignature = p.Signature }},
+		{"Different Relayer", func(p *ExactRelayedPayload) { p.Relayer = p.Sender }},
+		{"Missing Signature", func(p *ExactRelayedPayload) { p.RelayerSignature = "" }},
+		{"Invalid Hex", func(p *ExactRelayedPayload) { p.RelayerSignature = "zz" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tampered := payload
+			tt.mutate(&tampered)
+
+			valid, err := VerifyRelayerSignature(tampered)
+			if valid {
+				t.Error("Expected tampered relayer signature to be rejected")
+			}
+			var vErr *x402.VerifyError
+			if !errors.As(err, &vErr) {
+				t.Errorf("Expected *x402.VerifyError, got %T: %v", err, err)
+			}
+		})
+	}
+}
+
+// egldRequirements returns direct EGLD requirements the payload transfer satisfies
+func egldRequirements(payload ExactRelayedPayload) types.PaymentRequirements {
+	extra := map[string]interface{}{"assetTransferMethod": TransferMethodDirect}
+	if payload.Data != "" {
+		extra["memo"] = payload.Data
+	}
+	return types.PaymentRequirements{PayTo: payload.Receiver, Amount: payload.Value, Asset: NativeTokenTicker, Extra: extra}
+}
+
+func TestVerifyPayment_LocalSignatureWithData(t *testing.T) {
+	payload := ExactRelayedPayload{
+		Nonce:     0,
+		Value:     "0",
+		Receiver:  "erd1cux02zersde0l7hhklzhywcxk4u9n4py5tdxyx7vrvhnza2r4gmq4vw35r",
+		Sender:    "erd1l453hd0gt5gzdp7czpuall8ggt2dcv5zwmfdf3sd3lguxseux2fsmsgldz",
+		GasPrice:  1000000000,
+		GasLimit:  50000,
+		Data:      "foo",
+		ChainID:   "1",
+		Version:   1,
+		Signature: "b5fddb8c16fa7f6123cb32edc854f1e760a3eb62c6dc420b5a4c0473c58befd45b621b31a448c5b59e21428f2bc128c80d0ee1caa4f2bf05a12be857ad451b00",
+	}
+
+	req := egldRequirements(payload)
+	simulated := false
+	valid, err := VerifyPayment(context.Background(), payload, req, func(ctx context.Context, p ExactRelayedPayload) (string, error) {
+		simulated = true
+		return "sim_hash", nil
+	})
+	if err != nil || !valid {
+		t.Fatalf("Expected local verification to pass for non-empty data, got valid=%v err=%v", valid, err)
+	}
+	if !simulated {
+		t.Error("Expected simulation to run after local verification")
+	}
+
+	payload.Data = "bar"
+	if _, err := VerifyPayment(context.Background(), payload, req, func(ctx context.Context, p ExactRelayedPayload) (string, error) {
+		t.Fatal("Simulation must not run when the local signature check fails")
+		return "", nil
+	}); err == nil {
+		t.Error("Expected local verification to fail for tampered data")
+	}
+}
+
+func TestVerifyPaymentOffline(t *testing.T) {
+	holder, err := NewSimpleCryptoHolderFromBytes(make([]byte, 32))
+	if err != nil {
+		t.Fatalf("Failed to create holder: %v", err)
+	}
+
+	payload := ExactRelayedPayload{
+		Nonce:    1,
+		Value:    "1000",
+		Receiver: "erd1spyavw0956vq68xj8y4tenjpq2wd5a9p2c6j8gsz7ztyrnpxrruqzu66jx",
+		Sender:   holder.GetBech32(),
+		GasPrice: GasPriceDefault,
+		GasLimit: GasLimitStandard,
+		Data:     "offline",
+		ChainID:  ChainIDDevnet,
+		Version:  1,
+	}
+	tx := payload.ToTransaction()
+	if err := SignTransactionWithBuilder(holder, &tx, false); err != nil {
+		t.Fatalf("Failed to sign: %v", err)
+	}
+	payload.Signature = tx.Signature
+
+	req := egldRequirements(payload)
+	valid, err := VerifyPaymentOffline(context.Background(), payload, req)
+	if err != nil || !valid {
+		t.Fatalf("Expected valid offline verification, got valid=%v err=%v", valid, err)
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(p *ExactRelayedPayload)
+		reason string
+	}{
+		{"Missing Signature", func(p *ExactRelayedPayload) { p.Signature = "" }, x402.ErrCodeSignatureInvalid},
+		{"Tampered Amount", func(p *ExactRelayedPayload) { p.Value = "1" }, x402.ErrCodeSignatureInvalid},
+		{"Invalid Signature Hex", func(p *ExactRelayedPayload) { p.Signature = "xyz" }, "invalid_signature_hex"},
+		{"Short Signature", func(p *ExactRelayedPayload) { p.Signature = "abcd" }, "invalid_signature_length"},
+		{"Invalid Sender", func(p *ExactRelayedPayload) { p.Sender = "erd1invalid" }, ErrCodeInvalidSender},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tampered := payload
+			tt.mutate(&tampered)
+
+			valid, err := VerifyPaymentOffline(context.Background(), tampered, req)
+			if valid {
+				t.Fatal("Expected offline verification to fail")
+			}
+			var vErr *x402.VerifyError
+			if !errors.As(err, &vErr) {
+				t.Fatalf("Expected *x402.VerifyError, got %T: %v", err, err)
+			}
+			if vErr.Reason != tt.reason {
+				t.Errorf("Expected reason %s, got %s", tt.reason, vErr.Reason)
+			}
+		})
+	}
+}
+
+func TestVerifyPaymentOffline_ChecksTransfer(t *testing.T) {
+	holder, err := NewSimpleCryptoHolderFromBytes(make([]byte, 32))
+	if err != nil {
+		t.Fatalf("Failed to create holder: %v", err)
+	}
+
+	payload := ExactRelayedPayload{
+		Nonce:    1,
+		Value:    "1000",
+		Receiver: "erd1spyavw0956vq68xj8y4tenjpq2wd5a9p2c6j8gsz7ztyrnpxrruqzu66jx",
+		Sender:   holder.GetBech32(),
+		GasPrice: GasPriceDefault,
+		GasLimit: GasLimitStandard,
+		ChainID:  ChainIDDevnet,
+		Version:  1,
+	}
+	tx := payload.ToTransaction()
+	if err := SignTransactionWithBuilder(holder, &tx, false); err != nil {
+		t.Fatalf("Failed to sign: %v", err)
+	}
+	payload.Signature = tx.Signature
+
+	wrongReceiver := egldRequirements(payload)
+	wrongReceiver.PayTo = "erd1cux02zersde0l7hhklzhywcxk4u9n4py5tdxyx7vrvhnza2r4gmq4vw35r"
+	wrongAmount := egldRequirements(payload)
+	wrongAmount.Amount = "2000"
+
+	tests := []struct {
+		name   string
+		req    types.PaymentRequirements
+		reason string
+	}{
+		{"Wrong Receiver", wrongReceiver, ErrCodeReceiverMismatch},
+		{"Wrong Amount", wrongAmount, ErrCodeAmountMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The signature is valid, only the transfer does not match what the requirements ask for
+			valid, err := VerifyPaymentOffline(context.Background(), payload, tt.req)
+			if valid {
+				t.Fatal("Expected a well-signed payload with the wrong transfer to be rejected")
+			}
+			var vErr *x402.VerifyError
+			if !errors.As(err, &vErr) || vErr.Reason != tt.reason {
+				t.Errorf("Expected reason %s, got %v", tt.reason, err)
+			}
+		})
+	}
+}
+
+func TestVerifyPaymentOffline_HashSigning(t *testing.T) {
+	holder, err := NewSimpleCryptoHolderFromBytes(make([]byte, 32))
+	if err != nil {
+		t.Fatalf("Failed to create holder: %v", err)
+	}
+
+	payload := ExactRelayedPayload{
+		Nonce:    1,
+		Value:    "1000",
+		Receiver: "erd1spyavw0956vq68xj8y4tenjpq2wd5a9p2c6j8gsz7ztyrnpxrruqzu66jx",
+		Sender:   holder.GetBech32(),
+		GasPrice: GasPriceDefault,
+		GasLimit: GasLimitStandard,
+		ChainID:  ChainIDDevnet,
+		Version:  2,
+		Options:  TxOptionHashSign,
+	}
+
+	msgBytes, err := SigningBytes(payload)
+	if err != nil {
+		t.Fatalf("SigningBytes() error = %v", err)
+	}
+	if len(msgBytes) != 32 {
+		t.Errorf("Expected the 32-byte transaction hash to be signed, got %d bytes", len(msgBytes))
+	}
+
+	// The SDK builder hashes the transaction on its own
+	tx := payload.ToTransaction()
+	if err := SignTransactionWithBuilder(holder, &tx, false); err != nil {
+		t.Fatalf("Failed to sign: %v", err)
+	}
+	payload.Signature = tx.Signature
+
+	req := egldRequirements(payload)
+	valid, err := VerifyPaymentOffline(context.Background(), payload, req)
+	if err != nil || !valid {
+		t.Fatalf("Expected valid hash-signed payload, got valid=%v err=%v", valid, err)
+	}
+
+	// A signature over the raw bytes does not match a hash-signed transaction
+	rawBytes, _ := SerializeTransaction(&tx)
+	signed, _ := (&SimpleSigner{}).SignByteSlice(rawBytes, holder.GetPrivateKey())
+	payload.Signature = hex.EncodeToString(signed)
+	if valid, _ := VerifyPaymentOffline(context.Background(), payload, req); valid {
+		t.Error("Expected a raw-bytes signature of a hash-signed payload to be rejected")
+	}
+}
+
+func TestValidatePayloadConsistency(t *testing.T) {
+	sender := "erd1qyu5wthldzr8wx5c9ucg8kjagg0jfs53s8nr3zpz3hypefsdd8ssycr6th"
+	relayer := "erd1spyavw0956vq68xj8y4tenjpq2wd5a9p2c6j8gsz7ztyrnpxrruqzu66jx"
+
+	direct := types.PaymentRequirements{Extra: map[string]interface{}{"assetTransferMethod": TransferMethodDirect}}
+	relayed := types.PaymentRequirements{Extra: map[string]interface{}{"relayer": relayer}}
+	unspecified := types.PaymentRequirements{}
+
+	tests := []struct {
+		name    string
+		payload ExactRelayedPayload
+		req     types.PaymentRequirements
+		wantErr bool
+	}{
+		{"Direct Version 1", ExactRelayedPayload{Version: 1}, direct, false},
+		{"Direct Version 2", ExactRelayedPayload{Version: 2}, direct, false},
+		{"Relayed Version 2", ExactRelayedPayload{Version: 2, Relayer: relayer}, relayed, false},
+		{"Payload Relayer Version 2", ExactRelayedPayload{Version: 2, Relayer: relayer}, unspecified, false},
+		{"Guarded Version 2", ExactRelayedPayload{Version: 2, Options: TxOptionGuarded, Guardian: relayer}, direct, false},
+		{"Hash Signed Version 2", ExactRelayedPayload{Version: 2, Options: TxOptionHashSign}, direct, false},
+		{"Guarded Hash Signed Relayed", ExactRelayedPayload{Version: 2, Relayer: relayer, Options: TxOptionGuarded | TxOptionHashSign, Guardian: relayer}, relayed, false},
+		{"Missing Version", ExactRelayedPayload{}, direct, true},
+		{"Relayed Version 1", ExactRelayedPayload{Version: 1, Relayer: relayer}, relayed, true},
+		{"Relayed Version 3", ExactRelayedPayload{Version: 3, Relayer: relayer}, relayed, true},
+		{"Relayed Requirement Without Relayer", ExactRelayedPayload{Version: 2}, relayed, true},
+		{"Direct With Relayer", ExactRelayedPayload{Version: 2, Relayer: relayer}, direct, true},
+		{"Guarded Version 1", ExactRelayedPayload{Version: 1, Options: TxOptionGuarded, Guardian: relayer}, direct, true},
+		{"Guarded Without Guardian", ExactRelayedPayload{Version: 2, Options: TxOptionGuarded}, direct, true},
+		{"Guardian Without Guarded Option", ExactRelayedPayload{Version: 2, Guardian: relayer}, direct, true},
+		{"Guardian Signature Without Guarded Option", ExactRelayedPayload{Version: 2, GuardianSignature: "00"}, direct, true},
+		{"Hash Signed Version 1", ExactRelayedPayload{Version: 1, Options: TxOptionHashSign}, direct, true},
+		{"Unknown Option", ExactRelayedPayload{Version: 2, Options: 0x4}, direct, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.payload.Sender = sender
+			err := ValidatePayloadConsistency(tt.payload, tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidatePayloadConsistency() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var vErr *x402.VerifyError
+			if tt.wantErr && (!errors.As(err, &vErr) || vErr.Reason != ErrCodeInconsistentTransaction) {
+				t.Errorf("expected %s VerifyError, got %v", ErrCodeInconsistentTransaction, err)
+			}
+		})
+	}
+}
+
+func TestValidateValueFormat(t *testing.T) {
+	tests := []struct {
+		value   string
+		wantErr bool
+	}{
+		{"0", false},
+		{"1000", false},
+		{"1500000000000000000", false},
+		{"", true},
+		{"0x64", true},
+		{"0X64", true},
+		{"001000", true},
+		{"00", true},
+		{"-1", true},
+		{"+1", true},
+		{"1e3", true},
+		{"1.5", true},
+		{" 1", true},
+	}
+
+	for _, tt := range tests {
+		err := ValidateValueFormat(ExactRelayedPayload{Value: tt.value})
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ValidateValueFormat(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		var vErr *x402.VerifyError
+		if tt.wantErr && (!errors.As(err, &vErr) || vErr.Reason != ErrCodeInvalidValueFormat) {
+			t.Errorf("ValidateValueFormat(%q) error = %v, want %s", tt.value, err, ErrCodeInvalidValueFormat)
+		}
+	}
+}
+
+func TestVerifyPaymentOffline_SignatureCommitsToRelayer(t *testing.T) {
+	senderHolder, _ := NewSimpleCryptoHolderFromBytes(make([]byte, 32))
+	relayerSeed := make([]byte, 32)
+	relayerSeed[0] = 2
+	relayerHolder, _ := NewSimpleCryptoHolderFromBytes(relayerSeed)
+	otherSeed := make([]byte, 32)
+	otherSeed[0] = 3
+	otherHolder, _ := NewSimpleCryptoHolderFromBytes(otherSeed)
+
+	payload := ExactRelayedPayload{
+		Nonce:    3,
+		Value:    "1000",
+		Receiver: "erd1spyavw0956vq68xj8y4tenjpq2wd5a9p2c6j8gsz7ztyrnpxrruqzu66jx",
+		Sender:   senderHolder.GetBech32(),
+		GasPrice: GasPriceDefault,
+		GasLimit: GasLimitStandard + GasLimitRelayedV3Extra,
+		ChainID:  ChainIDDevnet,
+		Version:  TxVersionRelayed,
+		Relayer:  relayerHolder.GetBech32(),
+	}
+	tx := payload.ToTransaction()
+	if err := SignTransactionWithBuilder(senderHolder, &tx, false); err != nil {
+		t.Fatalf("Failed to apply user signature: %v", err)
+	}
+	payload.Signature = tx.Signature
+
+	// The relayer survives the map round trip used on the wire
+	roundTripped, err := PayloadFromMap(payload.ToMap())
+	if err != nil {
+		t.Fatalf("PayloadFromMap failed: %v", err)
+	}
+	if roundTripped.Relayer != payload.Relayer {
+		t.Fatalf("relayer = %s, want %s", roundTripped.Relayer, payload.Relayer)
+	}
+
+	// Relayed payloads leave the transfer method unspecified; EGLD still resolves to the direct handler
+	req := egldRequirements(payload)
+	delete(req.Extra, "assetTransferMethod")
+	req.Extra["relayer"] = relayerHolder.GetBech32()
+	if valid, err := VerifyPaymentOffline(context.Background(), *roundTripped, req); !valid || err != nil {
+		t.Fatalf("Expected relayed payload to verify, got valid=%v err=%v", valid, err)
+	}
+
+	tampered := *roundTripped
+	tampered.Relayer = otherHolder.GetBech32()
+	valid, err := VerifyPaymentOffline(context.Background(), tampered, req)
+	if valid {
+		t.Fatal("Expected a swapped relayer to invalidate the sender signature")
+	}
+	var vErr *x402.VerifyError
+	if !errors.As(err, &vErr) || vErr.Reason != x402.ErrCodeSignatureInvalid {
+		t.Errorf("Expected %s, got %v", x402.ErrCodeSignatureInvalid, err)
+	}
+}
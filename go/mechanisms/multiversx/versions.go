@@ -0,0 +1,45 @@
+package multiversx
+
+import (
+	"fmt"
+
+	x402 "github.com/coinbase/x402/go"
+)
+
+// ExtraX402Versions is the requirement/supported-kind Extra key advertising the accepted x402 versions
+const ExtraX402Versions = "x402Versions"
+
+// SupportedX402Versions lists the x402 protocol versions the MultiversX exact scheme accepts
+var SupportedX402Versions = []int{x402.ProtocolVersion}
+
+// ParseX402Versions reads an advertised version list, as set in Go ([]int) or decoded from JSON ([]interface{})
+func ParseX402Versions(value interface{}) ([]int, error) {
+	switch v := value.(type) {
+	case []int:
+		return v, nil
+	case []interface{}:
+		versions := make([]int, 0, len(v))
+		for _, item := range v {
+			switch n := item.(type) {
+			case int:
+				versions = append(versions, n)
+			case float64:
+				versions = append(versions, int(n))
+			default:
+				return nil, fmt.Errorf("invalid x402 version: %v", item)
+			}
+		}
+		return versions, nil
+	}
+	return nil, fmt.Errorf("invalid x402 version list: %v", value)
+}
+
+// ContainsX402Version reports whether version is one of versions
+func ContainsX402Version(versions []int, version int) bool {
+	for _, v := range versions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
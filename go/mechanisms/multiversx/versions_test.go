@@ -0,0 +1,36 @@
+package multiversx
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseX402Versions(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   interface{}
+		want    []int
+		wantErr bool
+	}{
+		{"Go Slice", []int{1, 2}, []int{1, 2}, false},
+		{"JSON Decoded", []interface{}{float64(2)}, []int{2}, false},
+		{"Invalid Entry", []interface{}{"2"}, nil, true},
+		{"Not A List", 2, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseX402Versions(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseX402Versions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseX402Versions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if !ContainsX402Version(SupportedX402Versions, 2) || ContainsX402Version(SupportedX402Versions, 1) {
+		t.Errorf("Unexpected SupportedX402Versions: %v", SupportedX402Versions)
+	}
+}
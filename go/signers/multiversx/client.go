@@ -4,12 +4,31 @@ import (
 	"context"
 	"crypto/ed25519"
 	"encoding/hex"
+	"encoding/pem"
+	"errors"
 	"fmt"
+	"os"
+	"strings"
 
 	"github.com/coinbase/x402/go/mechanisms/multiversx"
 	"github.com/multiversx/mx-sdk-go/data"
+	"github.com/multiversx/mx-sdk-go/interactors"
 )
 
+var (
+	// ErrWrongKeystorePassword is returned when the keystore cannot be decrypted with the given password
+	ErrWrongKeystorePassword = errors.New("wrong keystore password")
+	// ErrMalformedKeystore is returned when the keystore file is not a valid MultiversX JSON keystore
+	ErrMalformedKeystore = errors.New("malformed keystore file")
+	// ErrMalformedPEM is returned when the data is not a MultiversX PEM private key
+	ErrMalformedPEM = errors.New("malformed PEM private key")
+	// ErrPEMAddressMismatch is returned when the key does not match the address in the PEM header
+	ErrPEMAddressMismatch = errors.New("PEM header address does not match the private key")
+)
+
+// pemKeyTypePrefix prefixes the PEM block type of MultiversX keys, followed by the bech32 address
+const pemKeyTypePrefix = "PRIVATE KEY for "
+
 // ClientSigner implements multiversx.ClientMultiversXSigner using local Ed25519 keys
 type ClientSigner struct {
 	privKey ed25519.PrivateKey
@@ -23,6 +42,71 @@ func NewClientSignerFromPrivateKey(privKeyHex string) (*ClientSigner, error) {
 		return nil, fmt.Errorf("failed to decode private key hex: %w", err)
 	}
 
+	return newClientSignerFromSeed(privKeyBytes)
+}
+
+// NewClientSignerFromKeystore creates a new ClientSigner from a MultiversX JSON keystore file
+// A wrong password yields ErrWrongKeystorePassword and an unreadable keystore ErrMalformedKeystore.
+func NewClientSignerFromKeystore(path string, password string) (*ClientSigner, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("failed to read keystore: %w", err)
+	}
+
+	privKeyBytes, err := interactors.NewWallet().LoadPrivateKeyFromJsonFile(path, password)
+	if err != nil {
+		if errors.Is(err, interactors.ErrWrongPassword) {
+			return nil, ErrWrongKeystorePassword
+		}
+		return nil, fmt.Errorf("%w: %v", ErrMalformedKeystore, err)
+	}
+
+	return newClientSignerFromSeed(privKeyBytes)
+}
+
+// NewClientSignerFromPEM creates a new ClientSigner from a MultiversX PEM file (e.g. an mxpy walletKey.pem)
+func NewClientSignerFromPEM(path string) (*ClientSigner, error) {
+	pemBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PEM file: %w", err)
+	}
+	return NewClientSignerFromPEMBytes(pemBytes)
+}
+
+// NewClientSignerFromPEMBytes creates a new ClientSigner from MultiversX PEM data
+// The block body holds the hex-encoded seed (optionally followed by the public key). When the
+// "PRIVATE KEY for erd1..." header names an address, it must match the address derived from the seed.
+func NewClientSignerFromPEMBytes(pemBytes []byte) (*ClientSigner, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, fmt.Errorf("%w: no PEM block found", ErrMalformedPEM)
+	}
+
+	keyHex := strings.TrimSpace(string(block.Bytes))
+	if len(keyHex) < 64 {
+		return nil, fmt.Errorf("%w: key too short", ErrMalformedPEM)
+	}
+	seed, err := hex.DecodeString(keyHex[:64])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformedPEM, err)
+	}
+
+	signer, err := newClientSignerFromSeed(seed)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.HasPrefix(block.Type, pemKeyTypePrefix) {
+		headerAddress := strings.TrimSpace(strings.TrimPrefix(block.Type, pemKeyTypePrefix))
+		if headerAddress != "" && headerAddress != signer.address {
+			return nil, fmt.Errorf("%w: header %s, derived %s", ErrPEMAddressMismatch, headerAddress, signer.address)
+		}
+	}
+
+	return signer, nil
+}
+
+// newClientSignerFromSeed derives the key pair and bech32 address from a 32 byte seed
+func newClientSignerFromSeed(privKeyBytes []byte) (*ClientSigner, error) {
 	if len(privKeyBytes) != 32 {
 		return nil, fmt.Errorf("invalid private key length: expected 32 bytes, got %d", len(privKeyBytes))
 	}